@@ -0,0 +1,209 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/figment-networks/kava-worker/api/types"
+
+	"github.com/kava-labs/kava/app"
+	"github.com/tendermint/tendermint/libs/bech32"
+	"go.uber.org/zap"
+)
+
+// accountQueries are tx_search conditions matching transactions of the account
+var accountQueries = []string{"message.sender", "transfer.recipient"}
+
+// SearchAccountTx gets transactions sent or received by the account, between optional start and end height.
+// Transactions are de-duplicated and ordered by height and index.
+// At most limit transactions are returned (0 - no limit), cut at the height boundary; when there are more,
+// nextHeight is the height to continue from, otherwise it's zero.
+// A single height with more transactions than limit is returned whole.
+func (c *Client) SearchAccountTx(ctx context.Context, account string, startHeight, endHeight, perPage, limit uint64) (txs []structs.Transaction, nextHeight uint64, err error) {
+	defer c.logger.Sync()
+
+	hrp, _, err := bech32.DecodeAndConvert(account)
+	if err != nil {
+		return nil, 0, fmt.Errorf("[KAVA-API] Wrong account address %q: %w", account, err)
+	}
+	if hrp != app.Bech32MainPrefix {
+		return nil, 0, fmt.Errorf("[KAVA-API] Wrong account address prefix %q", hrp)
+	}
+
+	seen := make(map[string]bool)
+	var raw []types.TxResponse
+	var heights []uint64
+	for _, key := range accountQueries {
+		s := strings.Builder{}
+		s.WriteString(`"`)
+		s.WriteString(key)
+		s.WriteString("='")
+		s.WriteString(account)
+		s.WriteString("'")
+		if startHeight > 0 {
+			s.WriteString(" AND tx.height>=")
+			s.WriteString(strconv.FormatUint(startHeight, 10))
+		}
+		if endHeight > 0 {
+			s.WriteString(" AND tx.height<=")
+			s.WriteString(strconv.FormatUint(endHeight, 10))
+		}
+		s.WriteString(`"`)
+
+		// queried heights are ascending, query is complete for the result once it has more than limit
+		// transactions and the height of the one over the limit is fully fetched
+		var queried []uint64
+		for page := uint64(1); ; page++ {
+			result, err := c.txSearchPage(ctx, s.String(), page, perPage)
+			if err != nil {
+				return nil, 0, err
+			}
+			for _, txRaw := range result.Txs {
+				h, err := strconv.ParseUint(txRaw.Height, 10, 64)
+				if err != nil {
+					return nil, 0, fmt.Errorf("[KAVA-API] Error parsing transaction height: %w", err)
+				}
+				queried = append(queried, h)
+				if !seen[txRaw.Hash] {
+					seen[txRaw.Hash] = true
+					raw = append(raw, txRaw)
+					heights = append(heights, h)
+				}
+			}
+
+			totalCount, err := strconv.ParseUint(result.TotalCount, 10, 64)
+			if err != nil {
+				return nil, 0, err
+			}
+			if len(result.Txs) == 0 || page*perPage >= totalCount {
+				break
+			}
+			if limit > 0 && uint64(len(queried)) > limit && queried[len(queried)-1] > queried[limit] {
+				break
+			}
+		}
+	}
+
+	sort.Sort(byHeightIndex{raw, heights})
+	if limit > 0 && uint64(len(raw)) > limit {
+		raw, heights, nextHeight = cutAtHeight(raw, heights, limit)
+	}
+
+	blocks := make(map[uint64]structs.Block)
+	for i, txRaw := range raw {
+		block, ok := blocks[heights[i]]
+		if !ok {
+			if block, err = c.GetBlock(ctx, structs.HeightHash{Height: heights[i]}); err != nil {
+				return nil, 0, fmt.Errorf("[KAVA-API] Error fetching block of transaction: %w", err)
+			}
+			blocks[heights[i]] = block
+		}
+
+		tx, err := rawToTransaction(ctx, txRaw, c.logger, c.cdc)
+		if err != nil {
+			return nil, 0, err
+		}
+		tx.BlockHash = block.Hash
+		tx.ChainID = block.ChainID
+		tx.Time = block.Time
+		if c.Valuator != nil {
+			c.Valuator.Valuate(ctx, &tx)
+		}
+		txs = append(txs, tx)
+	}
+
+	c.logger.Debug("[KAVA-API] Converted account transactions", zap.Int("number", len(txs)), zap.String("account", account), zap.Uint64("next_height", nextHeight))
+	return txs, nextHeight, nil
+}
+
+// cutAtHeight keeps sorted transactions of heights below the height of the one over the limit,
+// or the whole first height when it alone exceeds the limit. It returns the first height left out (zero when nothing is).
+func cutAtHeight(raw []types.TxResponse, heights []uint64, limit uint64) ([]types.TxResponse, []uint64, uint64) {
+	next := heights[limit]
+	if next == heights[0] {
+		next++
+	}
+	n := sort.Search(len(heights), func(i int) bool { return heights[i] >= next })
+	if n == len(raw) {
+		return raw, heights, 0
+	}
+	return raw[:n], heights[:n], next
+}
+
+// byHeightIndex sorts raw transactions by height and index in block
+type byHeightIndex struct {
+	txs     []types.TxResponse
+	heights []uint64
+}
+
+func (b byHeightIndex) Len() int { return len(b.txs) }
+func (b byHeightIndex) Less(i, j int) bool {
+	if b.heights[i] != b.heights[j] {
+		return b.heights[i] < b.heights[j]
+	}
+	return b.txs[i].Index < b.txs[j].Index
+}
+func (b byHeightIndex) Swap(i, j int) {
+	b.txs[i], b.txs[j] = b.txs[j], b.txs[i]
+	b.heights[i], b.heights[j] = b.heights[j], b.heights[i]
+}
+
+// txSearchPage gets single page of tx_search results for the query
+func (c *Client) txSearchPage(ctx context.Context, query string, page, perPage uint64) (result types.ResultTxSearch, err error) {
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return result, err
+	}
+
+	sCtx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+	req, err := http.NewRequestWithContext(sCtx, http.MethodGet, c.baseURL+"/tx_search", nil)
+	if err != nil {
+		return result, err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	if c.key != "" {
+		req.Header.Add("Authorization", c.key)
+	}
+
+	q := req.URL.Query()
+	q.Add("query", query)
+	q.Add("page", strconv.FormatUint(page, 10))
+	q.Add("per_page", strconv.FormatUint(perPage, 10))
+	q.Add("order_by", `"asc"`)
+	req.URL.RawQuery = q.Encode()
+
+	now := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return result, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 399 { // ERROR
+		serverError, _ := ioutil.ReadAll(resp.Body)
+		c.logger.Error("[KAVA-API] error getting response from server", zap.Int("code", resp.StatusCode), zap.Any("response", string(serverError)))
+		return result, fmt.Errorf("error getting response from server %d %s", resp.StatusCode, string(serverError))
+	}
+
+	rawRequestHTTPDuration.WithLabels("/tx_search", resp.Status).Observe(time.Since(now).Seconds())
+
+	out := &types.GetTxSearchResponse{}
+	if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return result, fmt.Errorf("unable to decode result body %w", err)
+	}
+
+	if out.Error.Message != "" {
+		return result, fmt.Errorf("Error getting search: %s", out.Error.Message)
+	}
+
+	return out.Result, nil
+}
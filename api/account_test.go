@@ -0,0 +1,174 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/figment-networks/kava-worker/api/types"
+	"go.uber.org/zap"
+)
+
+// fixtureTx is the encoded transaction of testdata/fixtures
+const fixtureTx = "ZigoFqkKQqijYZoKFGZpeHR1cmUtc2VuZGVyLWFkZHIxEhRmaXh0dXJlLXJlY2lwaWVudC1hMRoQCgV1a2F2YRIHMTAwMDAwMBITCg0KBXVrYXZhEgQ1MDAwEMCaDCIHZml4dHVyZQ=="
+
+// accountNode serves tx_search results of account queries and blocks of any height
+type accountNode struct {
+	// results of tx_search per query key, in ascending order
+	results map[string][]types.TxResponse
+
+	l     sync.Mutex
+	pages map[string]int
+}
+
+func accountTx(hash string, height uint64, index float64) types.TxResponse {
+	return types.TxResponse{Hash: hash, Height: strconv.FormatUint(height, 10), Index: index, TxData: fixtureTx}
+}
+
+func (an *accountNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	switch r.URL.Path {
+	case "/block":
+		fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": -1, "result": {"block_id": {"hash": "BLOCK%[1]s"}, "block": {"header": {"chain_id": "kava-4", "height": "%[1]s", "time": "2021-03-05T12:00:00Z"}, "data": {"txs": []}}}}`, q.Get("height"))
+	case "/tx_search":
+		key := strings.SplitN(strings.Trim(q.Get("query"), `"`), "=", 2)[0]
+		page, _ := strconv.Atoi(q.Get("page"))
+		perPage, _ := strconv.Atoi(q.Get("per_page"))
+
+		an.l.Lock()
+		an.pages[key]++
+		an.l.Unlock()
+
+		all := an.results[key]
+		from, to := (page-1)*perPage, page*perPage
+		if from > len(all) {
+			from = len(all)
+		}
+		if to > len(all) {
+			to = len(all)
+		}
+		resp := types.GetTxSearchResponse{Result: types.ResultTxSearch{Txs: all[from:to], TotalCount: strconv.Itoa(len(all))}}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newAccountClient(t *testing.T, an *accountNode) *Client {
+	t.Helper()
+	InitMetrics()
+	an.pages = make(map[string]int)
+	srv := httptest.NewServer(an)
+	t.Cleanup(srv.Close)
+	return NewClient(srv.URL, "", zap.NewNop(), nil, 100)
+}
+
+func TestSearchAccountTx(t *testing.T) {
+	an := &accountNode{results: map[string][]types.TxResponse{
+		"message.sender":     {accountTx("A", 10, 0), accountTx("C", 12, 1), accountTx("E", 20, 0)},
+		"transfer.recipient": {accountTx("A", 10, 0), accountTx("B", 12, 0), accountTx("D", 15, 3), accountTx("E", 20, 0)},
+	}}
+	c := newAccountClient(t, an)
+
+	tests := []struct {
+		name       string
+		perPage    uint64
+		limit      uint64
+		wantHashes []string
+		wantNext   uint64
+	}{
+		{name: "no limit", perPage: 100, wantHashes: []string{"A", "B", "C", "D", "E"}},
+		{name: "paged", perPage: 1, wantHashes: []string{"A", "B", "C", "D", "E"}},
+		{name: "limit above results", perPage: 1, limit: 10, wantHashes: []string{"A", "B", "C", "D", "E"}},
+		{name: "limit at height boundary", perPage: 1, limit: 3, wantHashes: []string{"A", "B", "C"}, wantNext: 15},
+		{name: "height is not split", perPage: 1, limit: 2, wantHashes: []string{"A"}, wantNext: 12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txs, next, err := c.SearchAccountTx(context.Background(), fixtureAccount, 0, 0, tt.perPage, tt.limit)
+			if err != nil {
+				t.Fatalf("SearchAccountTx() error = %v", err)
+			}
+			var hashes []string
+			for _, tx := range txs {
+				hashes = append(hashes, tx.Hash)
+				if want := "BLOCK" + strconv.FormatUint(tx.Height, 10); tx.BlockHash != want {
+					t.Errorf("transaction %s has block hash %s, want %s", tx.Hash, tx.BlockHash, want)
+				}
+			}
+			if !reflect.DeepEqual(hashes, tt.wantHashes) {
+				t.Errorf("SearchAccountTx() = %v, want %v", hashes, tt.wantHashes)
+			}
+			if next != tt.wantNext {
+				t.Errorf("SearchAccountTx() next height = %d, want %d", next, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestSearchAccountTxLargeHeight(t *testing.T) {
+	an := &accountNode{results: map[string][]types.TxResponse{
+		"message.sender":     {accountTx("X1", 5, 0), accountTx("X3", 5, 2), accountTx("Y", 6, 0), accountTx("Z", 7, 0)},
+		"transfer.recipient": {accountTx("X2", 5, 1), accountTx("X3", 5, 2)},
+	}}
+	c := newAccountClient(t, an)
+
+	// height over the limit is returned whole
+	txs, next, err := c.SearchAccountTx(context.Background(), fixtureAccount, 0, 0, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hashes []string
+	for _, tx := range txs {
+		hashes = append(hashes, tx.Hash)
+	}
+	if !reflect.DeepEqual(hashes, []string{"X1", "X2", "X3"}) || next != 6 {
+		t.Errorf("SearchAccountTx() = %v, next height %d, want [X1 X2 X3] and 6", hashes, next)
+	}
+
+	// nothing is left after the height
+	an.results["message.sender"] = an.results["message.sender"][:2]
+	if txs, next, err = c.SearchAccountTx(context.Background(), fixtureAccount, 0, 0, 1, 2); err != nil || len(txs) != 3 || next != 0 {
+		t.Errorf("SearchAccountTx() returned %d transactions, next height %d, error %v, want 3, 0", len(txs), next, err)
+	}
+}
+
+func TestSearchAccountTxStopsPaging(t *testing.T) {
+	var sent []types.TxResponse
+	for i := uint64(0); i < 50; i++ {
+		sent = append(sent, accountTx(fmt.Sprintf("S%d", i), 100+i, 0))
+	}
+	an := &accountNode{results: map[string][]types.TxResponse{"message.sender": sent}}
+	c := newAccountClient(t, an)
+
+	txs, next, err := c.SearchAccountTx(context.Background(), fixtureAccount, 0, 0, 5, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 10 || next != 110 {
+		t.Errorf("SearchAccountTx() returned %d transactions, next height %d, want 10 and 110", len(txs), next)
+	}
+	// the eleventh transaction is on the third page
+	if an.pages["message.sender"] != 3 {
+		t.Errorf("requested %d pages, want 3", an.pages["message.sender"])
+	}
+}
+
+func TestSearchAccountTxWrongAccount(t *testing.T) {
+	c := newAccountClient(t, &accountNode{})
+	for _, account := range []string{"kava1", "cosmos1ve5hsar4wfjj6um9dejx2u3dv9jxgu33x4tcuc"} {
+		if _, _, err := c.SearchAccountTx(context.Background(), account, 0, 0, 100, 0); err == nil {
+			t.Errorf("expected error for account %q", account)
+		}
+	}
+}
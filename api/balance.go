@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/figment-networks/indexing-engine/structs"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/rest"
+)
+
+// balanceResponse is kava response for querying /bank/balances
+type balanceResponse struct {
+	Height string    `json:"height"`
+	Result sdk.Coins `json:"result"`
+}
+
+// GetBalances fetches balances of the account at given height
+func (c *Client) GetBalances(ctx context.Context, params structs.HeightAccount) (coins sdk.Coins, err error) {
+	endpoint := fmt.Sprintf("/bank/balances/%s", params.Account)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+endpoint, nil)
+	if err != nil {
+		return coins, err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	if c.key != "" {
+		req.Header.Add("Authorization", c.key)
+	}
+
+	q := req.URL.Query()
+	if params.Height > 0 {
+		q.Add("height", strconv.FormatUint(params.Height, 10))
+	}
+	req.URL.RawQuery = q.Encode()
+
+	err = c.rateLimiter.Wait(ctx)
+	if err != nil {
+		return coins, err
+	}
+
+	var cliResp *http.Response
+
+	for i := 1; i <= maxRetries; i++ {
+		n := time.Now()
+		cliResp, err = c.httpClient.Do(req)
+		if err, ok := err.(net.Error); ok && err.Timeout() && i != maxRetries {
+			continue
+		} else if err != nil {
+			return coins, err
+		}
+		rawRequestHTTPDuration.WithLabels("/bank/balances/_", cliResp.Status).Observe(time.Since(n).Seconds())
+
+		defer cliResp.Body.Close()
+
+		if cliResp.StatusCode < 500 {
+			break
+		}
+		time.Sleep(time.Duration(i*500) * time.Millisecond)
+	}
+
+	decoder := json.NewDecoder(cliResp.Body)
+
+	if cliResp.StatusCode > 399 {
+		var result rest.ErrorResponse
+		if err = decoder.Decode(&result); err != nil {
+			return coins, fmt.Errorf("[KAVA-API] Error fetching balances: %d", cliResp.StatusCode)
+		}
+		return coins, fmt.Errorf("[KAVA-API] Error fetching balances: %s ", result.Error)
+	}
+
+	var result balanceResponse
+	if err = decoder.Decode(&result); err != nil {
+		return coins, err
+	}
+
+	return result.Result, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"strconv"
 	"sync"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/figment-networks/indexing-engine/structs"
 	"github.com/figment-networks/kava-worker/api/types"
+	"go.uber.org/zap"
 )
 
 // BlocksMap map of blocks to control block map
@@ -29,25 +31,83 @@ type BlockErrorPair struct {
 }
 
 // GetBlock fetches most recent block from chain
-func (c Client) GetBlock(ctx context.Context, params structs.HeightHash) (block structs.Block, err error) {
+func (c *Client) GetBlock(ctx context.Context, params structs.HeightHash) (block structs.Block, err error) {
+	block, _, err = c.GetBlockWithParent(ctx, params)
+	return block, err
+}
+
+// GetBlockWithParent fetches block along with the hash of its parent (last block id)
+func (c *Client) GetBlockWithParent(ctx context.Context, params structs.HeightHash) (block structs.Block, parentHash string, err error) {
 	var ok bool
 	if params.Height != 0 {
-		block, ok = c.Sbc.Get(params.Height)
-		if ok {
-			return block, nil
+		block, parentHash, ok = c.BlockCache.Get(params.Height)
+		if ok && (params.Hash == "" || params.Hash == block.Hash) {
+			return block, parentHash, nil
 		}
 	}
 
-	err = c.rateLimiter.Wait(ctx)
+	var (
+		data   []byte
+		cached bool
+	)
+	if c.RawCache != nil && params.Height != 0 {
+		data, cached = c.RawCache.Get(RawBlock, params.Height, 0)
+	}
+	if !cached {
+		if data, err = c.fetchBlock(ctx, params.Height); err != nil {
+			return block, parentHash, err
+		}
+	}
+
+	var result *types.GetBlockResponse
+	if err = json.Unmarshal(data, &result); err != nil {
+		return block, parentHash, err
+	}
+
+	if result.Error.Message != "" {
+		return block, parentHash, fmt.Errorf("[KAVA-API] Error fetching block: %s ", result.Error.Message)
+	}
+	bTime, err := time.Parse(time.RFC3339Nano, result.Result.Block.Header.Time)
 	if err != nil {
-		return block, err
+		return block, parentHash, err
+	}
+	uHeight, err := strconv.ParseUint(result.Result.Block.Header.Height, 10, 64)
+	if err != nil {
+		return block, parentHash, err
+	}
+
+	numTxs := len(result.Result.Block.Data.Txs)
+
+	block = structs.Block{
+		Hash:                 result.Result.BlockID.Hash,
+		Height:               uHeight,
+		Time:                 bTime,
+		ChainID:              result.Result.Block.Header.ChainID,
+		NumberOfTransactions: uint64(numTxs),
+	}
+
+	parentHash = result.Result.Block.Header.LastBlockID.Hash
+	c.BlockCache.Add(block, parentHash)
+	// only responses of requested heights are persisted, the latest block changes
+	if c.RawCache != nil && params.Height != 0 && !cached {
+		if err := c.RawCache.Put(RawBlock, params.Height, 0, data); err != nil {
+			c.logger.Warn("[KAVA-API] Error caching block", zap.Uint64("height", params.Height), zap.Error(err))
+		}
+	}
+	return block, parentHash, nil
+}
+
+// fetchBlock gets raw /block response of the height (the latest one for 0)
+func (c *Client) fetchBlock(ctx context.Context, height uint64) (data []byte, err error) {
+	if err = c.rateLimiter.Wait(ctx); err != nil {
+		return nil, err
 	}
 
 	sCtx, cancel := context.WithTimeout(ctx, time.Second*50)
 	defer cancel()
 	req, err := http.NewRequestWithContext(sCtx, http.MethodGet, c.baseURL+"/block", nil)
 	if err != nil {
-		return block, err
+		return nil, err
 	}
 
 	req.Header.Add("Content-Type", "application/json")
@@ -56,48 +116,28 @@ func (c Client) GetBlock(ctx context.Context, params structs.HeightHash) (block
 	}
 
 	q := req.URL.Query()
-	if params.Height > 0 {
-		q.Add("height", strconv.FormatUint(params.Height, 10))
+	if height > 0 {
+		q.Add("height", strconv.FormatUint(height, 10))
 	}
 	req.URL.RawQuery = q.Encode()
 
 	n := time.Now()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return block, err
+		return nil, err
 	}
 	rawRequestHTTPDuration.WithLabels("/block", resp.Status).Observe(time.Since(n).Seconds())
 	defer resp.Body.Close()
 
-	decoder := json.NewDecoder(resp.Body)
-
-	var result *types.GetBlockResponse
-	if err = decoder.Decode(&result); err != nil {
-		return block, err
-	}
-
-	if result.Error.Message != "" {
-		return block, fmt.Errorf("[KAVA-API] Error fetching block: %s ", result.Error.Message)
-	}
-	bTime, err := time.Parse(time.RFC3339Nano, result.Result.Block.Header.Time)
-	if err != nil {
-		return block, err
-	}
-	uHeight, err := strconv.ParseUint(result.Result.Block.Header.Height, 10, 64)
-	if err != nil {
-		return block, err
-	}
-
-	numTxs := len(result.Result.Block.Data.Txs)
+	return ioutil.ReadAll(resp.Body)
+}
 
-	block = structs.Block{
-		Hash:                 result.Result.BlockID.Hash,
-		Height:               uHeight,
-		Time:                 bTime,
-		ChainID:              result.Result.Block.Header.ChainID,
-		NumberOfTransactions: uint64(numTxs),
+// InvalidateBlock removes block of given height from the client's caches
+func (c *Client) InvalidateBlock(height uint64) {
+	c.BlockCache.Invalidate(height)
+	if c.RawCache != nil {
+		if err := c.RawCache.Delete(height); err != nil {
+			c.logger.Warn("[KAVA-API] Error removing cached responses", zap.Uint64("height", height), zap.Error(err))
+		}
 	}
-
-	c.Sbc.Add(block)
-	return block, nil
 }
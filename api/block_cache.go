@@ -1,54 +1,78 @@
 package api
 
 import (
+	"container/list"
 	"sync"
 
 	"github.com/figment-networks/indexing-engine/structs"
 )
 
-// SimpleBlockCache simple in memory block cache to store latest blocks
-type SimpleBlockCache struct {
-	space  map[uint64]structs.Block
-	blocks chan *structs.Block
-	l      sync.RWMutex
+// BlockCache is an in memory LRU cache of blocks
+type BlockCache struct {
+	space map[uint64]*list.Element
+	// recent keeps cached blocks, the most recently used first
+	recent *list.List
+	cap    int
+	l      sync.Mutex
 }
 
-// NewSimpleBlockCache a SimpleBlockCache constructor
-func NewSimpleBlockCache(cap int) *SimpleBlockCache {
-	return &SimpleBlockCache{
-		space:  make(map[uint64]structs.Block),
-		blocks: make(chan *structs.Block, cap),
+// NewBlockCache is BlockCache constructor
+func NewBlockCache(cap int) *BlockCache {
+	return &BlockCache{
+		space:  make(map[uint64]*list.Element),
+		recent: list.New(),
+		cap:    cap,
 	}
 }
 
-// Add blocks to the cache (thread safe)
-func (sbc *SimpleBlockCache) Add(bl structs.Block) {
-	sbc.l.Lock()
-	defer sbc.l.Unlock()
+// cachedBlock is a block with the hash of its parent
+type cachedBlock struct {
+	block      structs.Block
+	parentHash string
+}
+
+// Add block to the cache, evicting the least recently used one when full (thread safe)
+func (bc *BlockCache) Add(bl structs.Block, parentHash string) {
+	bc.l.Lock()
+	defer bc.l.Unlock()
 
-	_, ok := sbc.space[bl.Height]
-	if ok {
+	if el, ok := bc.space[bl.Height]; ok {
+		el.Value = cachedBlock{block: bl, parentHash: parentHash}
+		bc.recent.MoveToFront(el)
 		return
 	}
 
-	sbc.space[bl.Height] = bl
-	select {
-	case sbc.blocks <- &bl:
-	default:
-		oldBlock := <-sbc.blocks
-		if oldBlock != nil {
-			delete(sbc.space, oldBlock.Height)
-		}
-		sbc.blocks <- &bl
+	bc.space[bl.Height] = bc.recent.PushFront(cachedBlock{block: bl, parentHash: parentHash})
+	if bc.recent.Len() > bc.cap {
+		oldest := bc.recent.Back()
+		bc.recent.Remove(oldest)
+		delete(bc.space, oldest.Value.(cachedBlock).block.Height)
 	}
+}
 
+// Get block of given height (thread safe)
+func (bc *BlockCache) Get(height uint64) (bl structs.Block, parentHash string, ok bool) {
+	bc.l.Lock()
+	defer bc.l.Unlock()
+
+	el, ok := bc.space[height]
+	if !ok {
+		cacheRequests.WithLabels("block", "miss").Inc()
+		return bl, "", false
+	}
+	cacheRequests.WithLabels("block", "hit").Inc()
+	bc.recent.MoveToFront(el)
+	cb := el.Value.(cachedBlock)
+	return cb.block, cb.parentHash, true
 }
 
-// Get and check block of given height (thread safe)
-func (sbc *SimpleBlockCache) Get(height uint64) (bl structs.Block, ok bool) {
-	sbc.l.RLock()
-	defer sbc.l.RUnlock()
+// Invalidate removes block of given height from the cache (thread safe)
+func (bc *BlockCache) Invalidate(height uint64) {
+	bc.l.Lock()
+	defer bc.l.Unlock()
 
-	bl, ok = sbc.space[bl.Height]
-	return bl, ok
+	if el, ok := bc.space[height]; ok {
+		bc.recent.Remove(el)
+		delete(bc.space, height)
+	}
 }
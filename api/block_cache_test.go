@@ -0,0 +1,60 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/figment-networks/indexing-engine/structs"
+)
+
+func TestBlockCacheGet(t *testing.T) {
+	bc := NewBlockCache(2)
+	if _, _, ok := bc.Get(1); ok {
+		t.Fatal("expected miss on empty cache")
+	}
+
+	bc.Add(structs.Block{Height: 1, Hash: "A1"}, "A0")
+	bl, parent, ok := bc.Get(1)
+	if !ok {
+		t.Fatal("expected hit")
+	}
+	if bl.Hash != "A1" || parent != "A0" {
+		t.Errorf("unexpected block %+v with parent %q", bl, parent)
+	}
+
+	// adding the same height replaces the block
+	bc.Add(structs.Block{Height: 1, Hash: "B1"}, "B0")
+	if bl, parent, _ = bc.Get(1); bl.Hash != "B1" || parent != "B0" {
+		t.Errorf("expected replaced block, got %+v with parent %q", bl, parent)
+	}
+
+	bc.Invalidate(1)
+	if _, _, ok := bc.Get(1); ok {
+		t.Error("expected miss after invalidation")
+	}
+	bc.Invalidate(1)
+}
+
+func TestBlockCacheEviction(t *testing.T) {
+	bc := NewBlockCache(3)
+	for h := uint64(1); h <= 3; h++ {
+		bc.Add(structs.Block{Height: h}, "")
+	}
+
+	// height 1 becomes the most recently used, so 2 is evicted first
+	if _, _, ok := bc.Get(1); !ok {
+		t.Fatal("expected hit for height 1")
+	}
+	bc.Add(structs.Block{Height: 4}, "")
+	// replacing refreshes height 3, so 1 is evicted next
+	bc.Add(structs.Block{Height: 3, Hash: "new"}, "")
+	bc.Add(structs.Block{Height: 5}, "")
+
+	for h, want := range map[uint64]bool{1: false, 2: false, 3: true, 4: true, 5: true} {
+		if _, _, ok := bc.Get(h); ok != want {
+			t.Errorf("height %d: expected cached %t, got %t", h, want, ok)
+		}
+	}
+	if len(bc.space) != 3 || bc.recent.Len() != 3 {
+		t.Errorf("expected 3 cached blocks, got %d in map and %d in list", len(bc.space), bc.recent.Len())
+	}
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/figment-networks/kava-worker/api/types"
+)
+
+// GetBlockResults fetches begin and end block events of the block
+func (c *Client) GetBlockResults(ctx context.Context, height uint64) (results types.ResultBlockResults, err error) {
+	if err = c.rateLimiter.Wait(ctx); err != nil {
+		return results, err
+	}
+
+	sCtx, cancel := context.WithTimeout(ctx, time.Second*50)
+	defer cancel()
+	req, err := http.NewRequestWithContext(sCtx, http.MethodGet, c.baseURL+"/block_results", nil)
+	if err != nil {
+		return results, err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	if c.key != "" {
+		req.Header.Add("Authorization", c.key)
+	}
+
+	q := req.URL.Query()
+	q.Add("height", strconv.FormatUint(height, 10))
+	req.URL.RawQuery = q.Encode()
+
+	n := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return results, err
+	}
+	rawRequestHTTPDuration.WithLabels("/block_results", resp.Status).Observe(time.Since(n).Seconds())
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 299 {
+		serverError, _ := ioutil.ReadAll(resp.Body)
+		return results, fmt.Errorf("[KAVA-API] Error fetching block results: %d %s", resp.StatusCode, string(serverError))
+	}
+
+	var result types.GetBlockResultsResponse
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return results, err
+	}
+
+	if result.Error.Message != "" {
+		return results, fmt.Errorf("[KAVA-API] Error fetching block results: %s ", result.Error.Message)
+	}
+
+	return result.Result, nil
+}
@@ -20,8 +20,14 @@ type Client struct {
 	logger     *zap.Logger
 
 	rateLimiter *rate.Limiter
-	Sbc         *SimpleBlockCache
+	BlockCache  *BlockCache
 	CallMap     sync.Map
+
+	// RawCache optionally keeps raw responses of /block and /tx_search
+	RawCache RawCache
+
+	// Valuator optionally annotates converted transactions with USD values
+	Valuator *USDValuator
 }
 
 // NewClient returns a new client for a given endpoint
@@ -44,7 +50,7 @@ func NewClient(url, key string, logger *zap.Logger, c *http.Client, reqPerSecLim
 		httpClient:  c,
 		rateLimiter: rateLimiter,
 		cdc:         app.MakeCodec(),
-		Sbc:         NewSimpleBlockCache(400),
+		BlockCache:  NewBlockCache(400),
 	}
 	return cli
 }
@@ -55,3 +61,8 @@ func InitMetrics() {
 	numberOfItemsInBlock = numberOfItemsBlock.WithLabels("transactions")
 	transactionConversionDuration = conversionDuration.WithLabels("transaction")
 }
+
+// SetCodec replaces codec used to decode transactions (see Codecs)
+func (c *Client) SetCodec(cdc *codec.Codec) {
+	c.cdc = cdc
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/figment-networks/kava-worker/api/recorder"
+	"go.uber.org/zap"
+)
+
+const (
+	fixtureHeight     = 1000
+	fixtureBlockHash  = "6C5F4D9A3B1E2F7081A2B3C4D5E6F708192A3B4C5D6E7F8091A2B3C4D5E6F7A8"
+	fixtureParentHash = "1A2B3C4D5E6F708192A3B4C5D6E7F8091A2B3C4D5E6F708192A3B4C5D6E7F809"
+	fixtureTxHash     = "25C62396C5F54525133F721504301416B2D2AB1E6CA5F252EBDBBD064BE13221"
+	fixtureAccount    = "kava1ve5hsar4wfjj6um9dejx2u3dv9jxgu33rl7l3u"
+)
+
+// newFixtureClient creates client of the fake node serving testdata/fixtures
+func newFixtureClient(t *testing.T) (*Client, *recorder.Server) {
+	t.Helper()
+	InitMetrics()
+	srv := recorder.NewServer("testdata/fixtures")
+	t.Cleanup(srv.Close)
+	return NewClient(srv.URL, "", zap.NewNop(), nil, 100), srv
+}
+
+func TestGetBlockWithParent(t *testing.T) {
+	c, srv := newFixtureClient(t)
+	ctx := context.Background()
+
+	block, parentHash, err := c.GetBlockWithParent(ctx, structs.HeightHash{Height: fixtureHeight})
+	if err != nil {
+		t.Fatalf("error getting block: %v", err)
+	}
+	if block.Hash != fixtureBlockHash || block.Height != fixtureHeight || block.ChainID != "kava-4" || block.NumberOfTransactions != 1 {
+		t.Errorf("unexpected block %+v", block)
+	}
+	if parentHash != fixtureParentHash {
+		t.Errorf("expected parent hash %s, got %s", fixtureParentHash, parentHash)
+	}
+
+	// the second call is served from cache
+	if _, _, err := c.GetBlockWithParent(ctx, structs.HeightHash{Height: fixtureHeight}); err != nil {
+		t.Fatalf("error getting cached block: %v", err)
+	}
+	if n := srv.Requests("/block"); n != 1 {
+		t.Errorf("expected 1 request to node, got %d", n)
+	}
+
+	if _, _, err := c.GetBlockWithParent(ctx, structs.HeightHash{Height: fixtureHeight + 1}); err == nil {
+		t.Error("expected error for height without fixture")
+	}
+}
+
+func TestSearchTx(t *testing.T) {
+	c, _ := newFixtureClient(t)
+	ctx := context.Background()
+
+	block, err := c.GetBlock(ctx, structs.HeightHash{Height: fixtureHeight})
+	if err != nil {
+		t.Fatalf("error getting block: %v", err)
+	}
+	txs, err := c.SearchTx(ctx, structs.HeightHash{Height: fixtureHeight}, block, 100)
+	if err != nil {
+		t.Fatalf("error searching transactions: %v", err)
+	}
+	if len(txs) != 1 {
+		t.Fatalf("expected 1 transaction, got %d", len(txs))
+	}
+
+	tx := txs[0]
+	if tx.Hash != fixtureTxHash || tx.Height != fixtureHeight || tx.BlockHash != fixtureBlockHash || tx.ChainID != "kava-4" || !tx.Time.Equal(block.Time) {
+		t.Errorf("unexpected transaction %+v", tx)
+	}
+	if tx.Memo != "fixture" || tx.GasWanted != 200000 || tx.GasUsed != 61234 {
+		t.Errorf("unexpected memo or gas: %q %d %d", tx.Memo, tx.GasWanted, tx.GasUsed)
+	}
+	if len(tx.Fee) != 1 || tx.Fee[0].Text != "5000" {
+		t.Errorf("unexpected fee %+v", tx.Fee)
+	}
+
+	var send *structs.SubsetEvent
+	var ledger []structs.SubsetEvent
+	for _, ev := range tx.Events {
+		for i, sub := range ev.Sub {
+			switch {
+			case len(sub.Type) > 0 && sub.Type[0] == LedgerSubType:
+				ledger = append(ledger, sub)
+			case ev.Kind == "send":
+				send = &ev.Sub[i]
+			}
+		}
+	}
+	// fee and the transfer of the message
+	if len(ledger) != 2 || ledger[0].Action != "fee" || ledger[1].Action != "transfer" {
+		t.Errorf("unexpected ledger events %+v", ledger)
+	}
+	if send == nil {
+		t.Fatalf("expected send event, got %+v", tx.Events)
+	}
+	if send.Module != "bank" || len(send.Sender) != 1 || len(send.Recipient) != 1 {
+		t.Fatalf("unexpected send event %+v", send)
+	}
+	if amounts := send.Recipient[0].Amounts; len(amounts) != 1 || amounts[0].Text != "1000000" {
+		t.Errorf("unexpected recipient amounts %+v", amounts)
+	}
+}
+
+func TestGetReward(t *testing.T) {
+	c, _ := newFixtureClient(t)
+
+	resp, err := c.GetReward(context.Background(), structs.HeightAccount{Height: fixtureHeight, Account: fixtureAccount})
+	if err != nil {
+		t.Fatalf("error getting reward: %v", err)
+	}
+	if resp.Height != fixtureHeight || len(resp.Rewards) != 1 {
+		t.Fatalf("unexpected reward response %+v", resp)
+	}
+	for _, amounts := range resp.Rewards {
+		if len(amounts) != 1 || amounts[0].Text != "123.456000000000000000" {
+			t.Errorf("unexpected reward amounts %+v", amounts)
+		}
+	}
+}
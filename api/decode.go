@@ -0,0 +1,201 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/figment-networks/kava-worker/api/types"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/kava-labs/kava/app"
+	"go.uber.org/zap"
+)
+
+// DefaultCodec is the codec version of the current chain
+const DefaultCodec = "kava-4"
+
+// Codecs are amino codecs of supported chain versions
+var Codecs = map[string]func() *codec.Codec{
+	DefaultCodec: app.MakeCodec,
+}
+
+// CodecVersions returns names of supported codec versions
+func CodecVersions() []string {
+	versions := make([]string, 0, len(Codecs))
+	for v := range Codecs {
+		versions = append(versions, v)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// Decoder converts raw transactions the same way as indexed ones, without the node
+type Decoder struct {
+	version string
+	cdc     *codec.Codec
+	logger  *zap.Logger
+}
+
+// NewDecoder is Decoder constructor
+func NewDecoder(version string, logger *zap.Logger) (*Decoder, error) {
+	makeCodec, ok := Codecs[version]
+	if !ok {
+		return nil, fmt.Errorf("unknown codec version %q, supported: %s", version, strings.Join(CodecVersions(), ", "))
+	}
+	InitMetrics()
+	return &Decoder{version: version, cdc: makeCodec(), logger: logger}, nil
+}
+
+// Codec returns amino codec of the decoder
+func (d *Decoder) Codec() *codec.Codec {
+	return d.cdc
+}
+
+// WithLogger returns decoder sharing the codec, logging into given logger
+func (d *Decoder) WithLogger(logger *zap.Logger) *Decoder {
+	return &Decoder{version: d.version, cdc: d.cdc, logger: logger}
+}
+
+// Transaction converts single result of /tx_search
+func (d *Decoder) Transaction(ctx context.Context, in types.TxResponse) (structs.Transaction, error) {
+	return rawToTransaction(ctx, in, d.logger, d.cdc)
+}
+
+// Search converts all transactions of /tx_search result, filling block details when block is given
+func (d *Decoder) Search(ctx context.Context, result types.ResultTxSearch, block *structs.Block) (txs []structs.Transaction, err error) {
+	for _, txRaw := range result.Txs {
+		tx, err := d.Transaction(ctx, txRaw)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding transaction %s: %w", txRaw.Hash, err)
+		}
+		if block != nil {
+			tx.BlockHash = block.Hash
+			tx.ChainID = block.ChainID
+			tx.Time = block.Time
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+// Raw converts transaction stored as Raw (base64 encoded transaction) and RawLog of structs.Transaction.
+// Unlike Transaction, it fails when raw is not an encoded transaction.
+func (d *Decoder) Raw(ctx context.Context, raw, rawLog []byte) (structs.Transaction, error) {
+	// rawToTransaction only logs decoding problems
+	if _, err := d.stdTx(string(raw)); err != nil {
+		return structs.Transaction{}, err
+	}
+	in, err := TxResponseFromBase64(string(raw))
+	if err != nil {
+		return structs.Transaction{}, err
+	}
+	in.TxResult.Log = string(rawLog)
+	return d.Transaction(ctx, in)
+}
+
+// Ledger produces ledger entries of transaction converted by the decoder
+func (d *Decoder) Ledger(tx structs.Transaction) ([]LedgerEntry, error) {
+	return transactionLedger(d.cdc, tx)
+}
+
+// Message is a single message of the transaction
+type Message struct {
+	Index   int             `json:"index"`
+	Route   string          `json:"route"`
+	Type    string          `json:"type"`
+	Signers []string        `json:"signers"`
+	Amino   json.RawMessage `json:"amino"`
+}
+
+// Messages decodes base64 encoded transaction into the list of its messages
+func (d *Decoder) Messages(txData string) (msgs []Message, err error) {
+	tx, err := d.stdTx(txData)
+	if err != nil {
+		return nil, err
+	}
+	for i, msg := range tx.Msgs {
+		m := Message{Index: i, Route: msg.Route(), Type: msg.Type()}
+		for _, s := range msg.GetSigners() {
+			m.Signers = append(m.Signers, s.String())
+		}
+		if m.Amino, err = d.cdc.MarshalJSON(msg); err != nil {
+			return nil, fmt.Errorf("error encoding message %d: %w", i, err)
+		}
+		msgs = append(msgs, m)
+	}
+	return msgs, nil
+}
+
+// DecodeRequest is a transaction to decode, in the form it's kept in structs.Transaction
+type DecodeRequest struct {
+	// Raw is base64 encoded transaction
+	Raw string `json:"raw"`
+	// RawLog is optional, without it events are derived from messages only
+	RawLog string `json:"raw_log,omitempty"`
+}
+
+// DecodeResponse is a decoded transaction along with its messages
+type DecodeResponse struct {
+	Codec       string              `json:"codec"`
+	Messages    []Message           `json:"messages"`
+	Transaction structs.Transaction `json:"transaction"`
+	Ledger      []LedgerEntry       `json:"ledger,omitempty"`
+}
+
+// Decode converts transaction and lists its messages
+func (d *Decoder) Decode(ctx context.Context, req DecodeRequest) (resp DecodeResponse, err error) {
+	resp.Codec = d.version
+	if resp.Messages, err = d.Messages(req.Raw); err != nil {
+		return resp, err
+	}
+	if resp.Transaction, err = d.Raw(ctx, []byte(req.Raw), []byte(req.RawLog)); err != nil {
+		return resp, err
+	}
+	resp.Ledger, err = d.Ledger(resp.Transaction)
+	return resp, err
+}
+
+// AminoJSON decodes base64 encoded transaction into its amino JSON representation
+func (d *Decoder) AminoJSON(txData string) (json.RawMessage, error) {
+	tx, err := d.stdTx(txData)
+	if err != nil {
+		return nil, err
+	}
+	return d.cdc.MarshalJSON(tx)
+}
+
+func (d *Decoder) stdTx(txData string) (tx auth.StdTx, err error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(txData))
+	if err != nil {
+		return tx, fmt.Errorf("error decoding base64: %w", err)
+	}
+	if err := d.cdc.UnmarshalBinaryLengthPrefixed(raw, &tx); err != nil {
+		return tx, fmt.Errorf("error decoding transaction: %w", err)
+	}
+	return tx, nil
+}
+
+// TxResponseFromBase64 creates /tx_search result of bare base64 encoded transaction (without logs and gas).
+// Hash is computed the same way as tendermint does.
+func TxResponseFromBase64(txData string) (in types.TxResponse, err error) {
+	txData = strings.TrimSpace(txData)
+	raw, err := base64.StdEncoding.DecodeString(txData)
+	if err != nil {
+		return in, fmt.Errorf("error decoding base64: %w", err)
+	}
+	sum := sha256.Sum256(raw)
+	return types.TxResponse{
+		Hash:     strings.ToUpper(hex.EncodeToString(sum[:])),
+		Height:   "0",
+		TxData:   txData,
+		TxResult: types.ResponseDeliverTx{GasWanted: "0", GasUsed: "0"},
+	}, nil
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// maxDecodeBody is the limit of /decode request body
+	maxDecodeBody = 16 << 20
+	// maxDecodeBatch is the limit of transactions decoded in single request
+	maxDecodeBatch = 1000
+)
+
+// DecodeError is returned in place of DecodeResponse of transaction that cannot be decoded
+type DecodeError struct {
+	Error string `json:"error"`
+}
+
+// AttachHTTP registers /decode endpoint, which decodes POSTed DecodeRequest (or array of them)
+// into DecodeResponse (or array of DecodeResponse and DecodeError, in order of request).
+func (d *Decoder) AttachHTTP(mux *http.ServeMux) {
+	mux.HandleFunc("/decode", d.handleDecode)
+}
+
+func (d *Decoder) handleDecode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxDecodeBody))
+	if err != nil {
+		http.Error(w, "error reading body: "+err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if body = bytes.TrimSpace(body); len(body) > 0 && body[0] != '[' {
+		req := DecodeRequest{}
+		if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, "wrong request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp, err := d.Decode(r.Context(), req)
+		if err != nil {
+			decodeRequests.WithLabels("error").Inc()
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(DecodeError{Error: err.Error()})
+			return
+		}
+		decodeRequests.WithLabels("ok").Inc()
+		writeDecodeJSON(w, resp)
+		return
+	}
+
+	reqs := []DecodeRequest{}
+	if err := json.Unmarshal(body, &reqs); err != nil {
+		http.Error(w, "wrong request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(reqs) > maxDecodeBatch {
+		http.Error(w, fmt.Sprintf("too many transactions, the limit is %d", maxDecodeBatch), http.StatusBadRequest)
+		return
+	}
+
+	out := make([]interface{}, len(reqs))
+	for i, req := range reqs {
+		resp, err := d.Decode(r.Context(), req)
+		if err != nil {
+			d.logger.Debug("[KAVA-API] Error decoding transaction", zap.Int("index", i), zap.Error(err))
+			decodeRequests.WithLabels("error").Inc()
+			out[i] = DecodeError{Error: err.Error()}
+			continue
+		}
+		decodeRequests.WithLabels("ok").Inc()
+		out[i] = resp
+	}
+	writeDecodeJSON(w, out)
+}
+
+func writeDecodeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(v); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
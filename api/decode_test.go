@@ -0,0 +1,162 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/figment-networks/kava-worker/api/types"
+	"go.uber.org/zap"
+)
+
+func readCorpus(t *testing.T, name string) types.TxResponse {
+	t.Helper()
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "corpus", name+".json"))
+	if err != nil {
+		t.Fatalf("error reading corpus: %v", err)
+	}
+	in := types.TxResponse{}
+	if err := json.Unmarshal(data, &in); err != nil {
+		t.Fatalf("error decoding corpus: %v", err)
+	}
+	return in
+}
+
+func TestDecoderRaw(t *testing.T) {
+	dec, err := NewDecoder(DefaultCodec, zap.NewNop())
+	if err != nil {
+		t.Fatalf("error creating decoder: %v", err)
+	}
+	in := readCorpus(t, "bank_send")
+
+	want, err := dec.Transaction(context.Background(), in)
+	if err != nil {
+		t.Fatalf("error converting transaction: %v", err)
+	}
+	// Raw and RawLog are all that is kept of the transaction, gas is not
+	want.GasWanted, want.GasUsed = 0, 0
+
+	got, err := dec.Raw(context.Background(), want.Raw, want.RawLog)
+	if err != nil {
+		t.Fatalf("error converting raw transaction: %v", err)
+	}
+	got.Height = want.Height
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("raw conversion differs\nwant %+v\ngot  %+v", want, got)
+	}
+
+	for _, raw := range []string{"aGVsbG8gd29ybGQ=", "not base64", ""} {
+		if _, err := dec.Raw(context.Background(), []byte(raw), nil); err == nil {
+			t.Errorf("expected error for %q", raw)
+		}
+	}
+
+	if _, err := NewDecoder("kava-0", zap.NewNop()); err == nil {
+		t.Error("expected error for unknown codec version")
+	}
+}
+
+func TestDecoderMessages(t *testing.T) {
+	dec, err := NewDecoder(DefaultCodec, zap.NewNop())
+	if err != nil {
+		t.Fatalf("error creating decoder: %v", err)
+	}
+
+	msgs, err := dec.Messages(readCorpus(t, "bank_multisend").TxData)
+	if err != nil {
+		t.Fatalf("error decoding messages: %v", err)
+	}
+	if len(msgs) != 1 || msgs[0].Route != "bank" || msgs[0].Type != "multisend" || len(msgs[0].Signers) == 0 {
+		t.Fatalf("unexpected messages %+v", msgs)
+	}
+
+	amino := struct {
+		Type string `json:"type"`
+	}{}
+	if err := json.Unmarshal(msgs[0].Amino, &amino); err != nil {
+		t.Fatalf("error decoding amino JSON: %v", err)
+	}
+	if amino.Type != "cosmos-sdk/MsgMultiSend" {
+		t.Errorf("unexpected amino type %q", amino.Type)
+	}
+
+	if _, err := dec.Messages("not base64"); err == nil {
+		t.Error("expected error for malformed transaction")
+	}
+}
+
+func TestDecodeHTTP(t *testing.T) {
+	dec, err := NewDecoder(DefaultCodec, zap.NewNop())
+	if err != nil {
+		t.Fatalf("error creating decoder: %v", err)
+	}
+	mux := http.NewServeMux()
+	dec.AttachHTTP(mux)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	in := readCorpus(t, "bank_send")
+	want, err := dec.Decode(context.Background(), DecodeRequest{Raw: in.TxData, RawLog: in.TxResult.Log})
+	if err != nil {
+		t.Fatalf("error decoding transaction: %v", err)
+	}
+	if want.Transaction.Memo == "" || len(want.Transaction.Fee) == 0 || len(want.Transaction.Events) == 0 {
+		t.Fatalf("incomplete transaction %+v", want.Transaction)
+	}
+
+	post := func(body interface{}) *http.Response {
+		data, _ := json.Marshal(body)
+		resp, err := http.Post(srv.URL+"/decode", "application/json", bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("error posting request: %v", err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	resp := post(DecodeRequest{Raw: in.TxData, RawLog: in.TxResult.Log})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status %d", resp.StatusCode)
+	}
+	got := DecodeResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("error decoding response: %v", err)
+	}
+	if got.Codec != DefaultCodec || got.Transaction.Hash != in.Hash || len(got.Messages) != 1 || len(got.Transaction.Events) != len(want.Transaction.Events) {
+		t.Errorf("unexpected response %+v", got)
+	}
+
+	resp = post([]DecodeRequest{{Raw: in.TxData}, {Raw: "not base64"}})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected batch status %d", resp.StatusCode)
+	}
+	batch := []struct {
+		DecodeResponse
+		DecodeError
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&batch); err != nil {
+		t.Fatalf("error decoding batch response: %v", err)
+	}
+	if len(batch) != 2 || batch[0].Transaction.Hash != in.Hash || batch[0].Error != "" || batch[1].Error == "" {
+		t.Errorf("unexpected batch response %+v", batch)
+	}
+
+	if resp = post(DecodeRequest{Raw: "not base64"}); resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("expected status %d for malformed transaction, got %d", http.StatusUnprocessableEntity, resp.StatusCode)
+	}
+
+	get, err := http.Get(srv.URL + "/decode")
+	if err != nil {
+		t.Fatalf("error getting: %v", err)
+	}
+	get.Body.Close()
+	if get.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("expected status %d for GET, got %d", http.StatusMethodNotAllowed, get.StatusCode)
+	}
+}
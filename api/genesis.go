@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/kava-labs/kava/app"
+	"github.com/tendermint/tendermint/libs/bech32"
+)
+
+// genesisTimeout is the timeout of genesis request, the document may be large
+const genesisTimeout = 5 * time.Minute
+
+type genesisDoc struct {
+	AppState map[string]json.RawMessage `json:"app_state"`
+}
+
+type genesisResponse struct {
+	Result struct {
+		Genesis genesisDoc `json:"genesis"`
+	} `json:"result"`
+	Error struct {
+		Message string `json:"message"`
+		Data    string `json:"data"`
+	} `json:"error"`
+}
+
+// GetGenesisBalances gets initial balances of the addresses from the genesis served by node.
+// Addresses missing in genesis have no balances.
+func (c *Client) GetGenesisBalances(ctx context.Context, addresses []string) (map[string]sdk.Coins, error) {
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	gCtx, cancel := context.WithTimeout(ctx, genesisTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(gCtx, http.MethodGet, c.baseURL+"/genesis", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	if c.key != "" {
+		req.Header.Add("Authorization", c.key)
+	}
+
+	// client timeout is meant for small responses
+	httpClient := *c.httpClient
+	httpClient.Timeout = 0
+
+	n := time.Now()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	rawRequestHTTPDuration.WithLabels("/genesis", resp.Status).Observe(time.Since(n).Seconds())
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 299 {
+		serverError, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("[KAVA-API] Error fetching genesis: %d %s", resp.StatusCode, string(serverError))
+	}
+
+	result := &genesisResponse{}
+	if err = json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return nil, fmt.Errorf("[KAVA-API] Error decoding genesis: %w", err)
+	}
+	if result.Error.Message != "" {
+		return nil, fmt.Errorf("[KAVA-API] Error fetching genesis: %s %s", result.Error.Message, result.Error.Data)
+	}
+
+	return genesisBalances(c.cdc, result.Result.Genesis, addresses)
+}
+
+// ReadGenesisBalances gets initial balances of the addresses from genesis document (eg. genesis.json file)
+func ReadGenesisBalances(r io.Reader, addresses []string) (map[string]sdk.Coins, error) {
+	doc := genesisDoc{}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("[KAVA-API] Error decoding genesis: %w", err)
+	}
+	return genesisBalances(app.MakeCodec(), doc, addresses)
+}
+
+// genesisBalances reads coins of the addresses from genesis accounts of auth module
+func genesisBalances(cdc *codec.Codec, doc genesisDoc, addresses []string) (map[string]sdk.Coins, error) {
+	authState, ok := doc.AppState[auth.ModuleName]
+	if !ok {
+		return nil, fmt.Errorf("[KAVA-API] Genesis has no %s module state", auth.ModuleName)
+	}
+	gs := auth.GenesisState{}
+	if err := cdc.UnmarshalJSON(authState, &gs); err != nil {
+		return nil, fmt.Errorf("[KAVA-API] Error decoding genesis accounts: %w", err)
+	}
+
+	// accounts are matched by address bytes, as bech32 prefix of sdk config isn't set
+	byBytes := make(map[string]string, len(addresses))
+	balances := make(map[string]sdk.Coins, len(addresses))
+	for _, addr := range addresses {
+		_, bz, err := bech32.DecodeAndConvert(addr)
+		if err != nil {
+			return nil, fmt.Errorf("[KAVA-API] Wrong account address %q: %w", addr, err)
+		}
+		byBytes[string(bz)] = addr
+		balances[addr] = sdk.Coins{}
+	}
+	for _, acc := range gs.Accounts {
+		if addr, ok := byBytes[string(acc.GetAddress())]; ok {
+			balances[addr] = acc.GetCoins()
+		}
+	}
+	return balances, nil
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	authexported "github.com/cosmos/cosmos-sdk/x/auth/exported"
+	"github.com/kava-labs/kava/app"
+	"github.com/tendermint/tendermint/libs/bech32"
+	"go.uber.org/zap"
+)
+
+// testGenesis returns genesis document with a single account of fixtureAccount
+func testGenesis(t *testing.T) []byte {
+	t.Helper()
+	_, bz, err := bech32.DecodeAndConvert(fixtureAccount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acc := auth.NewBaseAccountWithAddress(bz)
+	acc.Coins = sdk.NewCoins(sdk.NewInt64Coin("ukava", 1500), sdk.NewInt64Coin("hard", 7))
+
+	gs := auth.DefaultGenesisState()
+	gs.Accounts = authexported.GenesisAccounts{&acc}
+	authState, err := app.MakeCodec().MarshalJSON(gs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := json.Marshal(map[string]interface{}{
+		"chain_id":  "kava-4",
+		"app_state": map[string]json.RawMessage{auth.ModuleName: authState},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestGenesisBalances(t *testing.T) {
+	doc := testGenesis(t)
+	other := "kava1ve5hsar4wfjj6un9vd5hq6t9de6z6cf38jaha7"
+
+	check := func(t *testing.T, balances map[string]sdk.Coins) {
+		t.Helper()
+		if got := balances[fixtureAccount]; !got.IsEqual(sdk.NewCoins(sdk.NewInt64Coin("ukava", 1500), sdk.NewInt64Coin("hard", 7))) {
+			t.Errorf("balances of genesis account = %s", got)
+		}
+		if got, ok := balances[other]; !ok || !got.Empty() {
+			t.Errorf("balances of account missing in genesis = %s, %v", got, ok)
+		}
+	}
+
+	t.Run("file", func(t *testing.T) {
+		balances, err := ReadGenesisBalances(bytes.NewReader(doc), []string{fixtureAccount, other})
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, balances)
+	})
+
+	t.Run("node", func(t *testing.T) {
+		InitMetrics()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/genesis" {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": -1, "result": {"genesis": %s}}`, doc)
+		}))
+		defer srv.Close()
+
+		balances, err := NewClient(srv.URL, "", zap.NewNop(), nil, 100).GetGenesisBalances(context.Background(), []string{fixtureAccount, other})
+		if err != nil {
+			t.Fatal(err)
+		}
+		check(t, balances)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if _, err := ReadGenesisBalances(bytes.NewReader([]byte(`{"app_state": {}}`)), []string{fixtureAccount}); err == nil {
+			t.Error("expected error for genesis without auth state")
+		}
+		if _, err := ReadGenesisBalances(bytes.NewReader(doc), []string{"kava1"}); err == nil {
+			t.Error("expected error for wrong address")
+		}
+	})
+}
+
+func TestGetBlockResultsStatus(t *testing.T) {
+	InitMetrics()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// body decodes without JSON-RPC error
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	if _, err := NewClient(srv.URL, "", zap.NewNop(), nil, 100).GetBlockResults(context.Background(), 10); err == nil {
+		t.Error("expected error for non 2xx status")
+	}
+}
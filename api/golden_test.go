@@ -0,0 +1,108 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/figment-networks/kava-worker/api/types"
+
+	"github.com/kava-labs/kava/app"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+var update = flag.Bool("update", false, "update golden files of converted transactions")
+
+// TestGolden converts every transaction of testdata/corpus (tx_search results, one per file),
+// comparing the output with its snapshot in testdata/golden. Run with -update to accept changes.
+func TestGolden(t *testing.T) {
+	InitMetrics()
+	cdc := app.MakeCodec()
+
+	files, err := filepath.Glob(filepath.Join("testdata", "corpus", "*.json"))
+	if err != nil {
+		t.Fatalf("error listing corpus: %v", err)
+	}
+	if len(files) == 0 {
+		t.Fatal("empty corpus")
+	}
+
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".json")
+		t.Run(name, func(t *testing.T) {
+			data, err := ioutil.ReadFile(file)
+			if err != nil {
+				t.Fatalf("error reading corpus: %v", err)
+			}
+			in := types.TxResponse{}
+			if err := json.Unmarshal(data, &in); err != nil {
+				t.Fatalf("error decoding corpus: %v", err)
+			}
+
+			// mappers report problems only in logs
+			core, logs := observer.New(zap.WarnLevel)
+			tx, err := rawToTransaction(context.Background(), in, zap.New(core), cdc)
+			if err != nil {
+				t.Fatalf("error converting transaction: %v", err)
+			}
+			for _, entry := range logs.All() {
+				t.Errorf("conversion logged %q: %v", entry.Message, entry.ContextMap())
+			}
+
+			got, err := json.MarshalIndent(tx, "", "  ")
+			if err != nil {
+				t.Fatalf("error encoding transaction: %v", err)
+			}
+			got = append(got, '\n')
+
+			golden := filepath.Join("testdata", "golden", name+".json")
+			if *update {
+				if err := ioutil.WriteFile(golden, got, 0644); err != nil {
+					t.Fatalf("error updating golden file: %v", err)
+				}
+				return
+			}
+
+			want, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("error reading golden file (run with -update to create it): %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("output differs from %s (run with -update to accept):\n%s", golden, diffLines(string(want), string(got)))
+			}
+		})
+	}
+}
+
+// diffLines lists (up to 20) lines that differ between want and got
+func diffLines(want, got string) string {
+	wl, gl := strings.Split(want, "\n"), strings.Split(got, "\n")
+	n := len(wl)
+	if len(gl) > n {
+		n = len(gl)
+	}
+
+	s := strings.Builder{}
+	diffs := 0
+	for i := 0; i < n && diffs < 20; i++ {
+		var w, g string
+		if i < len(wl) {
+			w = wl[i]
+		}
+		if i < len(gl) {
+			g = gl[i]
+		}
+		if w != g {
+			fmt.Fprintf(&s, "line %d:\n  - %s\n  + %s\n", i+1, w, g)
+			diffs++
+		}
+	}
+	return s.String()
+}
@@ -0,0 +1,311 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/figment-networks/kava-worker/api/types"
+	"github.com/figment-networks/kava-worker/api/util"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/staking"
+	"github.com/cosmos/cosmos-sdk/x/supply"
+	"github.com/kava-labs/kava/app"
+	"github.com/tendermint/tendermint/libs/bech32"
+)
+
+// LedgerDirection is a side of ledger entry
+type LedgerDirection string
+
+const (
+	LedgerDebit  LedgerDirection = "debit"
+	LedgerCredit LedgerDirection = "credit"
+)
+
+// LedgerEntry is a single balance change of an account
+type LedgerEntry struct {
+	Direction    LedgerDirection `json:"direction"`
+	Account      string          `json:"account"`
+	Counterparty string          `json:"counterparty"`
+	// Action is a source of balance change (transfer, fee, delegate, complete_unbonding)
+	Action string `json:"action"`
+	// Denom is a base denomination (eg. ukava)
+	Denom  string   `json:"denom"`
+	Amount *big.Int `json:"amount"`
+	// MsgIndex is the index of message that produced the entry (-1 for fees)
+	MsgIndex int    `json:"msg_index"`
+	TxHash   string `json:"tx_hash,omitempty"`
+}
+
+// LedgerSubType is a type of subset events carrying ledger entries.
+// They are added to the event of the message that produced them (fees to the first message), so they aren't counted as messages.
+const LedgerSubType = "ledger"
+
+// BondDenom is a staking denomination, amounts of delegate events are given without it
+var BondDenom = "ukava"
+
+var (
+	feeCollectorAddr  string
+	bondedPoolAddr    string
+	notBondedPoolAddr string
+)
+
+func init() {
+	feeCollectorAddr, _ = bech32.ConvertAndEncode(app.Bech32MainPrefix, supply.NewModuleAddress(auth.FeeCollectorName).Bytes())
+	bondedPoolAddr, _ = bech32.ConvertAndEncode(app.Bech32MainPrefix, supply.NewModuleAddress(staking.BondedPoolName).Bytes())
+	notBondedPoolAddr, _ = bech32.ConvertAndEncode(app.Bech32MainPrefix, supply.NewModuleAddress(staking.NotBondedPoolName).Bytes())
+}
+
+// ProduceLedger creates ledger entries from fee, transfer and staking events of every message.
+// Staking module moves delegated coins without transfer events, so the balance changes of delegators
+// are taken from delegate, create_validator and complete_unbonding events.
+func ProduceLedger(txHash string, fee sdk.Coins, feePayer sdk.AccAddress, lf []types.LogFormat) (entries []LedgerEntry, err error) {
+	if !fee.IsZero() && !feePayer.Empty() {
+		payer, err := bech32.ConvertAndEncode(app.Bech32MainPrefix, feePayer.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("error converting fee payer address: %w", err)
+		}
+
+		for _, coin := range fee {
+			entries = append(entries, ledgerPair(payer, feeCollectorAddr, "fee", coin.Denom, coin.Amount.BigInt(), -1, txHash)...)
+		}
+	}
+
+	for _, logf := range lf {
+		for _, ev := range logf.Events {
+			if ev.Type == "complete_unbonding" {
+				unbonded, err := unbondingLedger(ev, int(logf.MsgIndex), txHash)
+				if err != nil {
+					return entries, err
+				}
+				entries = append(entries, unbonded...)
+				continue
+			}
+			if ev.Type != "transfer" {
+				continue
+			}
+
+			var sender, recipient string
+			for _, attr := range ev.Attributes {
+				if len(attr.Recipient) > 0 {
+					recipient = attr.Recipient[0]
+				}
+				if len(attr.Sender) > 0 {
+					sender = attr.Sender[0]
+				}
+				if len(attr.Amount) == 0 {
+					continue
+				}
+
+				coins, err := util.ParseCoins(attr.Amount[0])
+				if err != nil {
+					return entries, fmt.Errorf("error parsing transfer amount: %w", err)
+				}
+				for _, c := range coins {
+					if c.Exp != 0 {
+						return entries, fmt.Errorf("error parsing transfer amount: unexpected decimal coin %s", c.Text)
+					}
+					entries = append(entries, ledgerPair(sender, recipient, "transfer", c.Denom, c.Numeric, int(logf.MsgIndex), txHash)...)
+				}
+			}
+		}
+
+		delegated, err := delegationLedger(logf, txHash)
+		if err != nil {
+			return entries, err
+		}
+		entries = append(entries, delegated...)
+	}
+
+	return entries, nil
+}
+
+// delegationLedger creates entries of coins delegated by the message, from delegator's account to the bonded pool.
+// Delegator is the last sender of message event, as senders of transfers made by the message (eg. withdrawn rewards) precede it.
+// Coins delegated to a validator that is not bonded go to the not bonded pool, which events do not tell apart.
+func delegationLedger(logf types.LogFormat, txHash string) (entries []LedgerEntry, err error) {
+	var delegator string
+	var amounts []string
+	for _, ev := range logf.Events {
+		for _, attr := range ev.Attributes {
+			switch ev.Type {
+			case "message":
+				if len(attr.Sender) > 0 {
+					delegator = attr.Sender[len(attr.Sender)-1]
+				}
+			case "delegate", "create_validator":
+				amounts = append(amounts, attr.Amount...)
+			}
+		}
+	}
+
+	for _, a := range amounts {
+		coins, err := parseStakingAmount(a)
+		if err != nil {
+			return nil, err
+		}
+		if delegator == "" {
+			return nil, fmt.Errorf("error producing delegation ledger: missing delegator of %s", a)
+		}
+		for _, c := range coins {
+			entries = append(entries, ledgerPair(delegator, bondedPoolAddr, "delegate", c.Denom, c.Numeric, int(logf.MsgIndex), txHash)...)
+		}
+	}
+	return entries, nil
+}
+
+// unbondingLedger creates entries of coins returned to delegators from the not bonded pool
+func unbondingLedger(ev types.LogEvents, msgIndex int, txHash string) (entries []LedgerEntry, err error) {
+	var amount string
+	for _, attr := range ev.Attributes {
+		if len(attr.Amount) > 0 {
+			amount = attr.Amount[0]
+		}
+		delegator, ok := attr.Others["delegator"]
+		if !ok || len(delegator) == 0 || amount == "" {
+			continue
+		}
+
+		coins, err := parseStakingAmount(amount)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range coins {
+			entries = append(entries, ledgerPair(notBondedPoolAddr, delegator[0], "complete_unbonding", c.Denom, c.Numeric, msgIndex, txHash)...)
+		}
+		amount = ""
+	}
+	return entries, nil
+}
+
+// parseStakingAmount parses amount of staking event, which is given in bond denom without denomination
+func parseStakingAmount(amount string) ([]util.Coin, error) {
+	coins, err := util.ParseCoins(amount)
+	if errors.Is(err, util.ErrMissingDenom) {
+		numeric, exp, aErr := util.ParseAmount(amount)
+		if aErr != nil {
+			return nil, fmt.Errorf("error parsing staking amount: %w", aErr)
+		}
+		coins = []util.Coin{{Denom: BondDenom, Text: amount, Numeric: numeric, Exp: exp}}
+	} else if err != nil {
+		return nil, fmt.Errorf("error parsing staking amount: %w", err)
+	}
+
+	for _, c := range coins {
+		if c.Exp != 0 {
+			return nil, fmt.Errorf("error parsing staking amount: unexpected decimal coin %s", c.Text)
+		}
+	}
+	return coins, nil
+}
+
+// ProduceBlockLedger creates ledger entries from transfer and complete_unbonding events of begin or end blocker.
+// Action of transfer entries is set to the phase (eg. begin_block)
+func ProduceBlockLedger(phase string, events []types.BlockEvent) (entries []LedgerEntry, err error) {
+	lf := types.LogFormat{MsgIndex: -1}
+	for _, ev := range events {
+		le := types.LogEvents{Type: ev.Type}
+		for _, attr := range ev.Attributes {
+			lea := &types.LogEventsAttributes{}
+			switch string(attr.Key) {
+			case "recipient":
+				lea.Recipient = []string{string(attr.Value)}
+			case "sender":
+				lea.Sender = []string{string(attr.Value)}
+			case "amount":
+				lea.Amount = []string{string(attr.Value)}
+			case "delegator":
+				lea.Others = map[string][]string{"delegator": {string(attr.Value)}}
+			default:
+				continue
+			}
+			le.Attributes = append(le.Attributes, lea)
+		}
+		lf.Events = append(lf.Events, le)
+	}
+
+	entries, err = ProduceLedger("", nil, nil, []types.LogFormat{lf})
+	for i := range entries {
+		if entries[i].Action == "transfer" {
+			entries[i].Action = phase
+		}
+	}
+	return entries, err
+}
+
+func ledgerPair(from, to, action, denom string, amount *big.Int, msgIndex int, txHash string) []LedgerEntry {
+	return []LedgerEntry{
+		{Direction: LedgerDebit, Account: from, Counterparty: to, Action: action, Denom: denom, Amount: amount, MsgIndex: msgIndex, TxHash: txHash},
+		{Direction: LedgerCredit, Account: to, Counterparty: from, Action: action, Denom: denom, Amount: amount, MsgIndex: msgIndex, TxHash: txHash},
+	}
+}
+
+// attachLedger adds every debit and credit pair of entries as a subset event of the message event
+func attachLedger(tx *structs.Transaction, entries []LedgerEntry) {
+	if len(tx.Events) == 0 {
+		return
+	}
+	for _, e := range entries {
+		if e.Direction != LedgerDebit {
+			continue
+		}
+
+		ev := &tx.Events[0]
+		id := strconv.Itoa(e.MsgIndex)
+		for i := range tx.Events {
+			if tx.Events[i].ID == id {
+				ev = &tx.Events[i]
+				break
+			}
+		}
+
+		amount := util.NewAmount(e.Denom, e.Amount.String(), e.Amount, 0)
+		ev.Sub = append(ev.Sub, structs.SubsetEvent{
+			Type:      []string{LedgerSubType},
+			Action:    e.Action,
+			Module:    LedgerSubType,
+			Sender:    []structs.EventTransfer{{Account: structs.Account{ID: e.Account}, Amounts: []structs.TransactionAmount{amount}}},
+			Recipient: []structs.EventTransfer{{Account: structs.Account{ID: e.Counterparty}, Amounts: []structs.TransactionAmount{amount}}},
+		})
+	}
+}
+
+// Ledger produces ledger entries of converted transaction, from its Raw and RawLog
+func (c *Client) Ledger(tx structs.Transaction) ([]LedgerEntry, error) {
+	return transactionLedger(c.cdc, tx)
+}
+
+// transactionLedger decodes fee and fee payer of the transaction and produces its ledger.
+// Ledger is not a part of transaction events, as consumers read every event as a message.
+func transactionLedger(cdc *codec.Codec, tx structs.Transaction) ([]LedgerEntry, error) {
+	if len(tx.Raw) == 0 {
+		return nil, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(string(tx.Raw))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding raw transaction: %w", err)
+	}
+	stdTx := auth.StdTx{}
+	if err := cdc.UnmarshalBinaryLengthPrefixed(data, &stdTx); err != nil {
+		return nil, fmt.Errorf("error decoding raw transaction: %w", err)
+	}
+
+	// logs of failed transactions are not JSON, such transactions have no transfers
+	lf := []types.LogFormat{}
+	if len(tx.RawLog) > 0 {
+		json.Unmarshal(tx.RawLog, &lf)
+	}
+
+	var feePayer sdk.AccAddress
+	if len(stdTx.Msgs) > 0 {
+		feePayer = stdTx.FeePayer()
+	}
+	return ProduceLedger(tx.Hash, stdTx.Fee.Amount, feePayer, lf)
+}
@@ -0,0 +1,277 @@
+package api
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/figment-networks/kava-worker/api/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/kava-labs/kava/app"
+	"github.com/tendermint/tendermint/libs/bech32"
+	"go.uber.org/zap"
+)
+
+func transferLog(msgIndex float64, sender, recipient, amount string) types.LogFormat {
+	return types.LogFormat{
+		MsgIndex: msgIndex,
+		Events: []types.LogEvents{{
+			Type: "transfer",
+			Attributes: []*types.LogEventsAttributes{
+				{Recipient: []string{recipient}},
+				{Sender: []string{sender}},
+				{Amount: []string{amount}},
+			},
+		}},
+	}
+}
+
+func TestProduceLedger(t *testing.T) {
+	payerAddr := sdk.AccAddress([]byte("fee_payer_address___"))
+	payer, err := bech32.ConvertAndEncode(app.Bech32MainPrefix, payerAddr.Bytes())
+	if err != nil {
+		t.Fatalf("error encoding address: %v", err)
+	}
+	fee := sdk.NewCoins(sdk.NewInt64Coin("ukava", 5000))
+
+	tests := []struct {
+		name     string
+		fee      sdk.Coins
+		feePayer sdk.AccAddress
+		lf       []types.LogFormat
+		want     []LedgerEntry
+		wantErr  bool
+	}{
+		{
+			name:     "fee",
+			fee:      fee,
+			feePayer: payerAddr,
+			want: []LedgerEntry{
+				{Direction: LedgerDebit, Account: payer, Counterparty: feeCollectorAddr, Action: "fee", Denom: "ukava", Amount: big.NewInt(5000), MsgIndex: -1, TxHash: "HASH"},
+				{Direction: LedgerCredit, Account: feeCollectorAddr, Counterparty: payer, Action: "fee", Denom: "ukava", Amount: big.NewInt(5000), MsgIndex: -1, TxHash: "HASH"},
+			},
+		},
+		{
+			name: "missing fee payer",
+			fee:  fee,
+			lf:   []types.LogFormat{transferLog(0, "kava1from", "kava1to", "100ukava")},
+			want: []LedgerEntry{
+				{Direction: LedgerDebit, Account: "kava1from", Counterparty: "kava1to", Action: "transfer", Denom: "ukava", Amount: big.NewInt(100), MsgIndex: 0, TxHash: "HASH"},
+				{Direction: LedgerCredit, Account: "kava1to", Counterparty: "kava1from", Action: "transfer", Denom: "ukava", Amount: big.NewInt(100), MsgIndex: 0, TxHash: "HASH"},
+			},
+		},
+		{
+			name: "multi-coin transfer",
+			lf:   []types.LogFormat{transferLog(1, "kava1from", "kava1to", "100ukava,5hard")},
+			want: []LedgerEntry{
+				{Direction: LedgerDebit, Account: "kava1from", Counterparty: "kava1to", Action: "transfer", Denom: "ukava", Amount: big.NewInt(100), MsgIndex: 1, TxHash: "HASH"},
+				{Direction: LedgerCredit, Account: "kava1to", Counterparty: "kava1from", Action: "transfer", Denom: "ukava", Amount: big.NewInt(100), MsgIndex: 1, TxHash: "HASH"},
+				{Direction: LedgerDebit, Account: "kava1from", Counterparty: "kava1to", Action: "transfer", Denom: "hard", Amount: big.NewInt(5), MsgIndex: 1, TxHash: "HASH"},
+				{Direction: LedgerCredit, Account: "kava1to", Counterparty: "kava1from", Action: "transfer", Denom: "hard", Amount: big.NewInt(5), MsgIndex: 1, TxHash: "HASH"},
+			},
+		},
+		{
+			name: "non transfer events",
+			lf: []types.LogFormat{{Events: []types.LogEvents{{
+				Type:       "message",
+				Attributes: []*types.LogEventsAttributes{{Amount: []string{"100ukava"}}},
+			}}}},
+		},
+		{
+			name:    "decimal amount",
+			lf:      []types.LogFormat{transferLog(0, "kava1from", "kava1to", "1.5ukava")},
+			wantErr: true,
+		},
+		{
+			name: "delegate with withdrawn rewards",
+			lf: []types.LogFormat{{
+				MsgIndex: 0,
+				Events: []types.LogEvents{
+					{Type: "delegate", Attributes: []*types.LogEventsAttributes{
+						{Others: map[string][]string{"validator": {"kavavaloper1val"}}},
+						{Amount: []string{"5000000"}},
+					}},
+					{Type: "message", Attributes: []*types.LogEventsAttributes{
+						{Action: "delegate"},
+						{Sender: []string{"kava1distribution"}},
+						{Module: "staking"},
+						{Sender: []string{"kava1delegator"}},
+					}},
+					{Type: "transfer", Attributes: []*types.LogEventsAttributes{
+						{Recipient: []string{"kava1delegator"}},
+						{Sender: []string{"kava1distribution"}},
+						{Amount: []string{"25ukava"}},
+					}},
+				},
+			}},
+			want: []LedgerEntry{
+				{Direction: LedgerDebit, Account: "kava1distribution", Counterparty: "kava1delegator", Action: "transfer", Denom: "ukava", Amount: big.NewInt(25), MsgIndex: 0, TxHash: "HASH"},
+				{Direction: LedgerCredit, Account: "kava1delegator", Counterparty: "kava1distribution", Action: "transfer", Denom: "ukava", Amount: big.NewInt(25), MsgIndex: 0, TxHash: "HASH"},
+				{Direction: LedgerDebit, Account: "kava1delegator", Counterparty: bondedPoolAddr, Action: "delegate", Denom: "ukava", Amount: big.NewInt(5000000), MsgIndex: 0, TxHash: "HASH"},
+				{Direction: LedgerCredit, Account: bondedPoolAddr, Counterparty: "kava1delegator", Action: "delegate", Denom: "ukava", Amount: big.NewInt(5000000), MsgIndex: 0, TxHash: "HASH"},
+			},
+		},
+		{
+			name: "create validator",
+			lf: []types.LogFormat{{
+				MsgIndex: 1,
+				Events: []types.LogEvents{
+					{Type: "create_validator", Attributes: []*types.LogEventsAttributes{
+						{Others: map[string][]string{"validator": {"kavavaloper1val"}}},
+						{Amount: []string{"1000000"}},
+					}},
+					{Type: "message", Attributes: []*types.LogEventsAttributes{
+						{Module: "staking"},
+						{Sender: []string{"kava1operator"}},
+					}},
+				},
+			}},
+			want: []LedgerEntry{
+				{Direction: LedgerDebit, Account: "kava1operator", Counterparty: bondedPoolAddr, Action: "delegate", Denom: "ukava", Amount: big.NewInt(1000000), MsgIndex: 1, TxHash: "HASH"},
+				{Direction: LedgerCredit, Account: bondedPoolAddr, Counterparty: "kava1operator", Action: "delegate", Denom: "ukava", Amount: big.NewInt(1000000), MsgIndex: 1, TxHash: "HASH"},
+			},
+		},
+		{
+			name: "delegate without delegator",
+			lf: []types.LogFormat{{Events: []types.LogEvents{{
+				Type:       "delegate",
+				Attributes: []*types.LogEventsAttributes{{Amount: []string{"5000000"}}},
+			}}}},
+			wantErr: true,
+		},
+		{
+			name: "undelegate moves coins between pools only",
+			lf: []types.LogFormat{{Events: []types.LogEvents{
+				{Type: "unbond", Attributes: []*types.LogEventsAttributes{{Amount: []string{"5000000"}}}},
+				{Type: "message", Attributes: []*types.LogEventsAttributes{{Sender: []string{"kava1delegator"}}}},
+			}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ProduceLedger("HASH", tt.fee, tt.feePayer, tt.lf)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ProduceLedger() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ProduceLedger() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProduceBlockLedger(t *testing.T) {
+	events := []types.BlockEvent{
+		{Type: "transfer", Attributes: []types.BlockEventAttribute{
+			{Key: []byte("recipient"), Value: []byte("kava1to")},
+			{Key: []byte("sender"), Value: []byte("kava1from")},
+			{Key: []byte("amount"), Value: []byte("10hard")},
+		}},
+		{Type: "complete_unbonding", Attributes: []types.BlockEventAttribute{
+			{Key: []byte("amount"), Value: []byte("700ukava")},
+			{Key: []byte("validator"), Value: []byte("kavavaloper1val")},
+			{Key: []byte("delegator"), Value: []byte("kava1delegator")},
+		}},
+	}
+
+	got, err := ProduceBlockLedger("end_block", events)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []LedgerEntry{
+		{Direction: LedgerDebit, Account: "kava1from", Counterparty: "kava1to", Action: "end_block", Denom: "hard", Amount: big.NewInt(10), MsgIndex: -1},
+		{Direction: LedgerCredit, Account: "kava1to", Counterparty: "kava1from", Action: "end_block", Denom: "hard", Amount: big.NewInt(10), MsgIndex: -1},
+		{Direction: LedgerDebit, Account: notBondedPoolAddr, Counterparty: "kava1delegator", Action: "complete_unbonding", Denom: "ukava", Amount: big.NewInt(700), MsgIndex: -1},
+		{Direction: LedgerCredit, Account: "kava1delegator", Counterparty: notBondedPoolAddr, Action: "complete_unbonding", Denom: "ukava", Amount: big.NewInt(700), MsgIndex: -1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ProduceBlockLedger() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDecoderLedger(t *testing.T) {
+	dec, err := NewDecoder(DefaultCodec, zap.NewNop())
+	if err != nil {
+		t.Fatalf("error creating decoder: %v", err)
+	}
+	in := readCorpus(t, "bank_send")
+	tx, err := dec.Transaction(context.Background(), in)
+	if err != nil {
+		t.Fatalf("error converting transaction: %v", err)
+	}
+
+	// ledger is carried by subset events of the message, not as another event
+	if len(tx.Events) != 1 {
+		t.Fatalf("expected single message event, got %d", len(tx.Events))
+	}
+	var ledgerSubs int
+	for _, sub := range tx.Events[0].Sub {
+		if len(sub.Type) > 0 && sub.Type[0] == LedgerSubType {
+			ledgerSubs++
+		}
+	}
+
+	ledger, err := dec.Ledger(tx)
+	if err != nil {
+		t.Fatalf("error producing ledger: %v", err)
+	}
+	var transfers int
+	for _, e := range ledger {
+		if e.TxHash != tx.Hash {
+			t.Errorf("entry tx hash = %q, want %q", e.TxHash, tx.Hash)
+		}
+		if e.Action == "transfer" {
+			transfers++
+		}
+	}
+	if transfers == 0 || transfers%2 != 0 {
+		t.Errorf("expected debit and credit transfer entries, got %+v", ledger)
+	}
+	if ledgerSubs != len(ledger)/2 {
+		t.Errorf("expected %d ledger subset events, got %d", len(ledger)/2, ledgerSubs)
+	}
+
+	if ledger, err := dec.Ledger(structs.Transaction{Hash: tx.Hash}); err != nil || ledger != nil {
+		t.Errorf("transaction without raw: got %+v, %v", ledger, err)
+	}
+}
+
+func TestAttachLedger(t *testing.T) {
+	entries := append(append(
+		ledgerPair("payer", "collector", "fee", "ukava", big.NewInt(5), -1, "TX"),
+		ledgerPair("alice", "bob", "transfer", "hard", big.NewInt(10), 1, "TX")...),
+		ledgerPair("carol", "dave", "transfer", "ukava", big.NewInt(20), 7, "TX")...)
+
+	tx := structs.Transaction{Events: structs.TransactionEvents{{ID: "0"}, {ID: "1"}}}
+	attachLedger(&tx, entries)
+
+	summary := func(ev structs.TransactionEvent) (s []string) {
+		for _, sub := range ev.Sub {
+			if sub.Type[0] != LedgerSubType || sub.Sender[0].Amounts[0].Numeric.Cmp(sub.Recipient[0].Amounts[0].Numeric) != 0 {
+				t.Errorf("unexpected ledger event %+v", sub)
+			}
+			s = append(s, sub.Action+" "+sub.Sender[0].Account.ID+">"+sub.Recipient[0].Account.ID+" "+sub.Sender[0].Amounts[0].Text+sub.Sender[0].Amounts[0].Currency)
+		}
+		return s
+	}
+	// fees and entries of messages without event go to the first message
+	if got, want := summary(tx.Events[0]), []string{"fee payer>collector 5KAVA", "transfer carol>dave 20KAVA"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("first event ledger = %v, want %v", got, want)
+	}
+	if got, want := summary(tx.Events[1]), []string{"transfer alice>bob 10HARD"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("second event ledger = %v, want %v", got, want)
+	}
+
+	empty := structs.Transaction{}
+	attachLedger(&empty, entries)
+	if len(empty.Events) != 0 {
+		t.Errorf("events added to transaction without messages: %+v", empty.Events)
+	}
+}
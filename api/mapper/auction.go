@@ -7,6 +7,7 @@ import (
 
 	"github.com/figment-networks/indexing-engine/structs"
 	"github.com/figment-networks/kava-worker/api/types"
+	"github.com/figment-networks/kava-worker/api/util"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/kava-labs/kava/app"
@@ -32,11 +33,7 @@ func AuctionPlaceBidToSub(msg sdk.Msg, logf types.LogFormat) (se structs.SubsetE
 			"bidder": {{ID: bech32Addr}},
 		},
 		Amount: map[string]structs.TransactionAmount{
-			"bid": {
-				Currency: m.Amount.Denom,
-				Numeric:  m.Amount.Amount.BigInt(),
-				Text:     m.Amount.String(),
-			},
+			"bid": util.NewAmount(m.Amount.Denom, m.Amount.String(), m.Amount.Amount.BigInt(), 0),
 		},
 		Additional: map[string][]string{
 			"auction_id": []string{strconv.FormatUint(m.AuctionID, 10)},
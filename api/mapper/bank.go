@@ -6,6 +6,7 @@ import (
 
 	"github.com/figment-networks/indexing-engine/structs"
 	"github.com/figment-networks/kava-worker/api/types"
+	"github.com/figment-networks/kava-worker/api/util"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	bank "github.com/cosmos/cosmos-sdk/x/bank"
@@ -77,11 +78,7 @@ func bankProduceEvTx(account sdk.AccAddress, coins sdk.Coins) (evt structs.Event
 	if len(coins) > 0 {
 		evt.Amounts = []structs.TransactionAmount{}
 		for _, coin := range coins {
-			evt.Amounts = append(evt.Amounts, structs.TransactionAmount{
-				Currency: coin.Denom,
-				Numeric:  coin.Amount.BigInt(),
-				Text:     coin.Amount.String(),
-			})
+			evt.Amounts = append(evt.Amounts, util.NewAmount(coin.Denom, coin.Amount.String(), coin.Amount.BigInt(), 0))
 		}
 	}
 
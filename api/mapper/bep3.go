@@ -7,6 +7,7 @@ import (
 
 	"github.com/figment-networks/indexing-engine/structs"
 	"github.com/figment-networks/kava-worker/api/types"
+	"github.com/figment-networks/kava-worker/api/util"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/kava-labs/kava/app"
@@ -48,11 +49,7 @@ func Bep3CreateAtomicSwapToSub(msg sdk.Msg, logf types.LogFormat) (se structs.Su
 	txAmount := map[string]structs.TransactionAmount{}
 
 	for i, coin := range m.Amount {
-		am := structs.TransactionAmount{
-			Currency: coin.Denom,
-			Numeric:  coin.Amount.BigInt(),
-			Text:     coin.Amount.String(),
-		}
+		am := util.NewAmount(coin.Denom, coin.Amount.String(), coin.Amount.BigInt(), 0)
 
 		key := "send"
 		if i > 0 {
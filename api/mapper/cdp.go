@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/figment-networks/kava-worker/api/util"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/kava-labs/kava/app"
@@ -30,16 +31,8 @@ func CDPCreateCDPToSub(msg sdk.Msg) (se structs.SubsetEvent, err error) {
 			"sender": {{ID: bech32Addr}},
 		},
 		Amount: map[string]structs.TransactionAmount{
-			"collateral": {
-				Currency: m.Collateral.Denom,
-				Numeric:  m.Collateral.Amount.BigInt(),
-				Text:     m.Collateral.String(),
-			},
-			"principal": {
-				Currency: m.Principal.Denom,
-				Numeric:  m.Principal.Amount.BigInt(),
-				Text:     m.Principal.String(),
-			},
+			"collateral": util.NewAmount(m.Collateral.Denom, m.Collateral.String(), m.Collateral.Amount.BigInt(), 0),
+			"principal":  util.NewAmount(m.Principal.Denom, m.Principal.String(), m.Principal.Amount.BigInt(), 0),
 		},
 		Additional: map[string][]string{
 			"collateral_type": []string{m.CollateralType},
@@ -71,11 +64,7 @@ func CDPDepositCDPToSub(msg sdk.Msg) (se structs.SubsetEvent, err error) {
 			"owner":     {{ID: bech32OwnerAddr}},
 		},
 		Amount: map[string]structs.TransactionAmount{
-			"collateral": {
-				Currency: m.Collateral.Denom,
-				Numeric:  m.Collateral.Amount.BigInt(),
-				Text:     m.Collateral.String(),
-			},
+			"collateral": util.NewAmount(m.Collateral.Denom, m.Collateral.String(), m.Collateral.Amount.BigInt(), 0),
 		},
 		Additional: map[string][]string{
 			"collateral_type": []string{m.CollateralType},
@@ -107,11 +96,7 @@ func CDPWithdrawCDPToSub(msg sdk.Msg) (se structs.SubsetEvent, err error) {
 			"owner":     {{ID: bech32OwnerAddr}},
 		},
 		Amount: map[string]structs.TransactionAmount{
-			"collateral": {
-				Currency: m.Collateral.Denom,
-				Numeric:  m.Collateral.Amount.BigInt(),
-				Text:     m.Collateral.String(),
-			},
+			"collateral": util.NewAmount(m.Collateral.Denom, m.Collateral.String(), m.Collateral.Amount.BigInt(), 0),
 		},
 		Additional: map[string][]string{
 			"collateral_type": []string{m.CollateralType},
@@ -137,11 +122,7 @@ func CDPDrawCDPToSub(msg sdk.Msg) (se structs.SubsetEvent, err error) {
 			"sender": {{ID: bech32Addr}},
 		},
 		Amount: map[string]structs.TransactionAmount{
-			"principal": {
-				Currency: m.Principal.Denom,
-				Numeric:  m.Principal.Amount.BigInt(),
-				Text:     m.Principal.String(),
-			},
+			"principal": util.NewAmount(m.Principal.Denom, m.Principal.String(), m.Principal.Amount.BigInt(), 0),
 		},
 		Additional: map[string][]string{
 			"collateral_type": []string{m.CollateralType},
@@ -167,11 +148,7 @@ func CDPRepayCDPToSub(msg sdk.Msg) (se structs.SubsetEvent, err error) {
 			"sender": {{ID: bech32Addr}},
 		},
 		Amount: map[string]structs.TransactionAmount{
-			"payment": {
-				Currency: m.Payment.Denom,
-				Numeric:  m.Payment.Amount.BigInt(),
-				Text:     m.Payment.String(),
-			},
+			"payment": util.NewAmount(m.Payment.Denom, m.Payment.String(), m.Payment.Amount.BigInt(), 0),
 		},
 		Additional: map[string][]string{
 			"collateral_type": []string{m.CollateralType},
@@ -2,8 +2,6 @@ package mapper
 
 import (
 	"fmt"
-	"math/big"
-	"strings"
 
 	"github.com/figment-networks/indexing-engine/structs"
 	"github.com/figment-networks/kava-worker/api/types"
@@ -33,36 +31,15 @@ func produceTransfers(se *structs.SubsetEvent, transferType, skipAddr string, lo
 				continue
 			}
 
-			amts := []structs.TransactionAmount{}
-
 			if len(attr.Amount) == 0 {
 				continue
 			}
 
-			for _, amount := range strings.Split(attr.Amount[0], ",") {
-				attrAmt := structs.TransactionAmount{Numeric: &big.Int{}}
-				sliced := util.GetCurrency(amount)
-				var (
-					c       *big.Int
-					exp     int32
-					coinErr error
-				)
-				if len(sliced) == 3 {
-					attrAmt.Currency = sliced[2]
-					c, exp, coinErr = util.GetCoin(sliced[1])
-				} else {
-					c, exp, coinErr = util.GetCoin(amount)
-				}
-				if coinErr != nil {
-					return fmt.Errorf("[KAVA-API] Error parsing amount '%s': %s ", amount, coinErr)
-				}
-
-				attrAmt.Text = amount
-				attrAmt.Exp = exp
-				attrAmt.Numeric.Set(c)
-
-				amts = append(amts, attrAmt)
+			amts, err := util.ParseAmounts(attr.Amount[0])
+			if err != nil {
+				return fmt.Errorf("[KAVA-API] Error parsing amount '%s': %w ", attr.Amount[0], err)
 			}
+
 			evts = append(evts, structs.EventTransfer{
 				Amounts: amts,
 				Account: structs.Account{ID: latestRecipient},
@@ -7,6 +7,7 @@ import (
 
 	"github.com/figment-networks/indexing-engine/structs"
 	"github.com/figment-networks/kava-worker/api/types"
+	"github.com/figment-networks/kava-worker/api/util"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	distribution "github.com/cosmos/cosmos-sdk/x/distribution"
@@ -128,13 +129,7 @@ func distributionProduceEvTx(account sdk.AccAddress, coins sdk.Coins) (evt struc
 	if len(coins) > 0 {
 		evt.Amounts = []structs.TransactionAmount{}
 		for _, coin := range coins {
-			txa := structs.TransactionAmount{
-				Currency: coin.Denom,
-				Text:     coin.Amount.String(),
-			}
-
-			txa.Numeric.Set(coin.Amount.BigInt())
-			evt.Amounts = append(evt.Amounts, txa)
+			evt.Amounts = append(evt.Amounts, util.NewAmount(coin.Denom, coin.Amount.String(), coin.Amount.BigInt(), 0))
 		}
 	}
 
@@ -7,6 +7,7 @@ import (
 
 	"github.com/figment-networks/indexing-engine/structs"
 	"github.com/figment-networks/kava-worker/api/types"
+	"github.com/figment-networks/kava-worker/api/util"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	gov "github.com/cosmos/cosmos-sdk/x/gov"
@@ -36,11 +37,7 @@ func GovDepositToSub(msg sdk.Msg, logf types.LogFormat) (se structs.SubsetEvent,
 	txAmount := map[string]structs.TransactionAmount{}
 
 	for i, coin := range dep.Amount {
-		am := structs.TransactionAmount{
-			Currency: coin.Denom,
-			Numeric:  coin.Amount.BigInt(),
-			Text:     coin.Amount.String(),
-		}
+		am := util.NewAmount(coin.Denom, coin.Amount.String(), coin.Amount.BigInt(), 0)
 
 		sender.Amounts = append(sender.Amounts, am)
 		key := "deposit"
@@ -101,11 +98,7 @@ func GovSubmitProposalToSub(msg sdk.Msg, logf types.LogFormat) (se structs.Subse
 	txAmount := map[string]structs.TransactionAmount{}
 
 	for i, coin := range sp.InitialDeposit {
-		am := structs.TransactionAmount{
-			Currency: coin.Denom,
-			Numeric:  coin.Amount.BigInt(),
-			Text:     coin.Amount.String(),
-		}
+		am := util.NewAmount(coin.Denom, coin.Amount.String(), coin.Amount.BigInt(), 0)
 
 		sender.Amounts = append(sender.Amounts, am)
 		key := "initial_deposit"
@@ -7,6 +7,7 @@ import (
 
 	"github.com/figment-networks/indexing-engine/structs"
 	"github.com/figment-networks/kava-worker/api/types"
+	"github.com/figment-networks/kava-worker/api/util"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/kava-labs/kava/app"
@@ -150,11 +151,7 @@ func hardProduceAmounts(coins sdk.Coins) map[string]structs.TransactionAmount {
 	if len(coins) > 0 {
 		txAm := make(map[string]structs.TransactionAmount)
 		for i, coin := range coins {
-			txAm[strconv.Itoa(i)] = structs.TransactionAmount{
-				Currency: coin.Denom,
-				Numeric:  coin.Amount.BigInt(),
-				Text:     coin.Amount.String(),
-			}
+			txAm[strconv.Itoa(i)] = util.NewAmount(coin.Denom, coin.Amount.String(), coin.Amount.BigInt(), 0)
 		}
 		return txAm
 	}
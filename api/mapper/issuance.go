@@ -6,6 +6,7 @@ import (
 	"strconv"
 
 	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/figment-networks/kava-worker/api/util"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/kava-labs/kava/app"
@@ -37,11 +38,7 @@ func IssuanceIssueTokensToSub(msg sdk.Msg) (se structs.SubsetEvent, err error) {
 			"receiver": {{ID: bech32RecAddr}},
 		},
 		Amount: map[string]structs.TransactionAmount{
-			"send": {
-				Currency: m.Tokens.Denom,
-				Numeric:  m.Tokens.Amount.BigInt(),
-				Text:     m.Tokens.String(),
-			},
+			"send": util.NewAmount(m.Tokens.Denom, m.Tokens.String(), m.Tokens.Amount.BigInt(), 0),
 		},
 	}, nil
 }
@@ -64,11 +61,7 @@ func IssuanceRedeemTokensToSub(msg sdk.Msg) (se structs.SubsetEvent, err error)
 			"sender": {{ID: bech32SenderAddr}},
 		},
 		Amount: map[string]structs.TransactionAmount{
-			"send": {
-				Currency: m.Tokens.Denom,
-				Numeric:  m.Tokens.Amount.BigInt(),
-				Text:     m.Tokens.String(),
-			},
+			"send": util.NewAmount(m.Tokens.Denom, m.Tokens.String(), m.Tokens.Amount.BigInt(), 0),
 		},
 	}, nil
 }
@@ -103,7 +96,7 @@ func IssuanceBlockAddressToSub(msg sdk.Msg) (se structs.SubsetEvent, err error)
 }
 
 func IssuanceUnblockAddressToSub(msg sdk.Msg) (se structs.SubsetEvent, err error) {
-	m, ok := msg.(issuance.MsgBlockAddress)
+	m, ok := msg.(issuance.MsgUnblockAddress)
 	if !ok {
 		return se, errors.New("Not a unblock_address type")
 	}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/figment-networks/indexing-engine/structs"
 	"github.com/figment-networks/kava-worker/api/types"
+	"github.com/figment-networks/kava-worker/api/util"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	staking "github.com/cosmos/cosmos-sdk/x/staking"
@@ -39,11 +40,7 @@ func StakingUndelegateToSub(msg sdk.Msg, logf types.LogFormat) (se structs.Subse
 			"validator": {{ID: bech32ValAddr}},
 		},
 		Amount: map[string]structs.TransactionAmount{
-			"undelegate": {
-				Currency: u.Amount.Denom,
-				Numeric:  u.Amount.Amount.BigInt(),
-				Text:     u.Amount.String(),
-			},
+			"undelegate": util.NewAmount(u.Amount.Denom, u.Amount.String(), u.Amount.Amount.BigInt(), 0),
 		},
 	}
 
@@ -75,11 +72,7 @@ func StakingDelegateToSub(msg sdk.Msg, logf types.LogFormat) (se structs.SubsetE
 			"validator": {{ID: bech32ValAddr}},
 		},
 		Amount: map[string]structs.TransactionAmount{
-			"delegate": {
-				Currency: d.Amount.Denom,
-				Numeric:  d.Amount.Amount.BigInt(),
-				Text:     d.Amount.String(),
-			},
+			"delegate": util.NewAmount(d.Amount.Denom, d.Amount.String(), d.Amount.Amount.BigInt(), 0),
 		},
 	}
 
@@ -117,11 +110,7 @@ func StakingBeginRedelegateToSub(msg sdk.Msg, logf types.LogFormat) (se structs.
 			"validator_source":      {{ID: bech32ValSrcAddr}},
 		},
 		Amount: map[string]structs.TransactionAmount{
-			"delegate": {
-				Currency: br.Amount.Denom,
-				Numeric:  br.Amount.Amount.BigInt(),
-				Text:     br.Amount.String(),
-			},
+			"delegate": util.NewAmount(br.Amount.Denom, br.Amount.String(), br.Amount.Amount.BigInt(), 0),
 		},
 	}
 
@@ -162,11 +151,7 @@ func StakingCreateValidatorToSub(msg sdk.Msg) (se structs.SubsetEvent, err error
 			},
 		},
 		Amount: map[string]structs.TransactionAmount{
-			"self_delegation": {
-				Currency: ev.Value.Denom,
-				Numeric:  ev.Value.Amount.BigInt(),
-				Text:     ev.Value.String(),
-			},
+			"self_delegation": util.NewAmount(ev.Value.Denom, ev.Value.String(), ev.Value.Amount.BigInt(), 0),
 			"self_delegation_min": {
 				Text:    ev.MinSelfDelegation.String(),
 				Numeric: ev.MinSelfDelegation.BigInt(),
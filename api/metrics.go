@@ -59,6 +59,22 @@ var (
 		Tags:      []string{"type"},
 	})
 
+	cacheRequests = metrics.MustNewCounterWithTags(metrics.Options{
+		Namespace: "indexerworker",
+		Subsystem: "api",
+		Name:      "cache_requests",
+		Desc:      "Lookups in caches of blocks and raw responses",
+		Tags:      []string{"cache", "result"},
+	})
+
+	decodeRequests = metrics.MustNewCounterWithTags(metrics.Options{
+		Namespace: "indexerworker",
+		Subsystem: "api",
+		Name:      "decode_requests",
+		Desc:      "Transactions decoded over HTTP",
+		Tags:      []string{"result"},
+	})
+
 	numberOfItemsTransactions     *metrics.GroupCounter
 	numberOfItemsInBlock          *metrics.GroupCounter
 	transactionConversionDuration *metrics.GroupObserver
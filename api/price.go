@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/rest"
+)
+
+// ErrPriceNotFound is returned when pricefeed has no price of the market at given height
+var ErrPriceNotFound = errors.New("price not found")
+
+// priceResponse is kava response for querying /pricefeed/price
+type priceResponse struct {
+	Height string      `json:"height"`
+	Result priceResult `json:"result"`
+}
+
+type priceResult struct {
+	MarketID string  `json:"market_id"`
+	Price    sdk.Dec `json:"price"`
+}
+
+// GetPrice fetches current pricefeed price of the market at given height
+func (c *Client) GetPrice(ctx context.Context, market string, height uint64) (price sdk.Dec, err error) {
+	endpoint := fmt.Sprintf("/pricefeed/price/%s", market)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+endpoint, nil)
+	if err != nil {
+		return price, err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	if c.key != "" {
+		req.Header.Add("Authorization", c.key)
+	}
+
+	q := req.URL.Query()
+	if height > 0 {
+		q.Add("height", strconv.FormatUint(height, 10))
+	}
+	req.URL.RawQuery = q.Encode()
+
+	err = c.rateLimiter.Wait(ctx)
+	if err != nil {
+		return price, err
+	}
+
+	var cliResp *http.Response
+
+	for i := 1; i <= maxRetries; i++ {
+		n := time.Now()
+		cliResp, err = c.httpClient.Do(req)
+		if err, ok := err.(net.Error); ok && err.Timeout() && i != maxRetries {
+			continue
+		} else if err != nil {
+			return price, err
+		}
+		rawRequestHTTPDuration.WithLabels("/pricefeed/price/_", cliResp.Status).Observe(time.Since(n).Seconds())
+
+		defer cliResp.Body.Close()
+
+		if cliResp.StatusCode < 500 {
+			break
+		}
+		time.Sleep(time.Duration(i*500) * time.Millisecond)
+	}
+
+	decoder := json.NewDecoder(cliResp.Body)
+
+	if cliResp.StatusCode > 399 {
+		var result rest.ErrorResponse
+		if err = decoder.Decode(&result); err != nil {
+			result.Error = strconv.Itoa(cliResp.StatusCode)
+		}
+		// unknown market or no price at the height
+		if cliResp.StatusCode == http.StatusBadRequest || cliResp.StatusCode == http.StatusNotFound {
+			return price, fmt.Errorf("[KAVA-API] Error fetching price: %w: %s", ErrPriceNotFound, result.Error)
+		}
+		return price, fmt.Errorf("[KAVA-API] Error fetching price: %s ", result.Error)
+	}
+
+	var result priceResponse
+	if err = decoder.Decode(&result); err != nil {
+		return price, err
+	}
+
+	if result.Result.Price.IsNil() {
+		return price, fmt.Errorf("[KAVA-API] Error fetching price: %w: empty price for %s", ErrPriceNotFound, market)
+	}
+
+	return result.Result.Price, nil
+}
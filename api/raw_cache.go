@@ -0,0 +1,94 @@
+package api
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Kinds of raw responses kept in RawCache
+const (
+	RawBlock    = "block"
+	RawTxSearch = "tx_search"
+)
+
+// RawCache keeps raw node responses of past heights, so they don't have to be fetched again.
+// Pages of /tx_search are expected to be requested with the same page size.
+type RawCache interface {
+	// Get returns response of the kind for given height and page (0 for responses without pages)
+	Get(kind string, height, page uint64) (data []byte, ok bool)
+	Put(kind string, height, page uint64, data []byte) error
+	// Delete removes all responses of the height
+	Delete(height uint64) error
+}
+
+// DiskCache is a RawCache keeping responses in files of `<dir>/<chain id>/<kind>/<height>-<page>.json`
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache is DiskCache constructor
+func NewDiskCache(dir, chainID string) (*DiskCache, error) {
+	if chainID == "" {
+		return nil, fmt.Errorf("chain id is required for disk cache")
+	}
+	dc := &DiskCache{dir: filepath.Join(dir, chainID)}
+	for _, kind := range []string{RawBlock, RawTxSearch} {
+		if err := os.MkdirAll(filepath.Join(dc.dir, kind), 0755); err != nil {
+			return nil, fmt.Errorf("error creating cache directory: %w", err)
+		}
+	}
+	return dc, nil
+}
+
+// Get reads response from file
+func (dc *DiskCache) Get(kind string, height, page uint64) (data []byte, ok bool) {
+	data, err := ioutil.ReadFile(dc.path(kind, height, page))
+	if err != nil {
+		cacheRequests.WithLabels(kind, "miss").Inc()
+		return nil, false
+	}
+	cacheRequests.WithLabels(kind, "hit").Inc()
+	return data, true
+}
+
+// Put writes response into file, replacing it atomically
+func (dc *DiskCache) Put(kind string, height, page uint64, data []byte) error {
+	path := dc.path(kind, height, page)
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if cErr := tmp.Close(); err == nil {
+		err = cErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Delete removes files of the height
+func (dc *DiskCache) Delete(height uint64) error {
+	for _, kind := range []string{RawBlock, RawTxSearch} {
+		files, err := filepath.Glob(filepath.Join(dc.dir, kind, strconv.FormatUint(height, 10)+"-*.json"))
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (dc *DiskCache) path(kind string, height, page uint64) string {
+	return filepath.Join(dc.dir, kind, strconv.FormatUint(height, 10)+"-"+strconv.FormatUint(page, 10)+".json")
+}
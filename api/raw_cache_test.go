@@ -0,0 +1,90 @@
+package api
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewDiskCache(t *testing.T) {
+	if _, err := NewDiskCache(t.TempDir(), ""); err == nil {
+		t.Error("expected error without chain id")
+	}
+
+	dir := t.TempDir()
+	if _, err := NewDiskCache(dir, "kava-4"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, kind := range []string{RawBlock, RawTxSearch} {
+		if fi, err := os.Stat(filepath.Join(dir, "kava-4", kind)); err != nil || !fi.IsDir() {
+			t.Errorf("expected %s directory to be created: %v", kind, err)
+		}
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	dir := t.TempDir()
+	dc, err := NewDiskCache(dir, "kava-4")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := dc.Get(RawBlock, 10, 0); ok {
+		t.Fatal("expected miss on empty cache")
+	}
+
+	puts := []struct {
+		kind   string
+		height uint64
+		page   uint64
+		data   string
+	}{
+		{RawBlock, 10, 0, `{"block":10}`},
+		{RawTxSearch, 10, 1, `{"page":1}`},
+		{RawTxSearch, 10, 2, `{"page":2}`},
+		{RawBlock, 11, 0, `{"block":11}`},
+	}
+	for _, p := range puts {
+		if err := dc.Put(p.kind, p.height, p.page, []byte(p.data)); err != nil {
+			t.Fatalf("unexpected error putting %s %d-%d: %v", p.kind, p.height, p.page, err)
+		}
+	}
+	for _, p := range puts {
+		data, ok := dc.Get(p.kind, p.height, p.page)
+		if !ok || string(data) != p.data {
+			t.Errorf("%s %d-%d: expected %s, got %s (ok %t)", p.kind, p.height, p.page, p.data, data, ok)
+		}
+	}
+	if _, ok := dc.Get(RawTxSearch, 10, 3); ok {
+		t.Error("expected miss for page not put")
+	}
+
+	// replacing leaves no temporary files behind
+	if err := dc.Put(RawBlock, 10, 0, []byte(`{"block":"new"}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data, _ := dc.Get(RawBlock, 10, 0); string(data) != `{"block":"new"}` {
+		t.Errorf("expected replaced response, got %s", data)
+	}
+	files, err := ioutil.ReadDir(filepath.Join(dir, "kava-4", RawBlock))
+	if err != nil {
+		t.Fatalf("error reading dir: %v", err)
+	}
+	if len(files) != 2 {
+		t.Errorf("expected 2 files, got %d", len(files))
+	}
+
+	if err := dc.Delete(10); err != nil {
+		t.Fatalf("unexpected error deleting: %v", err)
+	}
+	for _, p := range puts {
+		_, ok := dc.Get(p.kind, p.height, p.page)
+		if want := p.height != 10; ok != want {
+			t.Errorf("%s %d-%d: expected cached %t after delete, got %t", p.kind, p.height, p.page, want, ok)
+		}
+	}
+	if err := dc.Delete(12); err != nil {
+		t.Errorf("unexpected error deleting missing height: %v", err)
+	}
+}
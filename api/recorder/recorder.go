@@ -0,0 +1,173 @@
+// Package recorder records HTTP responses of the node into fixture files and replays them,
+// so the client can be tested without network.
+package recorder
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrNoFixture is returned in replay mode for requests without recorded response
+var ErrNoFixture = errors.New("no fixture recorded for request")
+
+// Mode of the Transport
+type Mode int
+
+const (
+	// ModeReplay serves responses from fixtures only
+	ModeReplay Mode = iota
+	// ModeRecord passes requests to the node, writing responses into fixtures
+	ModeRecord
+)
+
+// Fixture is a recorded response
+type Fixture struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Query  string          `json:"query,omitempty"`
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body,omitempty"`
+	// RawBody is set instead of Body for responses that are not JSON
+	RawBody string `json:"raw_body,omitempty"`
+}
+
+func (f Fixture) body() []byte {
+	if f.Body != nil {
+		return f.Body
+	}
+	return []byte(f.RawBody)
+}
+
+// Transport is http.RoundTripper recording or replaying responses,
+// to be used in http.Client given to api.NewClient
+type Transport struct {
+	dir  string
+	mode Mode
+	next http.RoundTripper
+}
+
+// New is Transport constructor, next is used in record mode (http.DefaultTransport when nil)
+func New(dir string, mode Mode, next http.RoundTripper) (*Transport, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if mode == ModeRecord {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("error creating fixtures directory: %w", err)
+		}
+	}
+	return &Transport{dir: dir, mode: mode, next: next}, nil
+}
+
+// RoundTrip serves the request from fixture or records the response of next round tripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.mode == ModeReplay {
+		f, err := Load(t.dir, req)
+		if err != nil {
+			return nil, err
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+			StatusCode:    f.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": []string{"application/json"}},
+			Body:          ioutil.NopCloser(bytes.NewReader(f.body())),
+			ContentLength: int64(len(f.body())),
+			Request:       req,
+		}, nil
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	f := Fixture{Method: req.Method, Path: req.URL.Path, Query: canonicalQuery(req), Status: resp.StatusCode}
+	if json.Valid(body) {
+		f.Body = body
+	} else {
+		f.RawBody = string(body)
+	}
+	if err := Save(t.dir, f); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Load reads fixture of the request
+func Load(dir string, req *http.Request) (f Fixture, err error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, fileName(req.Method, req.URL.Path, canonicalQuery(req))))
+	if os.IsNotExist(err) {
+		return f, fmt.Errorf("%w: %s %s?%s", ErrNoFixture, req.Method, req.URL.Path, canonicalQuery(req))
+	}
+	if err != nil {
+		return f, err
+	}
+	return f, json.Unmarshal(data, &f)
+}
+
+// Save writes fixture into directory
+func Save(dir string, f Fixture) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, fileName(f.Method, f.Path, f.Query)), data, 0644)
+}
+
+// canonicalQuery encodes query with sorted keys, so the order of parameters doesn't matter
+func canonicalQuery(req *http.Request) string {
+	return req.URL.Query().Encode()
+}
+
+// fileName names fixture after the endpoint, with the hash of query making it unique
+func fileName(method, path, query string) string {
+	name := strings.Trim(strings.ReplaceAll(path, "/", "_"), "_")
+	if name == "" {
+		name = "root"
+	}
+	if query == "" {
+		return fmt.Sprintf("%s_%s.json", strings.ToLower(method), name)
+	}
+	sum := sha1.Sum([]byte(query))
+	return fmt.Sprintf("%s_%s_%s.json", strings.ToLower(method), name, hex.EncodeToString(sum[:6]))
+}
+
+// List returns fixtures of the directory, ordered by file name
+func List(dir string) (fixtures []Fixture, err error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		f := Fixture{}
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("error decoding fixture %s: %w", file, err)
+		}
+		fixtures = append(fixtures, f)
+	}
+	return fixtures, nil
+}
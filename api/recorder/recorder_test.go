@@ -0,0 +1,142 @@
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func get(t *testing.T, c *http.Client, url string) (int, string, error) {
+	t.Helper()
+	resp, err := c.Get(url)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("error reading body: %v", err)
+	}
+	return resp.StatusCode, string(body), nil
+}
+
+// normalize compacts JSON bodies, as fixtures are stored indented
+func normalize(body string) string {
+	b := &bytes.Buffer{}
+	if err := json.Compact(b, []byte(body)); err != nil {
+		return body
+	}
+	return b.String()
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/block":
+			fmt.Fprintf(w, `{"result":{"height":%q}}`, r.URL.Query().Get("height"))
+		case "/health":
+			fmt.Fprint(w, "ok")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":"not found"}`)
+		}
+	}))
+
+	rec, err := New(dir, ModeRecord, nil)
+	if err != nil {
+		t.Fatalf("error creating recorder: %v", err)
+	}
+	recorded := map[string]string{}
+	for _, path := range []string{"/block?height=10", "/block?height=11", "/health", "/missing"} {
+		_, body, err := get(t, &http.Client{Transport: rec}, node.URL+path)
+		if err != nil {
+			t.Fatalf("error recording %s: %v", path, err)
+		}
+		recorded[path] = body
+	}
+	node.Close()
+
+	fixtures, err := List(dir)
+	if err != nil {
+		t.Fatalf("error listing fixtures: %v", err)
+	}
+	if len(fixtures) != 4 {
+		t.Fatalf("expected 4 fixtures, got %d", len(fixtures))
+	}
+
+	replay, err := New(dir, ModeReplay, nil)
+	if err != nil {
+		t.Fatalf("error creating replay: %v", err)
+	}
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{path: "/block?height=10", status: http.StatusOK},
+		{path: "/block?height=11", status: http.StatusOK},
+		{path: "/health", status: http.StatusOK},
+		{path: "/missing", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			status, body, err := get(t, &http.Client{Transport: replay}, node.URL+tt.path)
+			if err != nil {
+				t.Fatalf("error replaying: %v", err)
+			}
+			if status != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, status)
+			}
+			if normalize(body) != recorded[tt.path] {
+				t.Errorf("expected body %q, got %q", recorded[tt.path], body)
+			}
+		})
+	}
+
+	if _, _, err := get(t, &http.Client{Transport: replay}, node.URL+"/block?height=12"); !errors.Is(err, ErrNoFixture) {
+		t.Errorf("expected ErrNoFixture for unrecorded request, got %v", err)
+	}
+}
+
+func TestQueryOrderDoesNotMatter(t *testing.T) {
+	dir := t.TempDir()
+	if err := Save(dir, Fixture{Method: http.MethodGet, Path: "/tx_search", Query: "page=1&per_page=100", Status: http.StatusOK, Body: []byte(`{"result":{}}`)}); err != nil {
+		t.Fatalf("error saving fixture: %v", err)
+	}
+
+	srv := NewServer(dir)
+	defer srv.Close()
+
+	status, body, err := get(t, srv.Client(), srv.URL+"/tx_search?per_page=100&page=1")
+	if err != nil {
+		t.Fatalf("error getting fixture: %v", err)
+	}
+	if status != http.StatusOK || normalize(body) != `{"result":{}}` {
+		t.Errorf("unexpected response %d %s", status, body)
+	}
+	if n := srv.Requests("/tx_search"); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+}
+
+func TestServerMissingFixture(t *testing.T) {
+	srv := NewServer(t.TempDir())
+	defer srv.Close()
+
+	status, body, err := get(t, srv.Client(), srv.URL+"/block?height=1")
+	if err != nil {
+		t.Fatalf("error getting response: %v", err)
+	}
+	if status != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, status)
+	}
+	if body == "" {
+		t.Error("expected JSON-RPC error body")
+	}
+}
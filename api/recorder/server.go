@@ -0,0 +1,72 @@
+package recorder
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+// Server is a fake Tendermint RPC (and LCD) server serving recorded fixtures
+type Server struct {
+	*httptest.Server
+
+	dir string
+
+	requests map[string]int
+	l        sync.Mutex
+}
+
+// NewServer starts server serving fixtures of the directory, it has to be closed after use
+func NewServer(dir string) *Server {
+	s := &Server{dir: dir, requests: make(map[string]int)}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// ServeHTTP responds with the fixture of the request, or with JSON-RPC error when there is none
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.l.Lock()
+	s.requests[r.URL.Path]++
+	s.l.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	f, err := Load(s.dir, r)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrNoFixture) {
+			status = http.StatusNotFound
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(rpcError{
+			JSONRPC: "2.0",
+			ID:      -1,
+			Error:   rpcErrorBody{Code: -32603, Message: "Internal error", Data: err.Error()},
+		})
+		return
+	}
+
+	w.WriteHeader(f.Status)
+	w.Write(f.body())
+}
+
+// Requests returns the number of requests received for the path
+func (s *Server) Requests(path string) int {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	return s.requests[path]
+}
+
+type rpcError struct {
+	JSONRPC string       `json:"jsonrpc"`
+	ID      int          `json:"id"`
+	Error   rpcErrorBody `json:"error"`
+}
+
+type rpcErrorBody struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data"`
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/figment-networks/kava-worker/api/util"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/rest"
@@ -97,14 +98,8 @@ func (c *Client) GetReward(ctx context.Context, params structs.HeightAccount) (r
 		valRewards := make([]structs.RewardAmount, 0, len(valReward.Rewards))
 
 		for _, reward := range valReward.Rewards {
-			valRewards = append(valRewards,
-				structs.RewardAmount{
-					Text:     reward.Amount.String(),
-					Numeric:  reward.Amount.BigInt(),
-					Currency: reward.Denom,
-					Exp:      sdk.Precision,
-				},
-			)
+			am := util.NewAmount(reward.Denom, reward.Amount.String(), reward.Amount.BigInt(), sdk.Precision)
+			valRewards = append(valRewards, structs.RewardAmount(am))
 		}
 		resp.Rewards[structs.Validator(valReward.Validator)] = valRewards
 	}
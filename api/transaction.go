@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"math/big"
 	"net/http"
 	"strconv"
 	"strings"
@@ -21,6 +20,7 @@ import (
 	"github.com/figment-networks/kava-worker/api/util"
 
 	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/auth"
 	log "github.com/sirupsen/logrus"
 	"go.uber.org/zap"
@@ -44,53 +44,21 @@ func (c *Client) SearchTx(ctx context.Context, r structs.HeightHash, block struc
 	numberOfItemsInBlock.Add(float64(block.NumberOfTransactions))
 	page := uint64(1)
 	for {
-		now := time.Now()
-		if err := c.rateLimiter.Wait(ctx); err != nil {
-			return txs, err
-		}
-
-		sCtx, cancel := context.WithTimeout(ctx, time.Second*10)
-		defer cancel()
-		req, err := http.NewRequestWithContext(sCtx, http.MethodGet, c.baseURL+"/tx_search", nil)
-		if err != nil {
-			return txs, err
-		}
-
-		req.Header.Add("Content-Type", "application/json")
-		if c.key != "" {
-			req.Header.Add("Authorization", c.key)
-		}
-
-		q := req.URL.Query()
-		s := strings.Builder{}
-		s.WriteString(`"`)
-		s.WriteString("tx.height=")
-		s.WriteString(strconv.FormatUint(r.Height, 10))
-		s.WriteString(`"`)
-
-		q.Add("query", s.String())
-		q.Add("page", strconv.FormatUint(page, 10))
-		q.Add("per_page", strconv.FormatUint(perPage, 10))
-		req.URL.RawQuery = q.Encode()
-
-		resp, err := c.httpClient.Do(req)
-		log.Debug("[COSMOS-API] Request Time (/tx_search)", zap.Duration("duration", time.Now().Sub(now)))
-		if err != nil {
-			return txs, err
+		var (
+			data   []byte
+			cached bool
+		)
+		if c.RawCache != nil {
+			data, cached = c.RawCache.Get(RawTxSearch, r.Height, page)
 		}
-
-		if resp.StatusCode > 399 { // ERROR
-			serverError, _ := ioutil.ReadAll(resp.Body)
-			c.logger.Error("[COSMOS-API] error getting response from server", zap.Int("code", resp.StatusCode), zap.Any("response", string(serverError)))
-			return txs, fmt.Errorf("error getting response from server %d %s", resp.StatusCode, string(serverError))
+		if !cached {
+			if data, err = c.fetchTxSearch(ctx, r.Height, page, perPage); err != nil {
+				return txs, err
+			}
 		}
 
-		rawRequestHTTPDuration.WithLabels("/tx_search", resp.Status).Observe(time.Since(now).Seconds())
-
-		decoder := json.NewDecoder(resp.Body)
-
 		result := &types.GetTxSearchResponse{}
-		if err = decoder.Decode(result); err != nil {
+		if err = json.Unmarshal(data, result); err != nil {
 			c.logger.Error("[COSMOS-API] unable to decode result body", zap.Error(err))
 			return txs, fmt.Errorf("unable to decode result body %w", err)
 		}
@@ -102,10 +70,17 @@ func (c *Client) SearchTx(ctx context.Context, r structs.HeightHash, block struc
 
 		totalCount, err := strconv.ParseInt(result.Result.TotalCount, 10, 64)
 		if err != nil {
-			c.logger.Error("[COSMOS-API] Error getting totalCount", zap.Error(err), zap.Any("result", result), zap.String("query", req.URL.RawQuery), zap.Any("request", r))
+			c.logger.Error("[COSMOS-API] Error getting totalCount", zap.Error(err), zap.Any("result", result), zap.Uint64("page", page), zap.Any("request", r))
 			return txs, err
 		}
 
+		// pages are persisted only when all transactions of the block are indexed by the node
+		if c.RawCache != nil && !cached && uint64(totalCount) == block.NumberOfTransactions {
+			if err := c.RawCache.Put(RawTxSearch, r.Height, page, data); err != nil {
+				c.logger.Warn("[COSMOS-API] Error caching transactions", zap.Uint64("height", r.Height), zap.Error(err))
+			}
+		}
+
 		numberOfItemsInBlock.Add(float64(totalCount))
 		c.logger.Debug("[COSMOS-API] Converting requests ", zap.Int("number", len(result.Result.Txs)))
 
@@ -118,6 +93,9 @@ func (c *Client) SearchTx(ctx context.Context, r structs.HeightHash, block struc
 			tx.BlockHash = block.Hash
 			tx.ChainID = block.ChainID
 			tx.Time = block.Time
+			if c.Valuator != nil {
+				c.Valuator.Valuate(ctx, &tx)
+			}
 			txs = append(txs, tx)
 		}
 
@@ -131,6 +109,54 @@ func (c *Client) SearchTx(ctx context.Context, r structs.HeightHash, block struc
 	return txs, nil
 }
 
+// fetchTxSearch gets raw /tx_search response with the page of transactions of the height
+func (c *Client) fetchTxSearch(ctx context.Context, height, page, perPage uint64) (data []byte, err error) {
+	now := time.Now()
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	sCtx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+	req, err := http.NewRequestWithContext(sCtx, http.MethodGet, c.baseURL+"/tx_search", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	if c.key != "" {
+		req.Header.Add("Authorization", c.key)
+	}
+
+	q := req.URL.Query()
+	s := strings.Builder{}
+	s.WriteString(`"`)
+	s.WriteString("tx.height=")
+	s.WriteString(strconv.FormatUint(height, 10))
+	s.WriteString(`"`)
+
+	q.Add("query", s.String())
+	q.Add("page", strconv.FormatUint(page, 10))
+	q.Add("per_page", strconv.FormatUint(perPage, 10))
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.httpClient.Do(req)
+	log.Debug("[COSMOS-API] Request Time (/tx_search)", zap.Duration("duration", time.Now().Sub(now)))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 399 { // ERROR
+		serverError, _ := ioutil.ReadAll(resp.Body)
+		c.logger.Error("[COSMOS-API] error getting response from server", zap.Int("code", resp.StatusCode), zap.Any("response", string(serverError)))
+		return nil, fmt.Errorf("error getting response from server %d %s", resp.StatusCode, string(serverError))
+	}
+
+	rawRequestHTTPDuration.WithLabels("/tx_search", resp.Status).Observe(time.Since(now).Seconds())
+	return ioutil.ReadAll(resp.Body)
+}
+
 // transform raw data from cosmos into transaction format with augmentation from blocks
 func rawToTransaction(ctx context.Context, in types.TxResponse, logger *zap.Logger, cdc *codec.Codec) (trans structs.Transaction, err error) {
 	defer logger.Sync()
@@ -171,11 +197,7 @@ func rawToTransaction(ctx context.Context, in types.TxResponse, logger *zap.Logg
 	}
 
 	for _, coin := range tx.Fee.Amount {
-		trans.Fee = append(trans.Fee, structs.TransactionAmount{
-			Text:     coin.Amount.String(),
-			Numeric:  coin.Amount.BigInt(),
-			Currency: coin.Denom,
-		})
+		trans.Fee = append(trans.Fee, util.NewAmount(coin.Denom, coin.Amount.String(), coin.Amount.BigInt(), 0))
 	}
 
 	trans.Height, err = strconv.ParseUint(in.Height, 10, 64)
@@ -195,6 +217,17 @@ func rawToTransaction(ctx context.Context, in types.TxResponse, logger *zap.Logg
 	trans.Raw = make([]byte, txReader.Len())
 	txReader.Read(trans.Raw)
 
+	// ledger is produced before logs are consumed by the conversion below
+	var feePayer sdk.AccAddress
+	if len(tx.Msgs) > 0 {
+		feePayer = tx.FeePayer()
+	}
+	ledger, err := ProduceLedger(trans.Hash, tx.Fee.Amount, feePayer, lf)
+	if err != nil {
+		logger.Error("[KAVA-API] Problem producing transfer ledger", zap.Error(err), zap.String("height", in.Height))
+		ledger = nil
+	}
+
 	presentIndexes := map[string]bool{}
 
 	for index, msg := range tx.Msgs {
@@ -434,34 +467,24 @@ func rawToTransaction(ctx context.Context, in types.TxResponse, logger *zap.Logg
 				}
 
 				for index, amount := range attr.Amount {
-					sliced := util.GetCurrency(amount)
-
-					am := structs.TransactionAmount{
-						Text: amount,
-					}
-
-					var (
-						c       *big.Int
-						exp     int32
-						coinErr error
-					)
-
-					if len(sliced) == 3 {
-						am.Currency = sliced[2]
-						c, exp, coinErr = util.GetCoin(sliced[1])
-					} else {
-						c, exp, coinErr = util.GetCoin(amount)
+					if sub.Amount == nil {
+						sub.Amount = make(map[string]structs.TransactionAmount)
 					}
 
-					if coinErr != nil {
-						am.Numeric.Set(c)
-						am.Exp = exp
+					amts, err := util.ParseAmounts(amount)
+					if err != nil {
+						logger.Debug("[KAVA-API] Problem parsing amount", zap.Error(err), zap.String("height", in.Height))
+						sub.Amount[strconv.Itoa(index)] = structs.TransactionAmount{Text: amount}
+						continue
 					}
 
-					if sub.Amount == nil {
-						sub.Amount = make(map[string]structs.TransactionAmount)
+					for i, am := range amts {
+						key := strconv.Itoa(index)
+						if i > 0 {
+							key += "_" + strconv.Itoa(i)
+						}
+						sub.Amount[key] = am
 					}
-					sub.Amount[strconv.Itoa(index)] = am
 				}
 				ev.Attributes[atk] = nil
 			}
@@ -471,6 +494,8 @@ func rawToTransaction(ctx context.Context, in types.TxResponse, logger *zap.Logg
 		trans.Events = append(trans.Events, tev)
 	}
 
+	attachLedger(&trans, ledger)
+
 	for _, txErr := range txErrs {
 		if txErr.Message != "" {
 			trans.Events = append(trans.Events, structs.TransactionEvent{
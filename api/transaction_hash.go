@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/figment-networks/kava-worker/api/types"
+)
+
+// GetTransaction fetches transaction of given hash, along with the context of its block
+func (c *Client) GetTransaction(ctx context.Context, hash string) (tx structs.Transaction, err error) {
+	if hash == "" {
+		return tx, fmt.Errorf("[KAVA-API] Empty transaction hash")
+	}
+
+	if err = c.rateLimiter.Wait(ctx); err != nil {
+		return tx, err
+	}
+
+	sCtx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+	req, err := http.NewRequestWithContext(sCtx, http.MethodGet, c.baseURL+"/tx", nil)
+	if err != nil {
+		return tx, err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	if c.key != "" {
+		req.Header.Add("Authorization", c.key)
+	}
+
+	q := req.URL.Query()
+	q.Add("hash", "0x"+strings.TrimPrefix(strings.ToUpper(hash), "0X"))
+	req.URL.RawQuery = q.Encode()
+
+	n := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return tx, err
+	}
+	rawRequestHTTPDuration.WithLabels("/tx", resp.Status).Observe(time.Since(n).Seconds())
+	defer resp.Body.Close()
+
+	var result types.GetTxResponse
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return tx, fmt.Errorf("unable to decode result body %w", err)
+	}
+
+	if result.Error.Message != "" {
+		return tx, fmt.Errorf("[KAVA-API] Error fetching transaction: %s %s", result.Error.Message, result.Error.Data)
+	}
+
+	height, err := strconv.ParseUint(result.Result.Height, 10, 64)
+	if err != nil {
+		return tx, fmt.Errorf("[KAVA-API] Error parsing transaction height: %w", err)
+	}
+
+	block, err := c.GetBlock(ctx, structs.HeightHash{Height: height})
+	if err != nil {
+		return tx, fmt.Errorf("[KAVA-API] Error fetching block of transaction: %w", err)
+	}
+
+	tx, err = rawToTransaction(ctx, result.Result, c.logger, c.cdc)
+	if err != nil {
+		return tx, err
+	}
+	tx.BlockHash = block.Hash
+	tx.ChainID = block.ChainID
+	tx.Time = block.Time
+	if c.Valuator != nil {
+		c.Valuator.Valuate(ctx, &tx)
+	}
+
+	return tx, nil
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/figment-networks/kava-worker/api/types"
+	"go.uber.org/zap"
+)
+
+// txNode serves the fixture transaction under fixtureTxHash, blocks are served by accountNode
+type txNode struct {
+	accountNode
+	hashes []string
+}
+
+func (tn *txNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/tx" {
+		tn.accountNode.ServeHTTP(w, r)
+		return
+	}
+
+	hash := r.URL.Query().Get("hash")
+	tn.hashes = append(tn.hashes, hash)
+	resp := types.GetTxResponse{RPC: "2.0"}
+	if hash == "0x"+fixtureTxHash {
+		resp.Result = accountTx(fixtureTxHash, 1000, 0)
+	} else {
+		resp.Error = types.Error{Code: -32603, Message: "Internal error", Data: "tx (" + hash + ") not found"}
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func TestGetTransaction(t *testing.T) {
+	tn := &txNode{}
+	tn.pages = make(map[string]int)
+	InitMetrics()
+	srv := httptest.NewServer(tn)
+	defer srv.Close()
+	c := NewClient(srv.URL, "", zap.NewNop(), nil, 100)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		hash    string
+		query   string
+		wantErr bool
+	}{
+		{name: "upper case", hash: fixtureTxHash, query: "0x" + fixtureTxHash},
+		{name: "lower case with prefix", hash: "0x" + "25c62396c5f54525133f721504301416b2d2ab1e6ca5f252ebdbbd064be13221", query: "0x" + fixtureTxHash},
+		{name: "unknown", hash: "ABCD", query: "0xABCD", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tn.hashes = nil
+			tx, err := c.GetTransaction(ctx, tt.hash)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetTransaction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(tn.hashes) != 1 || tn.hashes[0] != tt.query {
+				t.Errorf("requested hashes %v, want %s", tn.hashes, tt.query)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tx.Hash != fixtureTxHash || tx.Height != 1000 || tx.BlockHash != "BLOCK1000" || tx.ChainID != "kava-4" || tx.Time.IsZero() {
+				t.Errorf("unexpected transaction %+v", tx)
+			}
+			if tx.Memo != "fixture" || len(tx.Fee) != 1 || len(tx.Events) == 0 {
+				t.Errorf("transaction not converted: memo %q, fee %+v, events %+v", tx.Memo, tx.Fee, tx.Events)
+			}
+		})
+	}
+
+	if _, err := c.GetTransaction(ctx, ""); err == nil {
+		t.Error("expected error for empty hash")
+	}
+}
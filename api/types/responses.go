@@ -61,9 +61,10 @@ type Block struct {
 
 // BlockHeader structures
 type BlockHeader struct {
-	Height  string `json:"height"`
-	ChainID string `json:"chain_id"`
-	Time    string `json:"time"`
+	Height      string  `json:"height"`
+	ChainID     string  `json:"chain_id"`
+	Time        string  `json:"time"`
+	LastBlockID BlockID `json:"last_block_id"`
 }
 
 // BlockData structures
@@ -92,6 +93,13 @@ type GetTxSearchResponse struct {
 	Error  Error          `json:"error"`
 }
 
+// GetTxResponse cosmos response for single transaction
+type GetTxResponse struct {
+	RPC    string     `json:"jsonrpc"`
+	Result TxResponse `json:"result"`
+	Error  Error      `json:"error"`
+}
+
 // GetBlockResponse cosmos response from block
 type GetBlockResponse struct {
 	// ID     string      `json:"id"`
@@ -107,3 +115,29 @@ type GetBlockchainResponse struct {
 	Result ResultBlockchain `json:"result"`
 	Error  Error            `json:"error"`
 }
+
+// ResultBlockResults is result of fetching block results
+type ResultBlockResults struct {
+	Height           string       `json:"height"`
+	BeginBlockEvents []BlockEvent `json:"begin_block_events"`
+	EndBlockEvents   []BlockEvent `json:"end_block_events"`
+}
+
+// BlockEvent is an event emitted by begin or end blocker
+type BlockEvent struct {
+	Type       string                `json:"type"`
+	Attributes []BlockEventAttribute `json:"attributes"`
+}
+
+// BlockEventAttribute is base64 encoded attribute of block event
+type BlockEventAttribute struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// GetBlockResultsResponse cosmos response from block_results
+type GetBlockResultsResponse struct {
+	RPC    string             `json:"jsonrpc"`
+	Result ResultBlockResults `json:"result"`
+	Error  Error              `json:"error"`
+}
@@ -0,0 +1,156 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"github.com/figment-networks/indexing-engine/structs"
+)
+
+var (
+	// ErrMissingAmount is returned when coin has no numeric part
+	ErrMissingAmount = errors.New("missing amount")
+	// ErrMissingDenom is returned when coin has no denomination
+	ErrMissingDenom = errors.New("missing denom")
+	// ErrInvalidAmount is returned when numeric part of coin is malformed
+	ErrInvalidAmount = errors.New("invalid amount")
+	// ErrInvalidDenom is returned when denomination of coin is malformed
+	ErrInvalidDenom = errors.New("invalid denom")
+)
+
+// denomRegex follows cosmos sdk denom rules extended with ibc style denoms (eg. ibc/27A6...)
+var denomRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9/:._-]{1,127}$`)
+
+// CoinParseError is returned when coin string cannot be parsed
+type CoinParseError struct {
+	// Input is the whole parsed string
+	Input string
+	// Coin is the malformed coin
+	Coin string
+	// Reason is one of Err* errors
+	Reason error
+}
+
+func (e *CoinParseError) Error() string {
+	return fmt.Sprintf("error parsing coin %q of %q: %s", e.Coin, e.Input, e.Reason)
+}
+
+// Unwrap returns the reason of error
+func (e *CoinParseError) Unwrap() error {
+	return e.Reason
+}
+
+// Coin is a parsed coin with decimal implementation (numeric * 10 ^ -exp)
+type Coin struct {
+	// Text is the coin as it was written (eg. 1.5ukava)
+	Text    string
+	Denom   string
+	Numeric *big.Int
+	Exp     int32
+}
+
+// ParseCoins parses comma separated list of coins (eg. `100ukava,5.5hard,1ibc/27A6`).
+// It follows sdk.ParseCoins and sdk.ParseDecCoins semantics, with more permissive denominations.
+// Empty string returns no coins.
+func ParseCoins(in string) (coins []Coin, err error) {
+	if strings.TrimSpace(in) == "" {
+		return nil, nil
+	}
+
+	for _, part := range strings.Split(in, ",") {
+		c, reason := parseCoin(strings.TrimSpace(part))
+		if reason != nil {
+			return nil, &CoinParseError{Input: in, Coin: part, Reason: reason}
+		}
+		coins = append(coins, c)
+	}
+
+	return coins, nil
+}
+
+// ParseAmount parses a decimal number without denomination (eg. `100`, `0.25`)
+func ParseAmount(in string) (numeric *big.Int, exp int32, err error) {
+	numeric, exp, reason := parseDecimal(strings.TrimSpace(in))
+	if reason != nil {
+		return nil, 0, &CoinParseError{Input: in, Coin: in, Reason: reason}
+	}
+	return numeric, exp, nil
+}
+
+func parseCoin(in string) (c Coin, reason error) {
+	c.Text = in
+
+	i := 0
+	for ; i < len(in); i++ {
+		if (in[i] < '0' || in[i] > '9') && in[i] != '.' {
+			break
+		}
+	}
+
+	amount, denom := in[:i], strings.TrimSpace(in[i:])
+	if amount == "" {
+		return c, ErrMissingAmount
+	}
+	if denom == "" {
+		return c, ErrMissingDenom
+	}
+	if !denomRegex.MatchString(denom) {
+		return c, ErrInvalidDenom
+	}
+
+	c.Denom = denom
+	c.Numeric, c.Exp, reason = parseDecimal(amount)
+	return c, reason
+}
+
+func parseDecimal(in string) (numeric *big.Int, exp int32, reason error) {
+	if in == "" {
+		return nil, 0, ErrMissingAmount
+	}
+
+	intPart, fracPart := in, ""
+	if dot := strings.IndexByte(in, '.'); dot >= 0 {
+		intPart, fracPart = in[:dot], in[dot+1:]
+		if fracPart == "" || strings.IndexByte(fracPart, '.') >= 0 {
+			return nil, 0, ErrInvalidAmount
+		}
+	}
+
+	for _, r := range intPart + fracPart {
+		if r < '0' || r > '9' {
+			return nil, 0, ErrInvalidAmount
+		}
+	}
+
+	numeric, ok := new(big.Int).SetString(intPart+fracPart, 10)
+	if !ok {
+		return nil, 0, ErrInvalidAmount
+	}
+
+	return numeric, int32(len(fracPart)), nil
+}
+
+// ParseAmounts parses coins list into TransactionAmounts, converted to display denominations by NewAmount.
+// Single amount without denomination is accepted as well.
+func ParseAmounts(in string) (amounts []structs.TransactionAmount, err error) {
+	coins, err := ParseCoins(in)
+	if err != nil {
+		if !errors.Is(err, ErrMissingDenom) || strings.Contains(in, ",") {
+			return nil, err
+		}
+
+		numeric, exp, aErr := ParseAmount(in)
+		if aErr != nil {
+			return nil, err
+		}
+		return []structs.TransactionAmount{NewAmount("", strings.TrimSpace(in), numeric, exp)}, nil
+	}
+
+	for _, c := range coins {
+		amounts = append(amounts, NewAmount(c.Denom, c.Text, c.Numeric, c.Exp))
+	}
+	return amounts, nil
+}
@@ -0,0 +1,204 @@
+package util
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestParseCoins(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []Coin
+		wantErr error
+	}{
+		{name: "empty", in: ""},
+		{name: "spaces only", in: "   "},
+		{
+			name: "single coin",
+			in:   "100ukava",
+			want: []Coin{{Text: "100ukava", Denom: "ukava", Numeric: big.NewInt(100)}},
+		},
+		{
+			name: "multi coin",
+			in:   "100ukava,5hard",
+			want: []Coin{
+				{Text: "100ukava", Denom: "ukava", Numeric: big.NewInt(100)},
+				{Text: "5hard", Denom: "hard", Numeric: big.NewInt(5)},
+			},
+		},
+		{
+			name: "decimal coin",
+			in:   "1.250000000000000000ukava",
+			want: []Coin{{Text: "1.250000000000000000ukava", Denom: "ukava", Numeric: big.NewInt(1250000000000000000), Exp: 18}},
+		},
+		{
+			name: "decimal without integer part",
+			in:   ".5usdx",
+			want: []Coin{{Text: ".5usdx", Denom: "usdx", Numeric: big.NewInt(5), Exp: 1}},
+		},
+		{
+			name: "ibc denom",
+			in:   "42ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2",
+			want: []Coin{{Text: "42ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2", Denom: "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2", Numeric: big.NewInt(42)}},
+		},
+		{
+			name: "denom with digits",
+			in:   "7btc2usd,3xrpb",
+			want: []Coin{
+				{Text: "7btc2usd", Denom: "btc2usd", Numeric: big.NewInt(7)},
+				{Text: "3xrpb", Denom: "xrpb", Numeric: big.NewInt(3)},
+			},
+		},
+		{
+			name: "whitespaces",
+			in:   " 10 ukava , 20hard",
+			want: []Coin{
+				{Text: "10 ukava", Denom: "ukava", Numeric: big.NewInt(10)},
+				{Text: "20hard", Denom: "hard", Numeric: big.NewInt(20)},
+			},
+		},
+		{name: "missing denom", in: "100", wantErr: ErrMissingDenom},
+		{name: "missing amount", in: "ukava", wantErr: ErrMissingAmount},
+		{name: "empty coin in list", in: "100ukava,,5hard", wantErr: ErrMissingAmount},
+		{name: "comma as decimal separator", in: "1,5ukava", wantErr: ErrMissingDenom},
+		{name: "two dots", in: "1.2.3ukava", wantErr: ErrInvalidAmount},
+		{name: "trailing dot", in: "5.ukava", wantErr: ErrInvalidAmount},
+		{name: "negative", in: "-5ukava", wantErr: ErrMissingAmount},
+		{name: "invalid denom", in: "5u$kava", wantErr: ErrInvalidDenom},
+		{name: "denom starting with slash", in: "5/ukava", wantErr: ErrInvalidDenom},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCoins(tt.in)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ParseCoins(%q) error = %v, want %v", tt.in, err, tt.wantErr)
+				}
+				var cpe *CoinParseError
+				if !errors.As(err, &cpe) || cpe.Input != tt.in {
+					t.Fatalf("ParseCoins(%q) error = %#v, want CoinParseError", tt.in, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCoins(%q) unexpected error %v", tt.in, err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseCoins(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+			for i := range got {
+				if got[i].Text != tt.want[i].Text || got[i].Denom != tt.want[i].Denom || got[i].Exp != tt.want[i].Exp || got[i].Numeric.Cmp(tt.want[i].Numeric) != 0 {
+					t.Errorf("ParseCoins(%q)[%d] = %+v, want %+v", tt.in, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseAmounts(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		currency []string
+		exp      []int32
+		wantErr  bool
+	}{
+		{name: "known denoms", in: "1000000ukava,100000000bnb", currency: []string{"KAVA", "BNB"}, exp: []int32{6, 8}},
+		{name: "unknown denom", in: "12foo", currency: []string{"foo"}, exp: []int32{0}},
+		{name: "display denom", in: "1KAVA", currency: []string{"KAVA"}, exp: []int32{0}},
+		{name: "decimal known denom", in: "0.5ukava", currency: []string{"KAVA"}, exp: []int32{7}},
+		{name: "bare amount", in: "1000", currency: []string{""}, exp: []int32{0}},
+		{name: "bare amounts list", in: "1000,2000", wantErr: true},
+		{name: "garbage", in: "abc$", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAmounts(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAmounts(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if len(got) != len(tt.currency) {
+				t.Fatalf("ParseAmounts(%q) = %v", tt.in, got)
+			}
+			for i := range got {
+				if got[i].Currency != tt.currency[i] || got[i].Exp != tt.exp[i] {
+					t.Errorf("ParseAmounts(%q)[%d] = %+v", tt.in, i, got[i])
+				}
+			}
+		})
+	}
+}
+
+// TestParseCoinsInvariants checks properties every result of ParseCoins has, on valid and malformed input
+func TestParseCoinsInvariants(t *testing.T) {
+	for _, in := range []string{
+		"", "100ukava", "100ukava,5hard", "1.5usdx", ".5bnb", "1,5ukava", "5.ukava",
+		"42ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2",
+		"7btc2usd", "-1ukava", "100", "ukava", "1.2.3hard", " 1 ukava ,2 hard ",
+		",,", "0ukava", "00012hard", "1e5ukava", "1ukava,", "1UKAVA", "1u-kava", "\x00", "100ukava\n5hard",
+	} {
+		coins, err := ParseCoins(in)
+		if err != nil {
+			var cpe *CoinParseError
+			if !errors.As(err, &cpe) {
+				t.Errorf("ParseCoins(%q) returned unstructured error %v", in, err)
+				continue
+			}
+			if cpe.Reason != ErrMissingAmount && cpe.Reason != ErrMissingDenom && cpe.Reason != ErrInvalidAmount && cpe.Reason != ErrInvalidDenom {
+				t.Errorf("ParseCoins(%q) returned unknown reason %v", in, cpe.Reason)
+			}
+			continue
+		}
+
+		for _, c := range coins {
+			if c.Numeric == nil || c.Numeric.Sign() < 0 || c.Exp < 0 {
+				t.Errorf("ParseCoins(%q) returned wrong amount %+v", in, c)
+			}
+			if !denomRegex.MatchString(c.Denom) {
+				t.Errorf("ParseCoins(%q) returned wrong denom %q", in, c.Denom)
+			}
+			if !strings.HasSuffix(c.Text, c.Denom) {
+				t.Errorf("ParseCoins(%q) returned text %q not matching denom %q", in, c.Text, c.Denom)
+			}
+		}
+	}
+}
+
+// TestParseCoinsSDK checks that everything sdk is able to parse is parsed the same way
+func TestParseCoinsSDK(t *testing.T) {
+	for _, in := range []string{
+		// sdk of this version parses only amounts with decimal point
+		"100.0ukava", "100.0ukava,5.0hard", "1.5usdx", "0.5bnb", "0.000001btcb,3.0xrpb",
+		"1000000000000000000000000.0ukava", "12.345678901234567890hard", "1.0ukava,2.0usdx,3.0bnb",
+	} {
+		sdkCoins, sdkErr := sdk.ParseDecCoins(in)
+		if sdkErr != nil {
+			t.Fatalf("sdk failed to parse %q: %v", in, sdkErr)
+		}
+
+		coins, err := ParseCoins(in)
+		if err != nil {
+			t.Errorf("ParseCoins(%q) error %v, while sdk parsed %v", in, err, sdkCoins)
+			continue
+		}
+
+		if len(coins) != len(sdkCoins) {
+			t.Errorf("ParseCoins(%q) = %v, sdk parsed %v", in, coins, sdkCoins)
+			continue
+		}
+
+		for _, c := range coins {
+			got := sdk.NewDecFromBigIntWithPrec(c.Numeric, int64(c.Exp))
+			if want := sdkCoins.AmountOf(c.Denom); !got.Equal(want) {
+				t.Errorf("ParseCoins(%q) amount of %s = %s, sdk parsed %s", in, c.Denom, got, want)
+			}
+		}
+	}
+}
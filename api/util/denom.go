@@ -0,0 +1,165 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/figment-networks/indexing-engine/structs"
+)
+
+// Denom is a metadata of base denomination
+type Denom struct {
+	// Base denomination used on chain (eg. ukava)
+	Base string `json:"base"`
+	// Display denomination (eg. KAVA)
+	Display string `json:"display"`
+	// Exponent of base denomination in display units (1 KAVA = 10^6 ukava)
+	Exponent int32 `json:"exponent"`
+}
+
+// DefaultDenoms are denominations known on kava chain
+var DefaultDenoms = []Denom{
+	{Base: "ukava", Display: "KAVA", Exponent: 6},
+	{Base: "hard", Display: "HARD", Exponent: 6},
+	{Base: "swp", Display: "SWP", Exponent: 6},
+	{Base: "usdx", Display: "USDX", Exponent: 6},
+	{Base: "bnb", Display: "BNB", Exponent: 8},
+	{Base: "btcb", Display: "BTCB", Exponent: 8},
+	{Base: "xrpb", Display: "XRPB", Exponent: 8},
+	{Base: "busd", Display: "BUSD", Exponent: 8},
+}
+
+var registry = NewDenomRegistry(DefaultDenoms)
+
+// DenomRegistry is a thread safe registry of denominations
+type DenomRegistry struct {
+	denoms map[string]Denom
+	l      sync.RWMutex
+}
+
+// NewDenomRegistry is DenomRegistry constructor
+func NewDenomRegistry(denoms []Denom) *DenomRegistry {
+	dr := &DenomRegistry{}
+	dr.Set(denoms)
+	return dr
+}
+
+// Set replaces all denominations in registry
+func (dr *DenomRegistry) Set(denoms []Denom) {
+	dm := make(map[string]Denom, len(denoms)*2)
+	for _, d := range denoms {
+		dm[strings.ToLower(d.Base)] = d
+	}
+	// display names are added only when they do not collide with base denoms
+	for _, d := range denoms {
+		if _, ok := dm[strings.ToLower(d.Display)]; !ok {
+			dm[strings.ToLower(d.Display)] = d
+		}
+	}
+
+	dr.l.Lock()
+	defer dr.l.Unlock()
+	dr.denoms = dm
+}
+
+// List returns all denominations, ordered by base denomination
+func (dr *DenomRegistry) List() (denoms []Denom) {
+	dr.l.RLock()
+	defer dr.l.RUnlock()
+
+	for name, d := range dr.denoms {
+		if strings.EqualFold(name, d.Base) {
+			denoms = append(denoms, d)
+		}
+	}
+	sort.Slice(denoms, func(i, j int) bool { return denoms[i].Base < denoms[j].Base })
+	return denoms
+}
+
+// ServeHTTP lists denominations as JSON
+func (dr *DenomRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.Encode(dr.List())
+}
+
+// Get looks up the denomination by its base or display name
+func (dr *DenomRegistry) Get(name string) (d Denom, ok bool) {
+	dr.l.RLock()
+	defer dr.l.RUnlock()
+
+	d, ok = dr.denoms[strings.ToLower(name)]
+	return d, ok
+}
+
+// SetDenoms replaces denominations in default registry
+func SetDenoms(denoms []Denom) {
+	registry.Set(denoms)
+}
+
+// Registry returns default registry
+func Registry() *DenomRegistry {
+	return registry
+}
+
+// GetDenom looks up the denomination in default registry
+func GetDenom(name string) (d Denom, ok bool) {
+	return registry.Get(name)
+}
+
+// ParseDenoms parses comma separated list of `base=DISPLAY:exponent` (eg. `ukava=KAVA:6,bnb=BNB:8`)
+func ParseDenoms(in string) (denoms []Denom, err error) {
+	for _, entry := range strings.Split(in, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kv := strings.SplitN(entry, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("wrong denom %q, expected base=DISPLAY:exponent", entry)
+		}
+
+		de := strings.SplitN(kv[1], ":", 2)
+		if len(de) != 2 || de[0] == "" {
+			return nil, fmt.Errorf("wrong denom %q, expected base=DISPLAY:exponent", entry)
+		}
+
+		exp, err := strconv.ParseInt(de[1], 10, 32)
+		if err != nil || exp < 0 {
+			return nil, fmt.Errorf("wrong exponent in denom %q", entry)
+		}
+
+		denoms = append(denoms, Denom{Base: kv[0], Display: de[0], Exponent: int32(exp)})
+	}
+	return denoms, nil
+}
+
+// NewAmount creates TransactionAmount in display denomination of default registry.
+// The exp is the exponent of numeric in base denomination (eg. 18 for sdk.Dec), for known denominations
+// the currency becomes the display denomination and its exponent is added, while numeric stays in base units
+// (1500000ukava is 1500000 * 10^-6 KAVA). Denominations not present in registry are kept as they are.
+func NewAmount(denom, text string, numeric *big.Int, exp int32) structs.TransactionAmount {
+	am := structs.TransactionAmount{
+		Text:     text,
+		Currency: denom,
+		Numeric:  new(big.Int),
+		Exp:      exp,
+	}
+
+	if numeric != nil {
+		am.Numeric.Set(numeric)
+	}
+
+	if d, ok := registry.Get(denom); ok && strings.EqualFold(d.Base, denom) {
+		am.Currency = d.Display
+		am.Exp += d.Exponent
+	}
+	return am
+}
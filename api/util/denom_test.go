@@ -0,0 +1,148 @@
+package util
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/figment-networks/indexing-engine/structs"
+)
+
+func TestParseDenoms(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []Denom
+		wantErr bool
+	}{
+		{name: "defaults", in: "ukava=KAVA:6,bnb=BNB:8", want: []Denom{{Base: "ukava", Display: "KAVA", Exponent: 6}, {Base: "bnb", Display: "BNB", Exponent: 8}}},
+		{name: "spaces and empty entries", in: " ukava=KAVA:6 ,, ", want: []Denom{{Base: "ukava", Display: "KAVA", Exponent: 6}}},
+		{name: "zero exponent", in: "foo=FOO:0", want: []Denom{{Base: "foo", Display: "FOO"}}},
+		{name: "empty", in: ""},
+		{name: "missing display", in: "ukava=:6", wantErr: true},
+		{name: "missing base", in: "=KAVA:6", wantErr: true},
+		{name: "missing exponent", in: "ukava=KAVA", wantErr: true},
+		{name: "negative exponent", in: "ukava=KAVA:-6", wantErr: true},
+		{name: "not a number", in: "ukava=KAVA:six", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDenoms(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDenoms(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDenoms(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDenomRegistry(t *testing.T) {
+	abc := Denom{Base: "abc", Display: "XYZ", Exponent: 2}
+	xyz := Denom{Base: "xyz", Display: "ABC", Exponent: 3}
+	dr := NewDenomRegistry([]Denom{abc, xyz})
+
+	tests := []struct {
+		name string
+		want Denom
+		ok   bool
+	}{
+		// display names colliding with base denoms are not registered
+		{name: "abc", want: abc, ok: true},
+		{name: "ABC", want: abc, ok: true},
+		{name: "xyz", want: xyz, ok: true},
+		{name: "XYZ", want: xyz, ok: true},
+		{name: "foo"},
+	}
+	for _, tt := range tests {
+		if got, ok := dr.Get(tt.name); ok != tt.ok || got != tt.want {
+			t.Errorf("Get(%q) = %+v, %v, want %+v, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	if got := dr.List(); !reflect.DeepEqual(got, []Denom{abc, xyz}) {
+		t.Errorf("List() = %+v", got)
+	}
+
+	dr.Set([]Denom{{Base: "ukava", Display: "KAVA", Exponent: 6}})
+	if _, ok := dr.Get("abc"); ok {
+		t.Error("Set should replace all denominations")
+	}
+	if d, ok := dr.Get("kava"); !ok || d.Base != "ukava" {
+		t.Errorf("Get(kava) = %+v, %v", d, ok)
+	}
+
+	rec := httptest.NewRecorder()
+	dr.ServeHTTP(rec, httptest.NewRequest("GET", "/denoms", nil))
+	listed := []Denom{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("error decoding response: %v", err)
+	}
+	if !reflect.DeepEqual(listed, []Denom{{Base: "ukava", Display: "KAVA", Exponent: 6}}) {
+		t.Errorf("listed %+v", listed)
+	}
+}
+
+func TestNewAmount(t *testing.T) {
+	dec := new(big.Int).Mul(big.NewInt(25), new(big.Int).Exp(big.NewInt(10), big.NewInt(17), nil))
+	tests := []struct {
+		name    string
+		denom   string
+		numeric *big.Int
+		exp     int32
+		want    structs.TransactionAmount
+	}{
+		{
+			name:    "base units",
+			denom:   "ukava",
+			numeric: big.NewInt(1500000),
+			want:    structs.TransactionAmount{Text: "text", Currency: "KAVA", Numeric: big.NewInt(1500000), Exp: 6},
+		},
+		{
+			name:    "decimal (sdk.Dec)",
+			denom:   "ukava",
+			numeric: dec,
+			exp:     18,
+			want:    structs.TransactionAmount{Text: "text", Currency: "KAVA", Numeric: dec, Exp: 24},
+		},
+		{
+			name:    "exponent of 8",
+			denom:   "bnb",
+			numeric: big.NewInt(1),
+			want:    structs.TransactionAmount{Text: "text", Currency: "BNB", Numeric: big.NewInt(1), Exp: 8},
+		},
+		{
+			name:    "display denom is not converted again",
+			denom:   "KAVA",
+			numeric: big.NewInt(1),
+			want:    structs.TransactionAmount{Text: "text", Currency: "KAVA", Numeric: big.NewInt(1)},
+		},
+		{
+			name:    "unknown denom",
+			denom:   "foo",
+			numeric: big.NewInt(12),
+			exp:     2,
+			want:    structs.TransactionAmount{Text: "text", Currency: "foo", Numeric: big.NewInt(12), Exp: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			am := NewAmount(tt.denom, "text", tt.numeric, tt.exp)
+			if !reflect.DeepEqual(am, tt.want) {
+				t.Errorf("NewAmount() = %+v, want %+v", am, tt.want)
+			}
+			if am.Numeric == tt.numeric {
+				t.Error("NewAmount() should copy numeric")
+			}
+		})
+	}
+
+	if am := NewAmount("ukava", "", nil, 0); am.Numeric == nil || am.Numeric.Sign() != 0 {
+		t.Errorf("NewAmount() without numeric = %+v", am)
+	}
+}
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/figment-networks/kava-worker/api/util"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"go.uber.org/zap"
+)
+
+// PeggedMarket is a market name for denoms that are always worth 1 USD
+const PeggedMarket = "peg"
+
+// ValuationEventKind is a kind of event carrying usd values of transaction amounts
+const ValuationEventKind = "usd_valuation"
+
+// PriceGetter gets price of the market at given height
+type PriceGetter interface {
+	GetPrice(ctx context.Context, market string, height uint64) (price sdk.Dec, err error)
+}
+
+// USDValuator annotates transaction amounts with its USD value taken from pricefeed
+type USDValuator struct {
+	prices  PriceGetter
+	markets map[string]string
+	logger  *zap.Logger
+
+	cache *priceCache
+}
+
+// NewUSDValuator is USDValuator constructor.
+// Markets maps base denom to pricefeed market (eg. ukava -> kava:usd)
+func NewUSDValuator(prices PriceGetter, markets map[string]string, logger *zap.Logger) *USDValuator {
+	return &USDValuator{
+		prices:  prices,
+		markets: markets,
+		logger:  logger,
+		cache:   newPriceCache(2000),
+	}
+}
+
+// Valuate appends usd_valuation event to the transaction.
+// Every valued amount is referenced by its path in transaction (eg. `fee.0`, `0.0.amount.collateral`, `0.0.transfers.send.1.0`)
+func (v *USDValuator) Valuate(ctx context.Context, tx *structs.Transaction) {
+	values := make(map[string]structs.TransactionAmount)
+	prices := make(map[string][]string)
+
+	for i, am := range tx.Fee {
+		v.value(ctx, tx.Height, "fee."+strconv.Itoa(i), am, values, prices)
+	}
+
+	for _, ev := range tx.Events {
+		for si, sub := range ev.Sub {
+			prefix := ev.ID + "." + strconv.Itoa(si) + "."
+			for k, am := range sub.Amount {
+				v.value(ctx, tx.Height, prefix+"amount."+k, am, values, prices)
+			}
+			for tType, transfers := range sub.Transfers {
+				v.valueTransfers(ctx, tx.Height, prefix+"transfers."+tType+".", transfers, values, prices)
+			}
+			v.valueTransfers(ctx, tx.Height, prefix+"sender.", sub.Sender, values, prices)
+			v.valueTransfers(ctx, tx.Height, prefix+"recipient.", sub.Recipient, values, prices)
+		}
+	}
+
+	if len(values) == 0 {
+		return
+	}
+
+	tx.Events = append(tx.Events, structs.TransactionEvent{
+		Kind: ValuationEventKind,
+		Sub: []structs.SubsetEvent{{
+			Type:       []string{ValuationEventKind},
+			Module:     "pricefeed",
+			Amount:     values,
+			Additional: prices,
+		}},
+	})
+}
+
+func (v *USDValuator) valueTransfers(ctx context.Context, height uint64, prefix string, transfers []structs.EventTransfer, values map[string]structs.TransactionAmount, prices map[string][]string) {
+	for ti, t := range transfers {
+		for ai, am := range t.Amounts {
+			v.value(ctx, height, prefix+strconv.Itoa(ti)+"."+strconv.Itoa(ai), am, values, prices)
+		}
+	}
+}
+
+func (v *USDValuator) value(ctx context.Context, height uint64, path string, am structs.TransactionAmount, values map[string]structs.TransactionAmount, prices map[string][]string) {
+	if am.Numeric == nil {
+		return
+	}
+
+	// prices are given per display unit, so only amounts converted to display denomination are valued
+	d, ok := util.GetDenom(am.Currency)
+	if !ok || !strings.EqualFold(d.Display, am.Currency) {
+		return
+	}
+
+	market, ok := v.markets[strings.ToLower(d.Base)]
+	if !ok {
+		return
+	}
+
+	numeric, prec := am.Numeric, int64(am.Exp)
+	if prec < 0 {
+		return
+	}
+	if prec > sdk.Precision {
+		numeric = new(big.Int).Quo(numeric, new(big.Int).Exp(big.NewInt(10), big.NewInt(prec-sdk.Precision), nil))
+		prec = sdk.Precision
+	}
+
+	price, ok := v.price(ctx, market, height)
+	if !ok {
+		return
+	}
+
+	usd := sdk.NewDecFromBigIntWithPrec(numeric, prec).Mul(price)
+	values[path] = structs.TransactionAmount{
+		Text:     usd.String(),
+		Currency: "USD",
+		Numeric:  usd.BigInt(),
+		Exp:      sdk.Precision,
+	}
+
+	if _, ok := prices[market]; !ok && market != PeggedMarket {
+		prices[market] = []string{price.String()}
+	}
+}
+
+func (v *USDValuator) price(ctx context.Context, market string, height uint64) (sdk.Dec, bool) {
+	if market == PeggedMarket {
+		return sdk.OneDec(), true
+	}
+
+	if p, ok := v.cache.Get(market, height); ok {
+		return p.price, p.ok
+	}
+
+	price, err := v.prices.GetPrice(ctx, market, height)
+	if err != nil {
+		v.logger.Warn("[KAVA-API] Error getting price", zap.Error(err), zap.String("market", market), zap.Uint64("height", height))
+		// transient errors are not cached, so the price is fetched again for the next transaction
+		if errors.Is(err, ErrPriceNotFound) {
+			v.cache.Add(market, height, cachedPrice{})
+		}
+		return price, false
+	}
+
+	v.cache.Add(market, height, cachedPrice{price: price, ok: true})
+	return price, true
+}
+
+type priceKey struct {
+	market string
+	height uint64
+}
+
+type cachedPrice struct {
+	price sdk.Dec
+	ok    bool
+}
+
+// priceCache simple in memory cache of prices per market and height
+type priceCache struct {
+	space map[priceKey]cachedPrice
+	keys  chan priceKey
+	l     sync.RWMutex
+}
+
+func newPriceCache(cap int) *priceCache {
+	return &priceCache{
+		space: make(map[priceKey]cachedPrice),
+		keys:  make(chan priceKey, cap),
+	}
+}
+
+func (pc *priceCache) Add(market string, height uint64, p cachedPrice) {
+	pc.l.Lock()
+	defer pc.l.Unlock()
+
+	k := priceKey{market, height}
+	if _, ok := pc.space[k]; ok {
+		return
+	}
+
+	pc.space[k] = p
+	select {
+	case pc.keys <- k:
+	default:
+		delete(pc.space, <-pc.keys)
+		pc.keys <- k
+	}
+}
+
+func (pc *priceCache) Get(market string, height uint64) (p cachedPrice, ok bool) {
+	pc.l.RLock()
+	defer pc.l.RUnlock()
+
+	p, ok = pc.space[priceKey{market, height}]
+	return p, ok
+}
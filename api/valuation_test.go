@@ -0,0 +1,203 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/figment-networks/kava-worker/api/util"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"go.uber.org/zap"
+)
+
+// stubPrices serves fixed prices, markets listed in errs fail
+type stubPrices struct {
+	prices map[string]sdk.Dec
+	errs   map[string]error
+	calls  map[string]int
+	l      sync.Mutex
+}
+
+func (sp *stubPrices) GetPrice(ctx context.Context, market string, height uint64) (sdk.Dec, error) {
+	sp.l.Lock()
+	defer sp.l.Unlock()
+
+	if sp.calls == nil {
+		sp.calls = make(map[string]int)
+	}
+	sp.calls[market]++
+	if err, ok := sp.errs[market]; ok {
+		return sdk.Dec{}, err
+	}
+	p, ok := sp.prices[market]
+	if !ok {
+		return sdk.Dec{}, ErrPriceNotFound
+	}
+	return p, nil
+}
+
+func usd(s string) structs.TransactionAmount {
+	d := sdk.MustNewDecFromStr(s)
+	return structs.TransactionAmount{Text: d.String(), Currency: "USD", Numeric: d.BigInt(), Exp: sdk.Precision}
+}
+
+func ukava(n int64) structs.TransactionAmount {
+	return util.NewAmount("ukava", fmt.Sprintf("%dukava", n), sdk.NewInt(n).BigInt(), 0)
+}
+
+func TestValuate(t *testing.T) {
+	prices := &stubPrices{prices: map[string]sdk.Dec{"kava:usd": sdk.MustNewDecFromStr("2.5")}}
+	v := NewUSDValuator(prices, map[string]string{"ukava": "kava:usd", "usdx": PeggedMarket, "hard": "hard:usd"}, zap.NewNop())
+
+	tx := &structs.Transaction{
+		Height: 10,
+		Fee:    []structs.TransactionAmount{ukava(4000)},
+		Events: structs.TransactionEvents{{
+			ID:   "0",
+			Kind: "send",
+			Sub: []structs.SubsetEvent{{
+				Amount: map[string]structs.TransactionAmount{
+					// 0.5 KAVA as sdk.Dec of ukava, its display exponent exceeds sdk.Precision
+					"reward": util.NewAmount("ukava", "", sdk.NewDecWithPrec(5, 1).MulInt64(1000000).BigInt(), sdk.Precision),
+					"other":  util.NewAmount("foo", "", sdk.NewInt(5).BigInt(), 0),
+					"hard":   util.NewAmount("hard", "", sdk.NewInt(5).BigInt(), 0),
+				},
+				Transfers: map[string][]structs.EventTransfer{
+					"send": {{Amounts: []structs.TransactionAmount{ukava(1000000), util.NewAmount("usdx", "", sdk.NewInt(3000000).BigInt(), 0)}}},
+				},
+				Sender: []structs.EventTransfer{{Amounts: []structs.TransactionAmount{ukava(2000000)}}},
+			}},
+		}},
+	}
+	v.Valuate(context.Background(), tx)
+
+	if len(tx.Events) != 2 || tx.Events[1].Kind != ValuationEventKind {
+		t.Fatalf("expected valuation event, got %+v", tx.Events)
+	}
+	sub := tx.Events[1].Sub[0]
+	want := map[string]structs.TransactionAmount{
+		"fee.0":                  usd("0.01"),
+		"0.0.amount.reward":      usd("1.25"),
+		"0.0.transfers.send.0.0": usd("2.5"),
+		"0.0.transfers.send.0.1": usd("3"),
+		"0.0.sender.0.0":         usd("5"),
+	}
+	if !reflect.DeepEqual(sub.Amount, want) {
+		t.Errorf("valued amounts = %+v, want %+v", sub.Amount, want)
+	}
+	if !reflect.DeepEqual(sub.Additional, map[string][]string{"kava:usd": {"2.500000000000000000"}}) {
+		t.Errorf("prices = %+v", sub.Additional)
+	}
+
+	// every market is asked once per height
+	if prices.calls["kava:usd"] != 1 || prices.calls["hard:usd"] != 1 || prices.calls[PeggedMarket] != 0 {
+		t.Errorf("price calls = %v", prices.calls)
+	}
+
+	untouched := &structs.Transaction{Height: 10, Fee: []structs.TransactionAmount{util.NewAmount("foo", "", sdk.NewInt(1).BigInt(), 0)}}
+	v.Valuate(context.Background(), untouched)
+	if len(untouched.Events) != 0 {
+		t.Errorf("transaction without valued amounts got events %+v", untouched.Events)
+	}
+}
+
+func TestValuatePriceCache(t *testing.T) {
+	transient := errors.New("connection reset")
+	tests := []struct {
+		name      string
+		prices    *stubPrices
+		wantCalls int
+		valued    bool
+	}{
+		{
+			name:      "found price is cached",
+			prices:    &stubPrices{prices: map[string]sdk.Dec{"kava:usd": sdk.OneDec()}},
+			wantCalls: 1,
+			valued:    true,
+		},
+		{
+			name:      "missing price is cached",
+			prices:    &stubPrices{},
+			wantCalls: 1,
+		},
+		{
+			name:      "transient error is not cached",
+			prices:    &stubPrices{errs: map[string]error{"kava:usd": transient}},
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewUSDValuator(tt.prices, map[string]string{"ukava": "kava:usd"}, zap.NewNop())
+			for i := 0; i < 2; i++ {
+				tx := &structs.Transaction{Height: 10, Fee: []structs.TransactionAmount{ukava(1)}}
+				v.Valuate(context.Background(), tx)
+				if valued := len(tx.Events) == 1; valued != tt.valued {
+					t.Errorf("valued = %v, want %v", valued, tt.valued)
+				}
+			}
+			if got := tt.prices.calls["kava:usd"]; got != tt.wantCalls {
+				t.Errorf("GetPrice called %d times, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestPriceCacheEviction(t *testing.T) {
+	pc := newPriceCache(2)
+	pc.Add("a", 1, cachedPrice{ok: true})
+	pc.Add("a", 2, cachedPrice{ok: true})
+	pc.Add("a", 1, cachedPrice{})
+	pc.Add("a", 3, cachedPrice{ok: true})
+
+	if _, ok := pc.Get("a", 1); ok {
+		t.Error("the oldest entry should be evicted")
+	}
+	for _, h := range []uint64{2, 3} {
+		if p, ok := pc.Get("a", h); !ok || !p.ok {
+			t.Errorf("entry of height %d = %+v, %v", h, p, ok)
+		}
+	}
+}
+
+func TestGetPriceNotFound(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		notFound bool
+	}{
+		{name: "price", status: http.StatusOK, body: `{"height": "10", "result": {"market_id": "kava:usd", "price": "2.500000000000000000"}}`},
+		{name: "unknown market", status: http.StatusNotFound, body: `{"error": "asset not found"}`, notFound: true},
+		{name: "bad request", status: http.StatusBadRequest, body: `{"error": "no price"}`, notFound: true},
+		{name: "empty price", status: http.StatusOK, body: `{"height": "10", "result": {"market_id": "kava:usd"}}`, notFound: true},
+		{name: "unauthorized", status: http.StatusUnauthorized, body: `{"error": "unauthorized"}`},
+	}
+
+	InitMetrics()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			price, err := NewClient(srv.URL, "", zap.NewNop(), nil, 100).GetPrice(context.Background(), "kava:usd", 10)
+			if got := errors.Is(err, ErrPriceNotFound); got != tt.notFound {
+				t.Errorf("GetPrice() error = %v, not found %v, want %v", err, got, tt.notFound)
+			}
+			if tt.status == http.StatusOK && !tt.notFound && (err != nil || !price.Equal(sdk.MustNewDecFromStr("2.5"))) {
+				t.Errorf("GetPrice() = %v, %v", price, err)
+			}
+		})
+	}
+}
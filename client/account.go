@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+
+	cStructs "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/figment-networks/indexing-engine/metrics"
+	"github.com/figment-networks/indexing-engine/structs"
+	"go.uber.org/zap"
+)
+
+// ReqIDGetAccountTransactions is a task type getting transaction history of a single account
+const ReqIDGetAccountTransactions = "GetAccountTransactions"
+
+// AccountTransactionsRequest is the payload of GetAccountTransactions task
+type AccountTransactionsRequest struct {
+	Account string `json:"account"`
+	// StartHeight and EndHeight are optional bounds of history (inclusive)
+	StartHeight uint64 `json:"start_height"`
+	EndHeight   uint64 `json:"end_height"`
+	// Limit is the maximum number of transactions returned, capped by (and defaulting to) the configured maximum
+	Limit uint64 `json:"limit"`
+}
+
+// AccountTransactionsNext is the last response of GetAccountTransactions when not the whole history was returned
+type AccountTransactionsNext struct {
+	// NextHeight is the start height of the request getting the rest
+	NextHeight uint64 `json:"next_height"`
+}
+
+// GetAccountTransactions gets transactions sent or received by the account, ordered by height
+func (ic *IndexerClient) GetAccountTransactions(ctx context.Context, tr cStructs.TaskRequest, stream *cStructs.StreamAccess, client RPC) {
+	timer := metrics.NewTimer(getAccountTransactionsDuration)
+	defer timer.ObserveDuration()
+
+	atr := &AccountTransactionsRequest{}
+	err := json.Unmarshal(tr.Payload, atr)
+	if err != nil || atr.Account == "" {
+		stream.Send(cStructs.TaskResponse{
+			Id:    tr.Id,
+			Error: cStructs.TaskError{Msg: "Cannot unmarshal payload"},
+			Final: true,
+		})
+		return
+	}
+	if atr.EndHeight > 0 && atr.EndHeight < atr.StartHeight {
+		stream.Send(cStructs.TaskResponse{
+			Id:    tr.Id,
+			Error: cStructs.TaskError{Msg: "end height is lower than start height"},
+			Final: true,
+		})
+		return
+	}
+
+	limit := ic.limits.MaxAccountTransactions
+	if atr.Limit > 0 && atr.Limit < limit {
+		limit = atr.Limit
+	}
+
+	txs, next, err := client.SearchAccountTx(ctx, atr.Account, atr.StartHeight, atr.EndHeight, page, limit)
+	if err != nil {
+		ic.logger.Error("[KAVA-CLIENT] Error getting account transactions", zap.Error(err), zap.String("account", atr.Account))
+		stream.Send(cStructs.TaskResponse{
+			Id:    tr.Id,
+			Error: cStructs.TaskError{Msg: "Error getting account transactions " + err.Error()},
+			Final: true,
+		})
+		return
+	}
+
+	out := make(chan cStructs.OutResp, len(txs)+1)
+	for _, t := range txs {
+		out <- cStructs.OutResp{
+			ID:      tr.Id,
+			Type:    "Transaction",
+			Payload: ic.withLedger(structs.TransactionWithMeta{Network: "kava", ChainID: t.ChainID, Version: "0.0.1", Transaction: t}),
+		}
+	}
+	if next > 0 {
+		out <- cStructs.OutResp{
+			ID:      tr.Id,
+			Type:    "AccountTransactionsNext",
+			Payload: AccountTransactionsNext{NextHeight: next},
+		}
+	}
+	close(out)
+
+	sendResp(ctx, tr.Id, out, ic.logger, stream, nil)
+}
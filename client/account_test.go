@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	cStructs "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/google/uuid"
+)
+
+// responses reads responses of the task until the final one.
+// Streams of tests are not closed, as closing returns their response channels (possibly with unread responses) into the shared pool.
+func responses(t *testing.T, stream *cStructs.StreamAccess) (resp []cStructs.TaskResponse) {
+	t.Helper()
+	for {
+		r := <-stream.ResponseListener
+		resp = append(resp, r)
+		if r.Final {
+			return resp
+		}
+	}
+}
+
+func TestGetAccountTransactions(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     uint64
+		next      uint64
+		wantLimit uint64
+		wantTypes []string
+	}{
+		{name: "default limit", wantLimit: 1000, wantTypes: []string{"Transaction", "Transaction", "END"}},
+		{name: "lower limit", limit: 2, next: 15, wantLimit: 2, wantTypes: []string{"Transaction", "Transaction", "AccountTransactionsNext", "END"}},
+		{name: "limit over maximum", limit: 5000, wantLimit: 1000, wantTypes: []string{"Transaction", "Transaction", "END"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rpc := newFakeRPC()
+			rpc.accountTxs = []structs.Transaction{{Hash: "A", Height: 10}, {Hash: "B", Height: 12}}
+			rpc.accountNext = tt.next
+			ic := newTestClient(t, rpc, newMemStore(), Limits{})
+
+			stream := cStructs.NewStreamAccess()
+			payload, _ := json.Marshal(AccountTransactionsRequest{Account: "kava1", Limit: tt.limit})
+			ic.GetAccountTransactions(context.Background(), cStructs.TaskRequest{Id: uuid.New(), Payload: payload}, stream, rpc)
+
+			if rpc.accountLimit != tt.wantLimit {
+				t.Errorf("searched with limit %d, want %d", rpc.accountLimit, tt.wantLimit)
+			}
+			resp := responses(t, stream)
+			var types []string
+			for _, r := range resp {
+				types = append(types, r.Type)
+			}
+			if len(types) != len(tt.wantTypes) {
+				t.Fatalf("response types %v, want %v", types, tt.wantTypes)
+			}
+			for i := range types {
+				if types[i] != tt.wantTypes[i] {
+					t.Fatalf("response types %v, want %v", types, tt.wantTypes)
+				}
+			}
+			if tt.next > 0 {
+				next := AccountTransactionsNext{}
+				if err := json.Unmarshal(resp[2].Payload, &next); err != nil || next.NextHeight != tt.next {
+					t.Errorf("next height response %s, %v", resp[2].Payload, err)
+				}
+			}
+		})
+	}
+}
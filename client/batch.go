@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/figment-networks/indexing-engine/worker/store"
+)
+
+// fetchingBTX gets heights without writing them, for ranges written in batches.
+// Store session is still used for continuity checks.
+type fetchingBTX struct {
+	ic *IndexerClient
+}
+
+func (f *fetchingBTX) BlockAndTx(ctx context.Context, height uint64) (blockWM structs.BlockWithMeta, txsWM []structs.TransactionWithMeta, err error) {
+	hSess, err := f.ic.storeClient.GetSearchSession(ctx)
+	if err != nil {
+		return blockWM, nil, &stepError{ReasonStore, err}
+	}
+	return f.ic.fetchHeight(ctx, hSess, height)
+}
+
+// storeBatch buffers processed heights to write them into the store at once.
+// Heights are confirmed only after all their blocks and transactions are written,
+// when any write fails the whole batch is failed (writes are upserts, so the batch can be safely written again).
+type storeBatch struct {
+	store      store.SearchStoreCaller
+	maxHeights int
+	maxBytes   int
+
+	results []heightResult
+	bytes   int
+}
+
+func newStoreBatch(s store.SearchStoreCaller, maxHeights, maxBytes int) *storeBatch {
+	return &storeBatch{store: s, maxHeights: maxHeights, maxBytes: maxBytes}
+}
+
+// add buffers height, returning true when batch should be committed
+func (sb *storeBatch) add(r heightResult) (full bool) {
+	sb.results = append(sb.results, r)
+	if sb.maxBytes > 0 {
+		sb.bytes += resultSize(r)
+		if sb.bytes >= sb.maxBytes {
+			return true
+		}
+	}
+	return sb.maxHeights > 0 && len(sb.results) >= sb.maxHeights
+}
+
+// commit writes buffered heights retrying failed writes, returns committed heights and clears the batch
+func (sb *storeBatch) commit(ctx context.Context) (results []heightResult, retries int, err error) {
+	results = sb.results
+	sb.results, sb.bytes = nil, 0
+	if len(results) == 0 {
+		return nil, 0, nil
+	}
+
+	blocks := make([]structs.BlockWithMeta, 0, len(results))
+	var txs []structs.TransactionWithMeta
+	for _, r := range results {
+		blocks = append(blocks, r.block)
+		txs = append(txs, r.txs...)
+	}
+
+	for i := 1; ; i++ {
+		err = sb.write(ctx, blocks, txs)
+		if err == nil || i == heightMaxRetries || ctx.Err() != nil {
+			break
+		}
+		retries++
+		heightRetriesMetric.WithLabels(string(ReasonStore)).Inc()
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Duration(i) * heightRetryDelay):
+		}
+	}
+
+	if err != nil {
+		storeBatchCommitsMetric.WithLabels("error").Inc()
+		return results, retries, err
+	}
+	storeBatchCommitsMetric.WithLabels("ok").Inc()
+	storeBatchHeightsMetric.WithLabels().Observe(float64(len(results)))
+	return results, retries, nil
+}
+
+func (sb *storeBatch) write(ctx context.Context, blocks []structs.BlockWithMeta, txs []structs.TransactionWithMeta) error {
+	hSess, err := sb.store.GetSearchSession(ctx)
+	if err != nil {
+		return &stepError{ReasonStore, err}
+	}
+	return storeHeights(ctx, hSess, blocks, txs)
+}
+
+// Approximate sizes of encoded records without their variable length data
+const (
+	blockSizeEstimate = 512
+	txSizeEstimate    = 512
+	eventSizeEstimate = 256
+)
+
+// resultSize estimates the size of height's data sent to the store, without encoding it.
+// Raw transaction and its log are counted base64 encoded, every event and sub event adds a fixed size.
+func resultSize(r heightResult) int {
+	size := blockSizeEstimate
+	for _, t := range r.txs {
+		size += txSizeEstimate + base64Len(len(t.Transaction.Raw)) + base64Len(len(t.Transaction.RawLog)) + len(t.Transaction.Memo)
+		for _, ev := range t.Transaction.Events {
+			size += eventSizeEstimate * (1 + len(ev.Sub))
+		}
+	}
+	return size
+}
+
+func base64Len(n int) int {
+	return (n + 2) / 3 * 4
+}
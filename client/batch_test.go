@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/figment-networks/indexing-engine/structs"
+)
+
+func sizedResult(height uint64, raw int) heightResult {
+	return heightResult{
+		height: height,
+		block:  structs.BlockWithMeta{Block: structs.Block{Height: height}},
+		txs:    []structs.TransactionWithMeta{{Transaction: structs.Transaction{Height: height, Raw: make([]byte, raw)}}},
+	}
+}
+
+func TestStoreBatchAdd(t *testing.T) {
+	small := resultSize(sizedResult(1, 0))
+	tests := []struct {
+		name       string
+		maxHeights int
+		maxBytes   int
+		raw        int
+		// wantFull is the number of added heights that fills the batch
+		wantFull int
+	}{
+		{name: "heights", maxHeights: 3, wantFull: 3},
+		{name: "bytes", maxHeights: 10, maxBytes: 2 * small, wantFull: 2},
+		{name: "bytes of large heights", maxHeights: 10, maxBytes: 2 * small, raw: 3000, wantFull: 1},
+		{name: "heights before bytes", maxHeights: 2, maxBytes: 10 * small, wantFull: 2},
+		{name: "single height", maxHeights: 1, wantFull: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sb := newStoreBatch(newMemStore(), tt.maxHeights, tt.maxBytes)
+			for i := 1; i <= tt.wantFull; i++ {
+				full := sb.add(sizedResult(uint64(i), tt.raw))
+				if full != (i == tt.wantFull) {
+					t.Fatalf("add() of height %d returned full = %v, want full after %d", i, full, tt.wantFull)
+				}
+			}
+
+			results, _, err := sb.commit(context.Background())
+			if err != nil || len(results) != tt.wantFull {
+				t.Fatalf("commit() = %d heights, %v", len(results), err)
+			}
+			if len(sb.results) != 0 || sb.bytes != 0 {
+				t.Error("batch not cleared after commit")
+			}
+		})
+	}
+}
+
+func TestResultSize(t *testing.T) {
+	empty := resultSize(heightResult{})
+	withTx := resultSize(sizedResult(1, 0))
+	if empty <= 0 || withTx <= empty {
+		t.Fatalf("sizes of empty height %d and height with transaction %d", empty, withTx)
+	}
+	// raw bytes are counted base64 encoded
+	if got := resultSize(sizedResult(1, 300)) - withTx; got != 400 {
+		t.Errorf("300 raw bytes add %d to the size, want 400", got)
+	}
+
+	r := sizedResult(1, 0)
+	r.txs[0].Transaction.Events = structs.TransactionEvents{{Sub: []structs.SubsetEvent{{}, {}}}}
+	if got := resultSize(r) - withTx; got != 3*eventSizeEstimate {
+		t.Errorf("event with two sub events adds %d to the size, want %d", got, 3*eventSizeEstimate)
+	}
+}
+
+func TestStoreBatchCommitFailure(t *testing.T) {
+	ms := newMemStore()
+	ms.failWrites = heightMaxRetries
+	sb := newStoreBatch(ms, 10, 0)
+	for h := uint64(1); h <= 3; h++ {
+		sb.add(sizedResult(h, 0))
+	}
+
+	results, retries, err := sb.commit(context.Background())
+	if err == nil {
+		t.Fatal("expected error when every write fails")
+	}
+	if len(results) != 3 || retries != heightMaxRetries-1 {
+		t.Errorf("commit() = %d heights, %d retries", len(results), retries)
+	}
+	for h := uint64(1); h <= 3; h++ {
+		if ms.isConfirmed(h) {
+			t.Errorf("height %d confirmed after failed batch", h)
+		}
+	}
+}
+
+func TestGetRangeBatchFailure(t *testing.T) {
+	rpc := newFakeRPC()
+	for h := uint64(1); h <= 4; h++ {
+		rpc.txs[h] = 1
+	}
+	ms := newMemStore()
+	ic := newTestClient(t, rpc, ms, Limits{StoreBatchHeights: 2, RangeWorkers: 1})
+
+	// the first batch (heights 1 and 2) fails on every attempt, the second one is written
+	ms.failWrites = heightMaxRetries
+	res, err := ic.newStoringRangeRequester().GetRange(context.Background(), structs.HeightRange{StartHeight: 1, EndHeight: 4})
+
+	var re *RangeError
+	if !errors.As(err, &re) {
+		t.Fatalf("GetRange() error = %v, want RangeError", err)
+	}
+	if len(res.Failed) != 2 || res.Failed[0].Height != 1 || res.Failed[1].Height != 2 {
+		t.Fatalf("failed heights %+v, want 1 and 2", res.Failed)
+	}
+	for _, f := range res.Failed {
+		if f.Reason != ReasonStore || f.Retries != heightMaxRetries-1 {
+			t.Errorf("height %d failed with %s after %d retries", f.Height, f.Reason, f.Retries)
+		}
+	}
+	if len(res.Heights.Heights) != 2 || res.Heights.Heights[0] != 3 || res.Heights.Heights[1] != 4 {
+		t.Errorf("succeeded heights %v, want [3 4]", res.Heights.Heights)
+	}
+	for h, want := range map[uint64]bool{1: false, 2: false, 3: true, 4: true} {
+		if ms.isConfirmed(h) != want {
+			t.Errorf("height %d confirmed = %v, want %v", h, ms.isConfirmed(h), want)
+		}
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"context"
 	"encoding/json"
 	"sync"
-	"time"
 
 	"github.com/figment-networks/indexing-engine/metrics"
 
@@ -15,7 +14,6 @@ import (
 	"go.uber.org/zap"
 
 	cStructs "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
-	"github.com/figment-networks/indexing-engine/worker/process/ranged"
 	"github.com/figment-networks/indexing-engine/worker/store"
 	"github.com/figment-networks/kava-worker/api"
 )
@@ -23,10 +21,24 @@ import (
 const page = 100
 const blockchainEndpointLimit = 20
 
+const (
+	// ReqIDGetBlock is a task type getting a single block
+	ReqIDGetBlock = "GetBlock"
+	// ReqIDGetTransaction is a task type getting a single transaction by hash
+	ReqIDGetTransaction = "GetTransaction"
+)
+
+// linksCapacity is the number of recent heights kept for continuity checks
+const linksCapacity = 10000
+
 var (
 	getTransactionDuration *metrics.GroupObserver
 	getLatestDuration      *metrics.GroupObserver
 	getBlockDuration       *metrics.GroupObserver
+	getTxDuration          *metrics.GroupObserver
+
+	getAccountTransactionsDuration *metrics.GroupObserver
+	streamTransactionsDuration     *metrics.GroupObserver
 )
 
 type OutputSender interface {
@@ -35,7 +47,12 @@ type OutputSender interface {
 
 type RPC interface {
 	GetBlock(ctx context.Context, params structs.HeightHash) (block structs.Block, err error)
+	GetBlockWithParent(ctx context.Context, params structs.HeightHash) (block structs.Block, parentHash string, err error)
+	InvalidateBlock(height uint64)
+	GetTransaction(ctx context.Context, hash string) (tx structs.Transaction, err error)
+	SearchAccountTx(ctx context.Context, account string, startHeight, endHeight, perPage, limit uint64) (txs []structs.Transaction, nextHeight uint64, err error)
 	SearchTx(ctx context.Context, r structs.HeightHash, block structs.Block, perPage uint64) (txs []structs.Transaction, err error)
+	Ledger(tx structs.Transaction) ([]api.LedgerEntry, error)
 }
 
 type LCD interface {
@@ -44,6 +61,9 @@ type LCD interface {
 
 // IndexerClient is implementation of a client (main worker code)
 type IndexerClient struct {
+	// latestProcessedHeight is accessed atomically, kept first for 64-bit alignment
+	latestProcessedHeight uint64
+
 	rpcCli RPC
 	lcdCli LCD
 
@@ -51,16 +71,33 @@ type IndexerClient struct {
 	streams map[uuid.UUID]*cStructs.StreamAccess
 	sLock   sync.Mutex
 
-	Reqester            *ranged.RangeRequester
+	Reqester            *RangeRequester
 	storeClient         store.SearchStoreCaller
 	maximumHeightsToGet uint64
+
+	limits Limits
+	// taskSlots and heightSlots are global caps shared by all streams
+	taskSlots   semaphore
+	heightSlots semaphore
+
+	// Tasks is the registry of in-flight tasks
+	Tasks *TaskRegistry
+
+	draining bool
+	drainL   sync.RWMutex
+	inflight sync.WaitGroup
+
+	links *chainLinks
 }
 
 // NewIndexerClient is IndexerClient constructor
-func NewIndexerClient(ctx context.Context, logger *zap.Logger, rpcCli RPC, lcdCli LCD, storeClient store.SearchStoreCaller, maximumHeightsToGet uint64) *IndexerClient {
+func NewIndexerClient(ctx context.Context, logger *zap.Logger, rpcCli RPC, lcdCli LCD, storeClient store.SearchStoreCaller, maximumHeightsToGet uint64, limits Limits) *IndexerClient {
 	getTransactionDuration = endpointDuration.WithLabels("getTransactions")
 	getLatestDuration = endpointDuration.WithLabels("getLatest")
 	getBlockDuration = endpointDuration.WithLabels("getBlock")
+	getTxDuration = endpointDuration.WithLabels("getTransaction")
+	getAccountTransactionsDuration = endpointDuration.WithLabels("getAccountTransactions")
+	streamTransactionsDuration = endpointDuration.WithLabels("streamTransactions")
 	api.InitMetrics()
 
 	ic := &IndexerClient{
@@ -70,12 +107,37 @@ func NewIndexerClient(ctx context.Context, logger *zap.Logger, rpcCli RPC, lcdCl
 		storeClient:         storeClient,
 		maximumHeightsToGet: maximumHeightsToGet,
 		streams:             make(map[uuid.UUID]*cStructs.StreamAccess),
+		links:               newChainLinks(linksCapacity),
+		limits:              limits.withDefaults(),
+		taskSlots:           newSemaphore(limits.MaxConcurrentTasks),
+		heightSlots:         newSemaphore(limits.MaxConcurrentHeights),
+		Tasks:               NewTaskRegistry(limits.TaskTimeout, limits.TaskTimeouts),
 	}
 
-	ic.Reqester = ranged.NewRangeRequester(ic, 20)
+	ic.Reqester = ic.newStoringRangeRequester()
 	return ic
 }
 
+// newStoringRangeRequester creates range requester writing heights into the store, in batches when configured
+func (ic *IndexerClient) newStoringRangeRequester() *RangeRequester {
+	if ic.limits.StoreBatchHeights <= 1 && ic.limits.StoreBatchBytes <= 0 {
+		return ic.newRangeRequester(ic)
+	}
+	rr := ic.newRangeRequester(&fetchingBTX{ic: ic})
+	rr.Store = ic.storeClient
+	rr.BatchHeights = ic.limits.StoreBatchHeights
+	rr.BatchBytes = ic.limits.StoreBatchBytes
+	return rr
+}
+
+// newRangeRequester creates range requester with configured limits
+func (ic *IndexerClient) newRangeRequester(btx BTX) *RangeRequester {
+	rr := NewRangeRequester(btx, ic.limits.RangeWorkers)
+	rr.ChunkSize = ic.limits.RangeChunkSize
+	rr.Limiter = ic.heightSlots
+	return rr
+}
+
 // CloseStream removes stream from worker/client
 func (ic *IndexerClient) CloseStream(ctx context.Context, streamID uuid.UUID) error {
 	ic.sLock.Lock()
@@ -83,11 +145,12 @@ func (ic *IndexerClient) CloseStream(ctx context.Context, streamID uuid.UUID) er
 
 	ic.logger.Debug("[KAVA-CLIENT] Close Stream", zap.Stringer("streamID", streamID))
 	delete(ic.streams, streamID)
+	ic.Tasks.CancelStream(streamID)
 
 	return nil
 }
 
-// RegisterStream adds new listeners to the streams - currently fixed number per stream
+// RegisterStream adds new listener to the stream, processing its tasks with configured number of workers
 func (ic *IndexerClient) RegisterStream(ctx context.Context, stream *cStructs.StreamAccess) error {
 	ic.logger.Debug("[KAVA-CLIENT] Register Stream", zap.Stringer("streamID", stream.StreamID))
 	newStreamsMetric.WithLabels().Inc()
@@ -96,47 +159,130 @@ func (ic *IndexerClient) RegisterStream(ctx context.Context, stream *cStructs.St
 	defer ic.sLock.Unlock()
 	ic.streams[stream.StreamID] = stream
 
-	// Limit workers not to create new goroutines over and over again
-	for i := 0; i < 20; i++ {
-		go ic.Run(ctx, stream)
-	}
+	go ic.Run(ctx, stream)
 
 	return nil
 }
 
-// Run listens on the stream events (new tasks)
+// Run listens on the stream events (new tasks), passing them to the pool of stream workers.
+// Cancellation is served immediately, not to wait for a worker busy with the task it cancels.
 func (ic *IndexerClient) Run(ctx context.Context, stream *cStructs.StreamAccess) {
+	tasks := make(chan cStructs.TaskRequest)
+	// Limit workers not to create new goroutines over and over again
+	for i := 0; i < ic.limits.StreamWorkers; i++ {
+		go ic.runTasks(ctx, stream, tasks)
+	}
+
+	// accepted tasks waiting for a worker, up to MaxQueuedTasks
+	var queue []cStructs.TaskRequest
+	dequeue := func(id uuid.UUID) bool {
+		for i, q := range queue {
+			if q.Id != id {
+				continue
+			}
+			queue = append(queue[:i], queue[i+1:]...)
+			stream.Send(cStructs.TaskResponse{
+				Id:    q.Id,
+				Error: cStructs.TaskError{Msg: "task cancelled before start"},
+				Final: true,
+			})
+			ic.endTask()
+			return true
+		}
+		return false
+	}
+	defer func() {
+		close(tasks)
+		for range queue {
+			ic.endTask()
+		}
+	}()
+
 	for {
+		var (
+			next chan<- cStructs.TaskRequest
+			head cStructs.TaskRequest
+		)
+		if len(queue) > 0 {
+			next, head = tasks, queue[0]
+		}
+
 		select {
 		case <-ctx.Done():
 			ic.sLock.Lock()
 			delete(ic.streams, stream.StreamID)
 			ic.sLock.Unlock()
+			ic.Tasks.CancelStream(stream.StreamID)
 			return
 		case <-stream.Finish:
+			ic.Tasks.CancelStream(stream.StreamID)
 			return
+		case next <- head:
+			queue = queue[1:]
 		case taskRequest := <-stream.RequestListener:
 			receivedRequestsMetric.WithLabels(taskRequest.Type).Inc()
-			nCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
-			switch taskRequest.Type {
-			case mStructs.ReqIDGetTransactions:
-				ic.GetTransactions(nCtx, taskRequest, stream, ic.rpcCli)
-			case mStructs.ReqIDGetLatestMark:
-				ic.GetLatestMark(nCtx, taskRequest, stream, ic.rpcCli)
-			case mStructs.ReqIDGetReward:
-				ic.GetReward(nCtx, taskRequest, stream, ic.lcdCli)
-			default:
-				stream.Send(cStructs.TaskResponse{
-					Id:    taskRequest.Id,
-					Error: cStructs.TaskError{Msg: "There is no such handler " + taskRequest.Type},
-					Final: true,
-				})
+			if taskRequest.Type == ReqIDCancelTask {
+				ic.CancelTask(ctx, taskRequest, stream, dequeue)
+				continue
+			}
+			if len(queue) >= ic.limits.MaxQueuedTasks {
+				ic.rejectTask(taskRequest, stream, ErrQueueFull)
+				continue
 			}
-			cancel()
+			if !ic.beginTask() {
+				ic.rejectTask(taskRequest, stream, ErrDraining)
+				continue
+			}
+			queue = append(queue, taskRequest)
 		}
 	}
 }
 
+// runTasks processes tasks accepted by Run until the stream is done
+func (ic *IndexerClient) runTasks(ctx context.Context, stream *cStructs.StreamAccess, tasks <-chan cStructs.TaskRequest) {
+	for taskRequest := range tasks {
+		ic.runTask(ctx, stream, taskRequest)
+	}
+}
+
+// runTask processes accepted task within its timeout
+func (ic *IndexerClient) runTask(ctx context.Context, stream *cStructs.StreamAccess, taskRequest cStructs.TaskRequest) {
+	defer ic.endTask()
+	if err := ic.taskSlots.acquire(ctx); err != nil {
+		return
+	}
+	defer ic.taskSlots.release()
+
+	runningTasksMetric.WithLabels().Inc()
+	defer runningTasksMetric.WithLabels().Dec()
+
+	nCtx, finish := ic.Tasks.Start(ctx, taskRequest, stream.StreamID)
+	defer finish()
+
+	switch taskRequest.Type {
+	case mStructs.ReqIDGetTransactions:
+		ic.GetTransactions(nCtx, taskRequest, stream, ic.rpcCli)
+	case mStructs.ReqIDGetLatestMark:
+		ic.GetLatestMark(nCtx, taskRequest, stream, ic.rpcCli)
+	case mStructs.ReqIDGetReward:
+		ic.GetReward(nCtx, taskRequest, stream, ic.lcdCli)
+	case ReqIDGetBlock:
+		ic.GetBlock(nCtx, taskRequest, stream, ic.rpcCli)
+	case ReqIDGetTransaction:
+		ic.GetTransaction(nCtx, taskRequest, stream, ic.rpcCli)
+	case ReqIDGetAccountTransactions:
+		ic.GetAccountTransactions(nCtx, taskRequest, stream, ic.rpcCli)
+	case ReqIDStreamTransactions:
+		ic.StreamTransactions(nCtx, taskRequest, stream)
+	default:
+		stream.Send(cStructs.TaskResponse{
+			Id:    taskRequest.Id,
+			Error: cStructs.TaskError{Msg: "There is no such handler " + taskRequest.Type},
+			Final: true,
+		})
+	}
+}
+
 // GetBlock gets block
 func (ic *IndexerClient) GetBlock(ctx context.Context, tr cStructs.TaskRequest, stream *cStructs.StreamAccess, client RPC) {
 	timer := metrics.NewTimer(getBlockDuration)
@@ -175,6 +321,44 @@ func (ic *IndexerClient) GetBlock(ctx context.Context, tr cStructs.TaskRequest,
 	sendResp(ctx, tr.Id, out, ic.logger, stream, nil)
 }
 
+// GetTransaction gets single transaction by hash
+func (ic *IndexerClient) GetTransaction(ctx context.Context, tr cStructs.TaskRequest, stream *cStructs.StreamAccess, client RPC) {
+	timer := metrics.NewTimer(getTxDuration)
+	defer timer.ObserveDuration()
+
+	hh := &structs.HeightHash{}
+	err := json.Unmarshal(tr.Payload, hh)
+	if err != nil || hh.Hash == "" {
+		stream.Send(cStructs.TaskResponse{
+			Id:    tr.Id,
+			Error: cStructs.TaskError{Msg: "Cannot unmarshal payload"},
+			Final: true,
+		})
+		return
+	}
+
+	tx, err := client.GetTransaction(ctx, hh.Hash)
+	if err != nil {
+		ic.logger.Error("Error getting transaction", zap.Error(err), zap.String("hash", hh.Hash))
+		stream.Send(cStructs.TaskResponse{
+			Id:    tr.Id,
+			Error: cStructs.TaskError{Msg: "Error getting transaction data " + err.Error()},
+			Final: true,
+		})
+		return
+	}
+
+	out := make(chan cStructs.OutResp, 1)
+	out <- cStructs.OutResp{
+		ID:      tr.Id,
+		Type:    "Transaction",
+		Payload: ic.withLedger(structs.TransactionWithMeta{Network: "kava", ChainID: tx.ChainID, Version: "0.0.1", Transaction: tx}),
+	}
+	close(out)
+
+	sendResp(ctx, tr.Id, out, ic.logger, stream, nil)
+}
+
 // GetReward gets reward
 func (ic *IndexerClient) GetReward(ctx context.Context, tr cStructs.TaskRequest, stream *cStructs.StreamAccess, client LCD) {
 	timer := metrics.NewTimer(getBlockDuration)
@@ -0,0 +1,246 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/figment-networks/indexing-engine/worker/store"
+	"github.com/figment-networks/kava-worker/api"
+	"go.uber.org/zap"
+)
+
+// fakeRPC serves blocks of consecutive heights, hash of every block is its parent hash of the next one
+type fakeRPC struct {
+	l sync.Mutex
+
+	hashes map[uint64]string
+	// parents overrides parent hash of the height
+	parents map[uint64]string
+	txs     map[uint64]int
+	// indexed limits transactions returned by SearchTx for the first calls of the height, as if node was still indexing them
+	indexed map[uint64][]int
+	// failures is the number of consecutive errors returned by SearchTx for the height
+	failures map[uint64]int
+
+	searchCalls map[uint64]int
+	invalidated []uint64
+	accountTxs  []structs.Transaction
+	accountNext uint64
+	// accountLimit is the limit of the last account search
+	accountLimit uint64
+
+	// wait, when set, holds every block request until it's closed or request is cancelled
+	wait chan struct{}
+}
+
+func newFakeRPC() *fakeRPC {
+	return &fakeRPC{
+		hashes:      make(map[uint64]string),
+		parents:     make(map[uint64]string),
+		txs:         make(map[uint64]int),
+		indexed:     make(map[uint64][]int),
+		failures:    make(map[uint64]int),
+		searchCalls: make(map[uint64]int),
+	}
+}
+
+func (f *fakeRPC) hash(height uint64) string {
+	if h, ok := f.hashes[height]; ok {
+		return h
+	}
+	return fmt.Sprintf("HASH%d", height)
+}
+
+func (f *fakeRPC) GetBlock(ctx context.Context, params structs.HeightHash) (block structs.Block, err error) {
+	block, _, err = f.GetBlockWithParent(ctx, params)
+	return block, err
+}
+
+func (f *fakeRPC) GetBlockWithParent(ctx context.Context, params structs.HeightHash) (block structs.Block, parentHash string, err error) {
+	if f.wait != nil {
+		select {
+		case <-f.wait:
+		case <-ctx.Done():
+			return block, "", ctx.Err()
+		}
+	}
+
+	f.l.Lock()
+	defer f.l.Unlock()
+
+	if params.Height == 0 {
+		params.Height = 1000
+	}
+	parentHash, ok := f.parents[params.Height]
+	if !ok {
+		parentHash = f.hash(params.Height - 1)
+	}
+	return structs.Block{
+		Height:               params.Height,
+		Hash:                 f.hash(params.Height),
+		ChainID:              "kava-4",
+		Time:                 time.Unix(int64(params.Height), 0).UTC(),
+		NumberOfTransactions: uint64(f.txs[params.Height]),
+	}, parentHash, nil
+}
+
+func (f *fakeRPC) InvalidateBlock(height uint64) {
+	f.l.Lock()
+	defer f.l.Unlock()
+	f.invalidated = append(f.invalidated, height)
+}
+
+func (f *fakeRPC) GetTransaction(ctx context.Context, hash string) (tx structs.Transaction, err error) {
+	return tx, errors.New("not implemented")
+}
+
+func (f *fakeRPC) SearchAccountTx(ctx context.Context, account string, startHeight, endHeight, perPage, limit uint64) (txs []structs.Transaction, nextHeight uint64, err error) {
+	f.l.Lock()
+	defer f.l.Unlock()
+	f.accountLimit = limit
+	return f.accountTxs, f.accountNext, nil
+}
+
+func (f *fakeRPC) SearchTx(ctx context.Context, r structs.HeightHash, block structs.Block, perPage uint64) (txs []structs.Transaction, err error) {
+	f.l.Lock()
+	defer f.l.Unlock()
+
+	f.searchCalls[r.Height]++
+	if f.failures[r.Height] > 0 {
+		f.failures[r.Height]--
+		return nil, errors.New("node unavailable")
+	}
+
+	n := f.txs[r.Height]
+	if partial := f.indexed[r.Height]; len(partial) > 0 {
+		n, f.indexed[r.Height] = partial[0], partial[1:]
+	}
+	for i := 0; i < n; i++ {
+		txs = append(txs, structs.Transaction{Hash: fmt.Sprintf("TX%d-%d", r.Height, i), Height: r.Height, BlockHash: block.Hash, ChainID: block.ChainID})
+	}
+	return txs, nil
+}
+
+func (f *fakeRPC) Ledger(tx structs.Transaction) ([]api.LedgerEntry, error) {
+	return nil, nil
+}
+
+func (f *fakeRPC) searched(height uint64) int {
+	f.l.Lock()
+	defer f.l.Unlock()
+	return f.searchCalls[height]
+}
+
+// memStore is in-memory search store, also returning hashes of confirmed heights unless hideHashes is set
+type memStore struct {
+	l sync.Mutex
+
+	blocks    map[uint64]structs.BlockWithMeta
+	txs       map[string]structs.TransactionWithMeta
+	confirmed map[uint64]string
+	// writes is the number of calls of every method
+	writes map[string]int
+
+	hideHashes bool
+	// failWrites is the number of consecutive failing writes
+	failWrites int
+}
+
+func newMemStore() *memStore {
+	return &memStore{
+		blocks:    make(map[uint64]structs.BlockWithMeta),
+		txs:       make(map[string]structs.TransactionWithMeta),
+		confirmed: make(map[uint64]string),
+		writes:    make(map[string]int),
+	}
+}
+
+func (ms *memStore) GetSearchSession(ctx context.Context) (store.SearchStore, error) {
+	if ms.hideHashes {
+		return struct{ store.SearchStore }{ms}, nil
+	}
+	return ms, nil
+}
+
+func (ms *memStore) write(method string) error {
+	ms.writes[method]++
+	if ms.failWrites > 0 {
+		ms.failWrites--
+		return errors.New("store unavailable")
+	}
+	return nil
+}
+
+func (ms *memStore) StoreTransactions(ctx context.Context, txs []structs.TransactionWithMeta) error {
+	ms.l.Lock()
+	defer ms.l.Unlock()
+	if err := ms.write("transactions"); err != nil {
+		return err
+	}
+	for _, t := range txs {
+		ms.txs[t.Transaction.Hash] = t
+	}
+	return nil
+}
+
+func (ms *memStore) StoreBlocks(ctx context.Context, blocks []structs.BlockWithMeta) error {
+	ms.l.Lock()
+	defer ms.l.Unlock()
+	if err := ms.write("blocks"); err != nil {
+		return err
+	}
+	for _, b := range blocks {
+		ms.blocks[b.Block.Height] = b
+	}
+	return nil
+}
+
+func (ms *memStore) ConfirmHeights(ctx context.Context, heights []structs.BlockWithMeta) error {
+	ms.l.Lock()
+	defer ms.l.Unlock()
+	if err := ms.write("confirm"); err != nil {
+		return err
+	}
+	for _, b := range heights {
+		ms.confirmed[b.Block.Height] = b.Block.Hash
+	}
+	return nil
+}
+
+func (ms *memStore) GetConfirmedHash(ctx context.Context, height uint64) (hash string, ok bool, err error) {
+	ms.l.Lock()
+	defer ms.l.Unlock()
+	hash, ok = ms.confirmed[height]
+	return hash, ok, nil
+}
+
+func (ms *memStore) UnconfirmHeights(ctx context.Context, heights []uint64) error {
+	ms.l.Lock()
+	defer ms.l.Unlock()
+	for _, h := range heights {
+		delete(ms.confirmed, h)
+	}
+	return nil
+}
+
+func (ms *memStore) isConfirmed(height uint64) bool {
+	_, ok, _ := ms.GetConfirmedHash(context.Background(), height)
+	return ok
+}
+
+func (ms *memStore) numWrites(method string) int {
+	ms.l.Lock()
+	defer ms.l.Unlock()
+	return ms.writes[method]
+}
+
+// newTestClient creates client of fake node and in-memory store, writing every height separately unless limits say otherwise
+func newTestClient(t *testing.T, rpc *fakeRPC, ms *memStore, limits Limits) *IndexerClient {
+	t.Helper()
+	return NewIndexerClient(context.Background(), zap.NewNop(), rpc, nil, ms, 1000, limits)
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrHashMismatch is returned (wrapped in ForkError) when block doesn't link with the neighbouring height
+var ErrHashMismatch = errors.New("hash mismatch")
+
+// ForkError describes blocks of consecutive heights that don't link with each other.
+// Both heights should be invalidated and indexed again.
+type ForkError struct {
+	// Height of the block that was just fetched
+	Height     uint64 `json:"height"`
+	Hash       string `json:"hash"`
+	ParentHash string `json:"parent_hash"`
+	// KnownHeight is the height seen before, that the block doesn't link with
+	KnownHeight     uint64 `json:"known_height"`
+	KnownHash       string `json:"known_hash"`
+	KnownParentHash string `json:"known_parent_hash,omitempty"`
+}
+
+func (fe *ForkError) Error() string {
+	return fmt.Sprintf("%s between heights %d (%s, parent %s) and %d (%s)", ErrHashMismatch, fe.Height, fe.Hash, fe.ParentHash, fe.KnownHeight, fe.KnownHash)
+}
+
+func (fe *ForkError) Unwrap() error {
+	return ErrHashMismatch
+}
+
+// ConfirmedHashGetter may be implemented by store sessions able to return hashes of already confirmed heights.
+// Confirmations of heights involved in a fork are removed with UnconfirmHeights, so they can be indexed again.
+type ConfirmedHashGetter interface {
+	GetConfirmedHash(ctx context.Context, height uint64) (hash string, ok bool, err error)
+	UnconfirmHeights(ctx context.Context, heights []uint64) error
+}
+
+// blockLink is a hash of the block with the hash of its parent
+type blockLink struct {
+	hash       string
+	parentHash string
+}
+
+// chainLinks keeps a bounded set of block links of seen heights, to check continuity between them
+type chainLinks struct {
+	links   map[uint64]blockLink
+	heights chan uint64
+	l       sync.Mutex
+}
+
+func newChainLinks(cap int) *chainLinks {
+	return &chainLinks{
+		links:   make(map[uint64]blockLink),
+		heights: make(chan uint64, cap),
+	}
+}
+
+// check verifies the block against its neighbours and records it when they link (thread safe)
+func (cl *chainLinks) check(height uint64, link blockLink) error {
+	cl.l.Lock()
+	defer cl.l.Unlock()
+
+	if known, ok := cl.links[height]; ok && known.hash != link.hash {
+		return &ForkError{Height: height, Hash: link.hash, ParentHash: link.parentHash, KnownHeight: height, KnownHash: known.hash, KnownParentHash: known.parentHash}
+	}
+	if prev, ok := cl.links[height-1]; ok && link.parentHash != "" && prev.hash != link.parentHash {
+		return &ForkError{Height: height, Hash: link.hash, ParentHash: link.parentHash, KnownHeight: height - 1, KnownHash: prev.hash, KnownParentHash: prev.parentHash}
+	}
+	if next, ok := cl.links[height+1]; ok && next.parentHash != "" && next.parentHash != link.hash {
+		return &ForkError{Height: height, Hash: link.hash, ParentHash: link.parentHash, KnownHeight: height + 1, KnownHash: next.hash, KnownParentHash: next.parentHash}
+	}
+
+	if _, ok := cl.links[height]; ok {
+		return nil
+	}
+	cl.links[height] = link
+	select {
+	case cl.heights <- height:
+	default:
+		delete(cl.links, <-cl.heights)
+		cl.heights <- height
+	}
+	return nil
+}
+
+// forget removes links of given heights, along with their place in eviction order (thread safe)
+func (cl *chainLinks) forget(heights ...uint64) {
+	cl.l.Lock()
+	defer cl.l.Unlock()
+
+	for _, h := range heights {
+		delete(cl.links, h)
+	}
+	for i, n := 0, len(cl.heights); i < n; i++ {
+		h := <-cl.heights
+		if _, ok := cl.links[h]; ok {
+			cl.heights <- h
+		}
+	}
+}
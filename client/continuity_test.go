@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestContinuityParentMismatch(t *testing.T) {
+	rpc := newFakeRPC()
+	rpc.parents[11] = "OTHER"
+	ms := newMemStore()
+	ic := newTestClient(t, rpc, ms, Limits{})
+	ctx := context.Background()
+
+	if _, _, err := ic.BlockAndTx(ctx, 10); err != nil {
+		t.Fatalf("error processing height 10: %v", err)
+	}
+
+	_, _, err := ic.BlockAndTx(ctx, 11)
+	var fe *ForkError
+	if !errors.As(err, &fe) || !errors.Is(err, ErrHashMismatch) {
+		t.Fatalf("expected ForkError, got %v", err)
+	}
+	if fe.Height != 11 || fe.ParentHash != "OTHER" || fe.KnownHeight != 10 || fe.KnownHash != "HASH10" {
+		t.Errorf("unexpected fork %+v", fe)
+	}
+	if _, ok := ms.blocks[11]; ok || ms.isConfirmed(11) {
+		t.Error("mismatched block should not be stored")
+	}
+	if len(rpc.invalidated) != 2 || rpc.invalidated[0] != 11 || rpc.invalidated[1] != 10 {
+		t.Errorf("expected heights 11 and 10 invalidated, got %v", rpc.invalidated)
+	}
+}
+
+func TestContinuityChangedHash(t *testing.T) {
+	rpc := newFakeRPC()
+	ms := newMemStore()
+	ms.hideHashes = true
+	ic := newTestClient(t, rpc, ms, Limits{})
+	ctx := context.Background()
+
+	if _, _, err := ic.BlockAndTx(ctx, 20); err != nil {
+		t.Fatalf("error processing height 20: %v", err)
+	}
+	rpc.hashes[20] = "REORG"
+
+	_, _, err := ic.BlockAndTx(ctx, 20)
+	var fe *ForkError
+	if !errors.As(err, &fe) {
+		t.Fatalf("expected ForkError, got %v", err)
+	}
+	if fe.Height != 20 || fe.Hash != "REORG" || fe.KnownHeight != 20 || fe.KnownHash != "HASH20" {
+		t.Errorf("unexpected fork %+v", fe)
+	}
+
+	// links of both heights were forgotten, so the new hash is accepted when height is indexed again
+	if _, _, err := ic.BlockAndTx(ctx, 20); err != nil {
+		t.Errorf("error processing height again: %v", err)
+	}
+}
+
+func TestContinuityConfirmedHashes(t *testing.T) {
+	tests := []struct {
+		name        string
+		confirmed   map[uint64]string
+		hideHashes  bool
+		knownHeight uint64
+	}{
+		{name: "matching", confirmed: map[uint64]string{29: "HASH29", 30: "HASH30"}},
+		{name: "changed height", confirmed: map[uint64]string{30: "OLD30"}, knownHeight: 30},
+		{name: "changed parent", confirmed: map[uint64]string{29: "OLD29"}, knownHeight: 29},
+		{name: "store without hashes", confirmed: map[uint64]string{29: "OLD29"}, hideHashes: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := newMemStore()
+			ms.confirmed = tt.confirmed
+			ms.hideHashes = tt.hideHashes
+			ic := newTestClient(t, newFakeRPC(), ms, Limits{})
+
+			_, _, err := ic.BlockAndTx(context.Background(), 30)
+			var fe *ForkError
+			if tt.knownHeight == 0 {
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			if !errors.As(err, &fe) {
+				t.Fatalf("expected ForkError, got %v", err)
+			}
+			if fe.KnownHeight != tt.knownHeight || fe.KnownHash != "OLD"+strconv.FormatUint(tt.knownHeight, 10) {
+				t.Errorf("unexpected fork %+v", fe)
+			}
+
+			// confirmations of the fork are removed, so the height can be indexed again
+			if ms.isConfirmed(tt.knownHeight) {
+				t.Errorf("height %d is still confirmed", tt.knownHeight)
+			}
+			if _, _, err := ic.BlockAndTx(context.Background(), 30); err != nil {
+				t.Errorf("unexpected error indexing again %v", err)
+			}
+		})
+	}
+}
+
+func TestChainLinksCapacity(t *testing.T) {
+	cl := newChainLinks(2)
+	for h := uint64(1); h <= 3; h++ {
+		if err := cl.check(h, blockLink{hash: "A"}); err != nil {
+			t.Fatalf("error checking height %d: %v", h, err)
+		}
+	}
+	if _, ok := cl.links[1]; ok {
+		t.Error("the oldest height should be evicted")
+	}
+	if err := cl.check(1, blockLink{hash: "B"}); err != nil {
+		t.Errorf("evicted height should not be checked, got %v", err)
+	}
+}
+
+func TestChainLinksForget(t *testing.T) {
+	cl := newChainLinks(3)
+	for h := uint64(1); h <= 3; h++ {
+		if err := cl.check(h, blockLink{hash: "A"}); err != nil {
+			t.Fatalf("error checking height %d: %v", h, err)
+		}
+	}
+	cl.forget(2)
+	if len(cl.heights) != 2 {
+		t.Fatalf("expected forgotten height to leave eviction order, got %d heights", len(cl.heights))
+	}
+
+	// the free place is used without evicting anything
+	if err := cl.check(5, blockLink{hash: "A"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, h := range []uint64{1, 3, 5} {
+		if _, ok := cl.links[h]; !ok {
+			t.Errorf("height %d was evicted", h)
+		}
+	}
+
+	// the oldest height is evicted next, not the forgotten one
+	if err := cl.check(6, blockLink{hash: "A"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cl.links[1]; ok || len(cl.links) != 3 || len(cl.heights) != 3 {
+		t.Errorf("unexpected links %v after eviction", cl.links)
+	}
+}
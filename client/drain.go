@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	cStructs "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/figment-networks/indexing-engine/structs"
+	"go.uber.org/zap"
+)
+
+// flushTimeout is the time given to flush the store when drain deadline was already reached
+const flushTimeout = 10 * time.Second
+
+// ErrDraining is returned for work requested after drain has started
+var ErrDraining = errors.New("worker is shutting down")
+
+// Flusher may be implemented by stores buffering writes
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// beginTask registers in-flight task, returns false when client is draining
+func (ic *IndexerClient) beginTask() bool {
+	ic.drainL.RLock()
+	defer ic.drainL.RUnlock()
+
+	if ic.draining {
+		return false
+	}
+	ic.inflight.Add(1)
+	return true
+}
+
+func (ic *IndexerClient) endTask() {
+	ic.inflight.Done()
+}
+
+// StopAccepting makes client reject every new task, in-flight ones keep running
+func (ic *IndexerClient) StopAccepting() {
+	ic.drainL.Lock()
+	ic.draining = true
+	ic.drainL.Unlock()
+}
+
+// Drain stops accepting new tasks and waits for in-flight ones to finish until context is done.
+// Tasks still running at the deadline are cancelled. Store is flushed afterwards, when it buffers writes.
+func (ic *IndexerClient) Drain(ctx context.Context) (err error) {
+	ic.StopAccepting()
+
+	ic.logger.Info("[KAVA-CLIENT] Draining in-flight tasks", zap.Int("tasks", len(ic.Tasks.List())))
+
+	done := make(chan struct{})
+	go func() {
+		ic.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		ic.Tasks.CancelAll()
+		err = fmt.Errorf("in-flight tasks not finished before deadline: %w", ctx.Err())
+	}
+
+	if f, ok := ic.storeClient.(Flusher); ok {
+		fCtx := ctx
+		if ctx.Err() != nil {
+			var cancel context.CancelFunc
+			fCtx, cancel = context.WithTimeout(context.Background(), flushTimeout)
+			defer cancel()
+		}
+		if fErr := f.Flush(fCtx); fErr != nil {
+			return fmt.Errorf("error flushing store: %w", fErr)
+		}
+	}
+
+	return err
+}
+
+// GetRange processes the range as in-flight task, so it is awaited on drain
+func (ic *IndexerClient) GetRange(ctx context.Context, hr structs.HeightRange) (res HeightsResult, err error) {
+	if !ic.beginTask() {
+		return res, ErrDraining
+	}
+	defer ic.endTask()
+
+	return ic.Reqester.GetRange(ctx, hr)
+}
+
+// rejectTask responds to the task that is not accepted (while draining or with full queue)
+func (ic *IndexerClient) rejectTask(tr cStructs.TaskRequest, stream *cStructs.StreamAccess, err error) {
+	stream.Send(cStructs.TaskResponse{
+		Id:    tr.Id,
+		Error: cStructs.TaskError{Msg: err.Error()},
+		Final: true,
+	})
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cStructs "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/google/uuid"
+)
+
+// flushingStore is memStore buffering writes, recording flushes
+type flushingStore struct {
+	*memStore
+	flushed  int
+	flushErr error
+	// ctxErr is the error of flush context at the time of flush
+	ctxErr error
+}
+
+func (fs *flushingStore) Flush(ctx context.Context) error {
+	fs.flushed++
+	fs.ctxErr = ctx.Err()
+	return fs.flushErr
+}
+
+func drainAsync(ic *IndexerClient, ctx context.Context) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		done <- ic.Drain(ctx)
+	}()
+	return done
+}
+
+func TestDrainWaitsForInFlight(t *testing.T) {
+	ic := newTestClient(t, newFakeRPC(), newMemStore(), Limits{})
+	fs := &flushingStore{memStore: newMemStore()}
+	ic.storeClient = fs
+
+	if !ic.beginTask() {
+		t.Fatal("task rejected before drain")
+	}
+	done := drainAsync(ic, context.Background())
+
+	select {
+	case err := <-done:
+		t.Fatalf("Drain returned before in-flight task finished: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if ic.beginTask() {
+		t.Error("task accepted while draining")
+	}
+	if _, err := ic.GetRange(context.Background(), structs.HeightRange{StartHeight: 1, EndHeight: 2}); !errors.Is(err, ErrDraining) {
+		t.Errorf("GetRange() error = %v, want %v", err, ErrDraining)
+	}
+	if fs.flushed != 0 {
+		t.Error("store flushed before in-flight task finished")
+	}
+
+	ic.endTask()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Drain() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Drain didn't return after in-flight task finished")
+	}
+	if fs.flushed != 1 {
+		t.Errorf("store flushed %d times, want 1", fs.flushed)
+	}
+}
+
+func TestDrainDeadline(t *testing.T) {
+	ic := newTestClient(t, newFakeRPC(), newMemStore(), Limits{})
+	fs := &flushingStore{memStore: newMemStore()}
+	ic.storeClient = fs
+
+	ic.beginTask()
+	tCtx, finish := ic.Tasks.Start(context.Background(), cStructs.TaskRequest{Id: uuid.New(), Type: ReqIDGetBlock}, uuid.New())
+	defer finish()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := ic.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Drain() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	select {
+	case <-tCtx.Done():
+	default:
+		t.Error("task running at the deadline was not cancelled")
+	}
+	// store is flushed with a fresh context, deadline of drain is already reached
+	if fs.flushed != 1 || fs.ctxErr != nil {
+		t.Errorf("store flushed %d times, context error %v", fs.flushed, fs.ctxErr)
+	}
+}
+
+func TestDrainFlushError(t *testing.T) {
+	ic := newTestClient(t, newFakeRPC(), newMemStore(), Limits{})
+	flushErr := errors.New("disk full")
+	ic.storeClient = &flushingStore{memStore: newMemStore(), flushErr: flushErr}
+
+	if err := ic.Drain(context.Background()); !errors.Is(err, flushErr) {
+		t.Errorf("Drain() error = %v, want %v", err, flushErr)
+	}
+}
+
+func TestRunRejectsWhileDraining(t *testing.T) {
+	ic := newTestClient(t, newFakeRPC(), newMemStore(), Limits{})
+	ic.StopAccepting()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := cStructs.NewStreamAccess()
+	go ic.Run(ctx, stream)
+
+	id := uuid.New()
+	if err := stream.Req(cStructs.TaskRequest{Id: id, Type: ReqIDGetBlock, Payload: []byte(`{"height": 1}`)}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case resp := <-stream.ResponseListener:
+		if resp.Id != id || !resp.Final || resp.Error.Msg != ErrDraining.Error() {
+			t.Errorf("response = %+v, want draining error", resp)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no response to the task sent while draining")
+	}
+	if len(ic.Tasks.List()) != 0 {
+		t.Errorf("rejected task was started: %+v", ic.Tasks.List())
+	}
+}
@@ -0,0 +1,275 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/figment-networks/indexing-engine/structs"
+	"go.uber.org/zap"
+
+	tmhttp "github.com/tendermint/tendermint/rpc/client/http"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+const (
+	followSubscriber = "kava-worker"
+	// followIdleTimeout is the time without new blocks after which subscription is considered broken
+	followIdleTimeout    = time.Minute
+	followReconnectDelay = 5 * time.Second
+	// followIndexRetries is the number of attempts to get all transactions of the block,
+	// as transaction indexing on the node happens asynchronously after the commit
+	followIndexRetries = 3
+	followIndexDelay   = 500 * time.Millisecond
+	// followTxWait is the time new block waits for events of its transactions
+	followTxWait = 2 * time.Second
+)
+
+var errFollowIdle = errors.New("no new blocks received")
+
+// LatestProcessedHeight returns the last height indexed in follow mode
+func (ic *IndexerClient) LatestProcessedHeight() uint64 {
+	return atomic.LoadUint64(&ic.latestProcessedHeight)
+}
+
+func (ic *IndexerClient) setLatestProcessedHeight(height uint64) {
+	atomic.StoreUint64(&ic.latestProcessedHeight, height)
+	followHeightMetric.WithLabels().Set(float64(height))
+}
+
+// Follow subscribes to new blocks over the tendermint websocket and indexes every committed height.
+// Missing heights (after reconnection or dropped events) are filled starting from startHeight or the last processed height.
+// It blocks until context is done.
+func (ic *IndexerClient) Follow(ctx context.Context, remote string, startHeight uint64) {
+	if startHeight > 0 && ic.LatestProcessedHeight() == 0 {
+		ic.setLatestProcessedHeight(startHeight - 1)
+	}
+
+	for {
+		err := ic.follow(ctx, remote)
+		if ctx.Err() != nil || errors.Is(err, ErrDraining) {
+			return
+		}
+		ic.logger.Error("[KAVA-CLIENT] Follow subscription broken, reconnecting", zap.Error(err), zap.Uint64("last_height", ic.LatestProcessedHeight()))
+		followReconnectsMetric.WithLabels().Inc()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(followReconnectDelay):
+		}
+	}
+}
+
+// eventSubscriber subscribes to events of the node (implemented by tendermint websocket client)
+type eventSubscriber interface {
+	Subscribe(ctx context.Context, subscriber, query string, outCapacity ...int) (<-chan ctypes.ResultEvent, error)
+}
+
+// followedBlock is a new block waiting for the events of its transactions
+type followedBlock struct {
+	height uint64
+	numTxs uint64
+}
+
+func (ic *IndexerClient) follow(ctx context.Context, remote string) error {
+	cli, err := tmhttp.New(remote, "/websocket")
+	if err != nil {
+		return fmt.Errorf("error creating websocket client: %w", err)
+	}
+	if err := cli.Start(); err != nil {
+		return fmt.Errorf("error starting websocket client: %w", err)
+	}
+	defer cli.Stop()
+
+	ic.logger.Info("[KAVA-CLIENT] Following new blocks", zap.String("remote", remote))
+	return ic.followEvents(ctx, cli)
+}
+
+// followEvents processes new blocks, every block is processed once events of all its transactions are received
+// (or after followTxWait, as events may be dropped), followed by the next block or when subscription breaks.
+func (ic *IndexerClient) followEvents(ctx context.Context, sub eventSubscriber) error {
+	sCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	blocks, err := sub.Subscribe(sCtx, followSubscriber, tmtypes.EventQueryNewBlock.String(), 10)
+	if err != nil {
+		return fmt.Errorf("error subscribing to new blocks: %w", err)
+	}
+	txEvents, err := sub.Subscribe(sCtx, followSubscriber, tmtypes.EventQueryTx.String(), 100)
+	if err != nil {
+		return fmt.Errorf("error subscribing to transactions: %w", err)
+	}
+	cancel()
+
+	idle := time.NewTimer(followIdleTimeout)
+	defer idle.Stop()
+	// resetIdle restarts idle timeout, so time spent processing (eg. filling a long gap) doesn't count as idle
+	resetIdle := func() {
+		if !idle.Stop() {
+			select {
+			case <-idle.C:
+			default:
+			}
+		}
+		idle.Reset(followIdleTimeout)
+	}
+
+	var (
+		pending *followedBlock
+		txWait  <-chan time.Time
+		// seenTxs is the number of transaction events per height not processed yet
+		seenTxs = make(map[uint64]uint64)
+	)
+	processPending := func() error {
+		b := pending
+		pending, txWait = nil, nil
+		for h := range seenTxs {
+			if h <= b.height {
+				delete(seenTxs, h)
+			}
+		}
+		defer resetIdle()
+		return ic.processFollowed(ctx, b.height, b.numTxs)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-idle.C:
+			return errFollowIdle
+		case <-txWait:
+			if err := processPending(); err != nil {
+				return err
+			}
+		case ev := <-txEvents:
+			tx, ok := ev.Data.(tmtypes.EventDataTx)
+			if !ok {
+				continue
+			}
+			h := uint64(tx.Height)
+			seenTxs[h]++
+			if pending != nil && pending.height == h && seenTxs[h] >= pending.numTxs {
+				if err := processPending(); err != nil {
+					return err
+				}
+			}
+		case ev := <-blocks:
+			nb, ok := ev.Data.(tmtypes.EventDataNewBlock)
+			if !ok || nb.Block == nil {
+				continue
+			}
+			if pending != nil {
+				if err := processPending(); err != nil {
+					return err
+				}
+			}
+			pending = &followedBlock{height: uint64(nb.Block.Height), numTxs: uint64(len(nb.Block.Data.Txs))}
+			if seenTxs[pending.height] >= pending.numTxs {
+				if err := processPending(); err != nil {
+					return err
+				}
+			} else {
+				txWait = time.After(followTxWait)
+			}
+			resetIdle()
+		}
+	}
+}
+
+// processFollowed indexes new height, filling the gap after the last processed one first.
+// Height is written into the store only after all its transactions are indexed by the node.
+func (ic *IndexerClient) processFollowed(ctx context.Context, height, numTxs uint64) error {
+	if !ic.beginTask() {
+		return ErrDraining
+	}
+	defer ic.endTask()
+
+	last := ic.LatestProcessedHeight()
+	if height <= last {
+		return nil
+	}
+
+	if last > 0 && height > last+1 {
+		if err := ic.fillGap(ctx, last+1, height-1); err != nil {
+			return err
+		}
+	}
+
+	hSess, err := ic.storeClient.GetSearchSession(ctx)
+	if err != nil {
+		return fmt.Errorf("error processing height %d: %w", height, err)
+	}
+	for i := 1; ; i++ {
+		blockWM, txs, err := ic.fetchHeight(ctx, hSess, height)
+		if err != nil {
+			return fmt.Errorf("error processing height %d: %w", height, err)
+		}
+		if uint64(len(txs)) >= numTxs {
+			if err := storeHeights(ctx, hSess, []structs.BlockWithMeta{blockWM}, txs); err != nil {
+				return fmt.Errorf("error processing height %d: %w", height, err)
+			}
+			break
+		}
+		if i == followIndexRetries {
+			return fmt.Errorf("error processing height %d: got %d of %d transactions", height, len(txs), numTxs)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(followIndexDelay):
+		}
+	}
+
+	ic.setLatestProcessedHeight(height)
+	ic.logger.Debug("[KAVA-CLIENT] Followed height", zap.Uint64("height", height), zap.Uint64("txs", numTxs))
+	return nil
+}
+
+// fillGap indexes missed heights in chunks of maximumHeightsToGet.
+// The latest processed height moves after every chunk, up to the first height that failed,
+// so the next attempt continues from there.
+func (ic *IndexerClient) fillGap(ctx context.Context, start, end uint64) error {
+	ic.logger.Info("[KAVA-CLIENT] Filling follow gap", zap.Uint64("start", start), zap.Uint64("end", end))
+
+	chunk := ic.maximumHeightsToGet
+	if chunk == 0 {
+		chunk = end - start + 1
+	}
+	for from := start; ; from += chunk {
+		to := from + chunk - 1
+		if to > end || to < from {
+			to = end
+		}
+
+		res, err := ic.Reqester.GetRange(ctx, structs.HeightRange{StartHeight: from, EndHeight: to})
+		if done := contiguousEnd(from, res.Heights.Heights); done >= from {
+			ic.setLatestProcessedHeight(done)
+		}
+		if err == nil && ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			return fmt.Errorf("error filling gap %d-%d: %w", from, to, err)
+		}
+		if to == end {
+			return nil
+		}
+	}
+}
+
+// contiguousEnd returns the last height of sorted heights following start without a gap (start-1 when start is missing)
+func contiguousEnd(start uint64, heights []uint64) uint64 {
+	next := start
+	for _, h := range heights {
+		if h != next {
+			break
+		}
+		next++
+	}
+	return next - 1
+}
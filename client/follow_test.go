@@ -0,0 +1,227 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+// fakeSubscriber delivers events sent to the channel of the query
+type fakeSubscriber struct {
+	l      sync.Mutex
+	events map[string]chan ctypes.ResultEvent
+}
+
+func newFakeSubscriber() *fakeSubscriber {
+	return &fakeSubscriber{events: map[string]chan ctypes.ResultEvent{
+		tmtypes.EventQueryNewBlock.String(): make(chan ctypes.ResultEvent, 10),
+		tmtypes.EventQueryTx.String():       make(chan ctypes.ResultEvent, 10),
+	}}
+}
+
+func (fs *fakeSubscriber) Subscribe(ctx context.Context, subscriber, query string, outCapacity ...int) (<-chan ctypes.ResultEvent, error) {
+	fs.l.Lock()
+	defer fs.l.Unlock()
+	return fs.events[query], nil
+}
+
+func (fs *fakeSubscriber) newBlock(height int64, numTxs int) {
+	fs.events[tmtypes.EventQueryNewBlock.String()] <- ctypes.ResultEvent{Data: tmtypes.EventDataNewBlock{Block: &tmtypes.Block{
+		Header: tmtypes.Header{Height: height},
+		Data:   tmtypes.Data{Txs: make(tmtypes.Txs, numTxs)},
+	}}}
+}
+
+func (fs *fakeSubscriber) tx(height int64) {
+	fs.events[tmtypes.EventQueryTx.String()] <- ctypes.ResultEvent{Data: tmtypes.EventDataTx{TxResult: tmtypes.TxResult{Height: height}}}
+}
+
+// startFollow runs followEvents until the test ends
+func startFollow(t *testing.T, ic *IndexerClient, fs *fakeSubscriber) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		ic.followEvents(ctx, fs)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func waitProcessed(t *testing.T, ic *IndexerClient, height uint64, within time.Duration) {
+	t.Helper()
+	deadline := time.Now().Add(within)
+	for ic.LatestProcessedHeight() < height {
+		if time.Now().After(deadline) {
+			t.Fatalf("height %d not processed, the last one is %d", height, ic.LatestProcessedHeight())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestFollowFillsGap(t *testing.T) {
+	rpc := newFakeRPC()
+	ms := newMemStore()
+	ic := newTestClient(t, rpc, ms, Limits{})
+	ic.setLatestProcessedHeight(4)
+
+	fs := newFakeSubscriber()
+	startFollow(t, ic, fs)
+	fs.newBlock(8, 0)
+	waitProcessed(t, ic, 8, 5*time.Second)
+
+	for h := uint64(5); h <= 8; h++ {
+		if !ms.isConfirmed(h) {
+			t.Errorf("height %d not confirmed", h)
+		}
+	}
+}
+
+func TestFollowGapCheckpoints(t *testing.T) {
+	rpc := newFakeRPC()
+	rpc.txs[6], rpc.txs[9] = 1, 1
+	rpc.failures[9] = 100
+	ms := newMemStore()
+	ic := newTestClient(t, rpc, ms, Limits{})
+	ic.maximumHeightsToGet = 3
+	ic.setLatestProcessedHeight(4)
+	ctx := context.Background()
+
+	// gap 5-14 is filled in chunks, the failed height stops it after the last contiguous one
+	if err := ic.processFollowed(ctx, 15, 0); err == nil {
+		t.Fatal("expected error of failed height")
+	}
+	if got := ic.LatestProcessedHeight(); got != 8 {
+		t.Errorf("latest processed height %d, want 8", got)
+	}
+	if ms.isConfirmed(11) {
+		t.Error("chunk after the failed one was processed")
+	}
+
+	rpc.l.Lock()
+	rpc.failures[9] = 0
+	rpc.l.Unlock()
+
+	// the next attempt continues from the failed height
+	if err := ic.processFollowed(ctx, 15, 0); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got := ic.LatestProcessedHeight(); got != 15 {
+		t.Errorf("latest processed height %d, want 15", got)
+	}
+	if got := rpc.searched(6); got != 1 {
+		t.Errorf("height 6 searched %d times, want 1", got)
+	}
+	for h := uint64(5); h <= 15; h++ {
+		if !ms.isConfirmed(h) {
+			t.Errorf("height %d not confirmed", h)
+		}
+	}
+}
+
+func TestContiguousEnd(t *testing.T) {
+	tests := []struct {
+		heights []uint64
+		want    uint64
+	}{
+		{heights: []uint64{5, 6, 7}, want: 7},
+		{heights: []uint64{5, 6, 8}, want: 6},
+		{heights: []uint64{6, 7}, want: 4},
+		{want: 4},
+	}
+	for _, tt := range tests {
+		if got := contiguousEnd(5, tt.heights); got != tt.want {
+			t.Errorf("contiguousEnd(5, %v) = %d, want %d", tt.heights, got, tt.want)
+		}
+	}
+}
+
+func TestFollowWaitsForTxEvents(t *testing.T) {
+	rpc := newFakeRPC()
+	rpc.txs[10], rpc.txs[11], rpc.txs[12] = 2, 1, 1
+	ms := newMemStore()
+	ic := newTestClient(t, rpc, ms, Limits{})
+	ic.setLatestProcessedHeight(9)
+
+	fs := newFakeSubscriber()
+	startFollow(t, ic, fs)
+
+	fs.newBlock(10, 2)
+	fs.tx(10)
+	time.Sleep(50 * time.Millisecond)
+	if ic.LatestProcessedHeight() != 9 {
+		t.Fatal("height processed before events of all its transactions")
+	}
+	fs.tx(10)
+	waitProcessed(t, ic, 10, time.Second)
+
+	// events of transactions may come before the block
+	fs.tx(11)
+	time.Sleep(10 * time.Millisecond)
+	fs.newBlock(11, 1)
+	waitProcessed(t, ic, 11, time.Second)
+
+	// missing events don't hold the block after the next one arrives
+	fs.newBlock(12, 1)
+	fs.newBlock(13, 0)
+	waitProcessed(t, ic, 13, time.Second)
+
+	for h := uint64(10); h <= 13; h++ {
+		if !ms.isConfirmed(h) {
+			t.Errorf("height %d not confirmed", h)
+		}
+	}
+}
+
+func TestProcessFollowedRetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		indexed    []int
+		wantErr    bool
+		wantSearch int
+	}{
+		{name: "all transactions indexed", wantSearch: 1},
+		{name: "transactions indexed on retry", indexed: []int{1, 2}, wantSearch: 3},
+		{name: "transactions never indexed", indexed: []int{0, 1, 2}, wantErr: true, wantSearch: followIndexRetries},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rpc := newFakeRPC()
+			rpc.txs[10] = 3
+			rpc.indexed[10] = tt.indexed
+			ms := newMemStore()
+			ic := newTestClient(t, rpc, ms, Limits{})
+
+			err := ic.processFollowed(context.Background(), 10, 3)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("processFollowed() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := rpc.searched(10); got != tt.wantSearch {
+				t.Errorf("transactions searched %d times, want %d", got, tt.wantSearch)
+			}
+
+			// partial heights are never written
+			wantWrites := 1
+			if tt.wantErr {
+				wantWrites = 0
+			}
+			if got := ms.numWrites("blocks"); got != wantWrites {
+				t.Errorf("blocks written %d times, want %d", got, wantWrites)
+			}
+			if ms.isConfirmed(10) == tt.wantErr {
+				t.Errorf("height confirmed = %v", ms.isConfirmed(10))
+			}
+			if !tt.wantErr && (len(ms.txs) != 3 || ic.LatestProcessedHeight() != 10) {
+				t.Errorf("stored %d transactions, latest height %d", len(ms.txs), ic.LatestProcessedHeight())
+			}
+		})
+	}
+}
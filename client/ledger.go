@@ -0,0 +1,24 @@
+package client
+
+import (
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/figment-networks/kava-worker/api"
+
+	"go.uber.org/zap"
+)
+
+// TransactionWithLedger is a transaction sent back to the manager, along with its transfer ledger.
+// Ledger is kept apart from transaction events, so it's not mistaken for a message.
+type TransactionWithLedger struct {
+	structs.TransactionWithMeta
+	Ledger []api.LedgerEntry `json:"ledger,omitempty"`
+}
+
+// withLedger produces ledger of the transaction. Transaction is sent without ledger when it cannot be produced
+func (ic *IndexerClient) withLedger(tx structs.TransactionWithMeta) TransactionWithLedger {
+	ledger, err := ic.rpcCli.Ledger(tx.Transaction)
+	if err != nil {
+		ic.logger.Error("[KAVA-CLIENT] Problem producing transfer ledger", zap.Error(err), zap.String("hash", tx.Transaction.Hash))
+	}
+	return TransactionWithLedger{TransactionWithMeta: tx, Ledger: ledger}
+}
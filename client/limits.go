@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/figment-networks/indexing-engine/structs"
+)
+
+// queuedTasksPerSlot is the number of tasks queued per concurrent task when MaxQueuedTasks is not set
+const queuedTasksPerSlot = 10
+
+// ErrQueueFull is returned for tasks requested when too many of them are waiting for a worker
+var ErrQueueFull = errors.New("too many tasks waiting, try again later")
+
+// Limits of client concurrency
+type Limits struct {
+	// StreamWorkers is the number of tasks processed concurrently for every stream
+	StreamWorkers int
+	// RangeWorkers is the number of heights processed concurrently for every range
+	RangeWorkers int
+	// RangeChunkSize is the number of heights of range scheduled at once
+	RangeChunkSize uint64
+
+	// StoreBatchHeights and StoreBatchBytes limit heights of range written into the store at once (0 for both - every height is written separately)
+	StoreBatchHeights int
+	StoreBatchBytes   int
+
+	// MaxConcurrentTasks caps tasks processed concurrently across all streams (0 - no limit)
+	MaxConcurrentTasks int
+	// MaxConcurrentHeights caps heights processed concurrently across all ranges (0 - no limit)
+	MaxConcurrentHeights int
+	// MaxQueuedTasks caps tasks of every stream waiting for a worker, tasks over the cap are rejected
+	// (0 - MaxConcurrentTasks, or StreamWorkers when tasks are not capped, times queuedTasksPerSlot)
+	MaxQueuedTasks int
+
+	// MaxAccountTransactions caps transactions returned by a single GetAccountTransactions task
+	MaxAccountTransactions uint64
+
+	// TaskTimeout is the timeout of task types not present in TaskTimeouts
+	TaskTimeout  time.Duration
+	TaskTimeouts map[string]time.Duration
+}
+
+// DefaultLimits are limits used when not configured
+var DefaultLimits = Limits{
+	StreamWorkers:        20,
+	RangeWorkers:         20,
+	RangeChunkSize:       100,
+	MaxConcurrentTasks:   40,
+	MaxConcurrentHeights: 40,
+
+	MaxAccountTransactions: 1000,
+	TaskTimeout:            DefaultTaskTimeout,
+}
+
+func (l Limits) withDefaults() Limits {
+	if l.StreamWorkers <= 0 {
+		l.StreamWorkers = DefaultLimits.StreamWorkers
+	}
+	if l.RangeWorkers <= 0 {
+		l.RangeWorkers = DefaultLimits.RangeWorkers
+	}
+	if l.RangeChunkSize == 0 {
+		l.RangeChunkSize = DefaultLimits.RangeChunkSize
+	}
+	if l.MaxQueuedTasks <= 0 {
+		l.MaxQueuedTasks = l.MaxConcurrentTasks * queuedTasksPerSlot
+		if l.MaxConcurrentTasks <= 0 {
+			l.MaxQueuedTasks = l.StreamWorkers * queuedTasksPerSlot
+		}
+	}
+	if l.MaxAccountTransactions == 0 {
+		l.MaxAccountTransactions = DefaultLimits.MaxAccountTransactions
+	}
+	return l
+}
+
+// semaphore limits number of concurrent operations, nil semaphore doesn't limit anything
+type semaphore chan struct{}
+
+func newSemaphore(n int) semaphore {
+	if n <= 0 {
+		return nil
+	}
+	return make(semaphore, n)
+}
+
+func (s semaphore) acquire(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+	select {
+	case s <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s semaphore) release() {
+	if s != nil {
+		<-s
+	}
+}
+
+// checkRange validates range against maximum number of heights per request
+func (ic *IndexerClient) checkRange(hr structs.HeightRange) error {
+	if hr.EndHeight == 0 {
+		return fmt.Errorf("end height is zero")
+	}
+	if hr.EndHeight < hr.StartHeight {
+		return fmt.Errorf("end height %d is lower than start height %d", hr.EndHeight, hr.StartHeight)
+	}
+	if n := hr.EndHeight - hr.StartHeight + 1; ic.maximumHeightsToGet > 0 && n > ic.maximumHeightsToGet {
+		return fmt.Errorf("range too large: %d heights requested, maximum is %d", n, ic.maximumHeightsToGet)
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	cStructs "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func TestCheckRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		max     uint64
+		hr      structs.HeightRange
+		wantErr string
+	}{
+		{name: "within limit", max: 10, hr: structs.HeightRange{StartHeight: 1, EndHeight: 10}},
+		{name: "single height", max: 1, hr: structs.HeightRange{StartHeight: 5, EndHeight: 5}},
+		{name: "over limit", max: 10, hr: structs.HeightRange{StartHeight: 1, EndHeight: 11}, wantErr: "range too large: 11 heights requested, maximum is 10"},
+		{name: "no limit", hr: structs.HeightRange{StartHeight: 1, EndHeight: 1000000}},
+		{name: "zero end", max: 10, hr: structs.HeightRange{StartHeight: 1}, wantErr: "end height is zero"},
+		{name: "end before start", max: 10, hr: structs.HeightRange{StartHeight: 5, EndHeight: 4}, wantErr: "lower than start height"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ic := &IndexerClient{maximumHeightsToGet: tt.max}
+			err := ic.checkRange(tt.hr)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkRange() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkRange() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetTransactionsRangeLimit(t *testing.T) {
+	rpc := newFakeRPC()
+	ms := newMemStore()
+	ic := NewIndexerClient(context.Background(), zap.NewNop(), rpc, nil, ms, 5, Limits{})
+
+	stream := cStructs.NewStreamAccess()
+	ic.GetTransactions(context.Background(), cStructs.TaskRequest{Id: uuid.New(), Payload: []byte(`{"StartHeight": 1, "EndHeight": 6}`)}, stream, rpc)
+
+	resp := <-stream.ResponseListener
+	if !resp.Final || !strings.Contains(resp.Error.Msg, "range too large") {
+		t.Errorf("response %+v, want range error", resp)
+	}
+	if ms.numWrites("blocks") != 0 {
+		t.Error("heights of rejected range were processed")
+	}
+}
+
+func TestLimitsDefaults(t *testing.T) {
+	l := Limits{RangeWorkers: 3}.withDefaults()
+	if l.RangeWorkers != 3 || l.StreamWorkers != DefaultLimits.StreamWorkers || l.RangeChunkSize != DefaultLimits.RangeChunkSize || l.MaxAccountTransactions != DefaultLimits.MaxAccountTransactions {
+		t.Errorf("withDefaults() = %+v", l)
+	}
+	// global caps stay disabled
+	if l.MaxConcurrentTasks != 0 || l.MaxConcurrentHeights != 0 {
+		t.Errorf("withDefaults() set global caps: %+v", l)
+	}
+	// queue is derived from concurrency
+	if l.MaxQueuedTasks != DefaultLimits.StreamWorkers*queuedTasksPerSlot {
+		t.Errorf("withDefaults() queued tasks %d", l.MaxQueuedTasks)
+	}
+	if l = (Limits{MaxConcurrentTasks: 4}).withDefaults(); l.MaxQueuedTasks != 4*queuedTasksPerSlot {
+		t.Errorf("withDefaults() queued tasks %d with capped tasks", l.MaxQueuedTasks)
+	}
+	if l = (Limits{MaxQueuedTasks: 7}).withDefaults(); l.MaxQueuedTasks != 7 {
+		t.Errorf("withDefaults() changed queued tasks to %d", l.MaxQueuedTasks)
+	}
+}
+
+func TestSemaphore(t *testing.T) {
+	ctx := context.Background()
+
+	unlimited := newSemaphore(0)
+	for i := 0; i < 100; i++ {
+		if err := unlimited.acquire(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	unlimited.release()
+
+	s := newSemaphore(2)
+	if err := s.acquire(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.acquire(ctx); err != nil {
+		t.Fatal(err)
+	}
+	cCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := s.acquire(cCtx); err == nil {
+		t.Fatal("acquired over the limit")
+	}
+	s.release()
+	if err := s.acquire(ctx); err != nil {
+		t.Errorf("acquire() after release error = %v", err)
+	}
+}
+
+// concurrencyBTX records the maximum number of heights processed at once
+type concurrencyBTX struct {
+	l       sync.Mutex
+	current int
+	max     int
+}
+
+func (c *concurrencyBTX) BlockAndTx(ctx context.Context, height uint64) (blockWM structs.BlockWithMeta, txsWM []structs.TransactionWithMeta, err error) {
+	c.l.Lock()
+	c.current++
+	if c.current > c.max {
+		c.max = c.current
+	}
+	c.l.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	c.l.Lock()
+	c.current--
+	c.l.Unlock()
+	blockWM.Block.Height = height
+	return blockWM, nil, nil
+}
+
+func TestGlobalHeightsLimit(t *testing.T) {
+	btx := &concurrencyBTX{}
+	limiter := newSemaphore(3)
+
+	// ranges share the limiter, so their workers together don't exceed it
+	wg := sync.WaitGroup{}
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rr := NewRangeRequester(btx, 4)
+			rr.Limiter = limiter
+			start := uint64(i*20 + 1)
+			if _, err := rr.GetRange(context.Background(), structs.HeightRange{StartHeight: start, EndHeight: start + 19}); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if btx.max > 3 {
+		t.Errorf("%d heights processed at once, limit is 3", btx.max)
+	}
+}
+
+func TestGlobalTasksLimit(t *testing.T) {
+	rpc := newFakeRPC()
+	ic := newTestClient(t, rpc, newMemStore(), Limits{MaxConcurrentTasks: 1, StreamWorkers: 2})
+
+	// the only slot is taken, so tasks of the stream wait for it
+	if err := ic.taskSlots.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := cStructs.NewStreamAccess()
+	if err := ic.RegisterStream(ctx, stream); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Req(cStructs.TaskRequest{Id: uuid.New(), Type: ReqIDGetBlock, Payload: []byte(`{"height": 1}`)}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case resp := <-stream.ResponseListener:
+		t.Fatalf("task processed over the limit: %+v", resp)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	ic.taskSlots.release()
+	select {
+	case <-stream.ResponseListener:
+	case <-time.After(5 * time.Second):
+		t.Fatal("task not processed after slot was released")
+	}
+}
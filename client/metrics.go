@@ -33,4 +33,63 @@ var (
 		Desc:      "Responses to be sent from client",
 		Tags:      []string{"type", "final"},
 	})
+
+	followHeightMetric = metrics.MustNewGaugeWithTags(metrics.Options{
+		Namespace: "indexers",
+		Subsystem: "worker_client_cosmos",
+		Name:      "follow_height",
+		Desc:      "Latest height processed in follow mode",
+	})
+
+	followReconnectsMetric = metrics.MustNewCounterWithTags(metrics.Options{
+		Namespace: "indexers",
+		Subsystem: "worker_client_cosmos",
+		Name:      "follow_reconnects",
+		Desc:      "Reconnections of follow mode subscription",
+	})
+
+	hashMismatchMetric = metrics.MustNewCounterWithTags(metrics.Options{
+		Namespace: "indexers",
+		Subsystem: "worker_client_cosmos",
+		Name:      "hash_mismatch",
+		Desc:      "Blocks not linking with neighbouring heights",
+	})
+
+	heightRetriesMetric = metrics.MustNewCounterWithTags(metrics.Options{
+		Namespace: "indexers",
+		Subsystem: "worker_client_cosmos",
+		Name:      "height_retries",
+		Desc:      "Retries of processing heights",
+		Tags:      []string{"reason"},
+	})
+
+	runningTasksMetric = metrics.MustNewGaugeWithTags(metrics.Options{
+		Namespace: "indexers",
+		Subsystem: "worker_client_cosmos",
+		Name:      "running_tasks",
+		Desc:      "Tasks processed at the moment across all streams",
+	})
+
+	storeDuration = metrics.MustNewHistogramWithTags(metrics.HistogramOptions{
+		Namespace: "indexers",
+		Subsystem: "worker_client_cosmos",
+		Name:      "store_duration",
+		Desc:      "Duration of writes into the search store",
+		Tags:      []string{"operation"},
+	})
+
+	storeBatchHeightsMetric = metrics.MustNewHistogramWithTags(metrics.HistogramOptions{
+		Namespace: "indexers",
+		Subsystem: "worker_client_cosmos",
+		Name:      "store_batch_heights",
+		Desc:      "Number of heights written into the search store in single batch",
+	})
+
+	storeBatchCommitsMetric = metrics.MustNewCounterWithTags(metrics.Options{
+		Namespace: "indexers",
+		Subsystem: "worker_client_cosmos",
+		Name:      "store_batch_commits",
+		Desc:      "Commits of batched heights into the search store",
+		Tags:      []string{"status"},
+	})
 )
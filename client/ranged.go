@@ -0,0 +1,299 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/figment-networks/indexing-engine/worker/store"
+)
+
+const (
+	heightMaxRetries = 3
+	heightRetryDelay = 500 * time.Millisecond
+)
+
+// HeightErrorReason is a type of failure of processing height
+type HeightErrorReason string
+
+const (
+	ReasonFetchBlock        HeightErrorReason = "fetch_block"
+	ReasonFetchTransactions HeightErrorReason = "fetch_transactions"
+	ReasonStore             HeightErrorReason = "store"
+	ReasonHashMismatch      HeightErrorReason = "hash_mismatch"
+	ReasonCanceled          HeightErrorReason = "canceled"
+	ReasonUnknown           HeightErrorReason = "unknown"
+)
+
+// HeightError is a failure of processing height
+type HeightError struct {
+	Height  uint64            `json:"height"`
+	Reason  HeightErrorReason `json:"reason"`
+	Message string            `json:"message"`
+	Retries int               `json:"retries"`
+	// Fork is set for hash mismatches
+	Fork *ForkError `json:"fork,omitempty"`
+}
+
+// HeightsResult is a result of processing range - heights that succeeded and the ones that failed.
+// It is a superset of structs.Heights (ErrorAt lists failed heights).
+type HeightsResult struct {
+	structs.Heights
+
+	Failed []HeightError `json:"failed,omitempty"`
+	// Retried is the number of retries done in the whole range
+	Retried uint64 `json:"retried"`
+}
+
+// RangeError aggregates failures of heights in range
+type RangeError struct {
+	Failed []HeightError
+}
+
+func (re *RangeError) Error() string {
+	s := strings.Builder{}
+	fmt.Fprintf(&s, "%d heights failed:", len(re.Failed))
+	for i, he := range re.Failed {
+		if i == 10 {
+			fmt.Fprintf(&s, " (and %d more)", len(re.Failed)-i)
+			break
+		}
+		fmt.Fprintf(&s, " %d [%s] %s;", he.Height, he.Reason, he.Message)
+	}
+	return s.String()
+}
+
+// stepError is an error of BlockAndTx with the step it failed at
+type stepError struct {
+	reason HeightErrorReason
+	err    error
+}
+
+func (se *stepError) Error() string {
+	return se.err.Error()
+}
+
+func (se *stepError) Unwrap() error {
+	return se.err
+}
+
+// errorReason classifies error of BlockAndTx
+func errorReason(err error) HeightErrorReason {
+	var fe *ForkError
+	var se *stepError
+	switch {
+	case errors.As(err, &fe):
+		return ReasonHashMismatch
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return ReasonCanceled
+	case errors.As(err, &se):
+		return se.reason
+	}
+	return ReasonUnknown
+}
+
+// BTX is the processor of a single height
+type BTX interface {
+	BlockAndTx(ctx context.Context, height uint64) (blockWM structs.BlockWithMeta, txsWM []structs.TransactionWithMeta, err error)
+}
+
+// RangeRequester processes ranges of heights concurrently.
+// Failed heights are retried and then aggregated, without aborting the rest of the range.
+type RangeRequester struct {
+	BTX BTX
+	// ChunkSize is the number of heights scheduled at once (0 - whole range)
+	ChunkSize uint64
+	// Limiter optionally caps heights processed concurrently, shared with other requesters
+	Limiter semaphore
+	// OnHeight is optionally called after every processed height (succeeded or not)
+	OnHeight func(height uint64)
+
+	// Store, when set, receives heights of BTX (that shouldn't write them itself) in batches
+	// of BatchHeights heights or BatchBytes bytes, whichever is reached first.
+	// Heights succeed only after their batch is committed.
+	Store        store.SearchStoreCaller
+	BatchHeights int
+	BatchBytes   int
+
+	workers int
+}
+
+// NewRangeRequester is RangeRequester constructor
+func NewRangeRequester(btx BTX, workers int) *RangeRequester {
+	return &RangeRequester{BTX: btx, workers: workers}
+}
+
+type heightResult struct {
+	height  uint64
+	block   structs.BlockWithMeta
+	txs     []structs.TransactionWithMeta
+	err     error
+	retries int
+}
+
+// GetRange gets given range of blocks and transactions.
+// Range is processed chunk by chunk, every chunk is finished before the next one starts.
+// Error is of *RangeError type, when any of heights failed.
+func (rr *RangeRequester) GetRange(ctx context.Context, hr structs.HeightRange) (res HeightsResult, err error) {
+	if hr.EndHeight < hr.StartHeight {
+		return res, fmt.Errorf("wrong range %d-%d", hr.StartHeight, hr.EndHeight)
+	}
+
+	chunk := rr.ChunkSize
+	if chunk == 0 {
+		chunk = hr.EndHeight - hr.StartHeight + 1
+	}
+
+	for start := hr.StartHeight; ; start += chunk {
+		end := start + chunk - 1
+		if end > hr.EndHeight || end < start {
+			end = hr.EndHeight
+		}
+		rr.getChunk(ctx, start, end, &res)
+		if end == hr.EndHeight {
+			break
+		}
+	}
+
+	sort.Slice(res.Heights.Heights, func(i, j int) bool { return res.Heights.Heights[i] < res.Heights.Heights[j] })
+	sort.Slice(res.ErrorAt, func(i, j int) bool { return res.ErrorAt[i] < res.ErrorAt[j] })
+	sort.Slice(res.Failed, func(i, j int) bool { return res.Failed[i].Height < res.Failed[j].Height })
+
+	if len(res.Failed) > 0 {
+		return res, &RangeError{Failed: res.Failed}
+	}
+	return res, nil
+}
+
+// getChunk processes heights between start and end (inclusive) with the pool of workers
+func (rr *RangeRequester) getChunk(ctx context.Context, start, end uint64, res *HeightsResult) {
+	heights := make(chan uint64)
+	out := make(chan heightResult, rr.workers)
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < rr.workers; i++ {
+		wg.Add(1)
+		go rr.asyncBlockAndTx(ctx, wg, heights, out)
+	}
+
+	go func() {
+	POPULATE:
+		for h := start; h <= end; h++ {
+			select {
+			case heights <- h:
+			case <-ctx.Done():
+				break POPULATE
+			}
+		}
+		close(heights)
+		wg.Wait()
+		close(out)
+	}()
+
+	var batch *storeBatch
+	if rr.Store != nil {
+		batch = newStoreBatch(rr.Store, rr.BatchHeights, rr.BatchBytes)
+	}
+
+	done := make([]bool, end-start+1)
+	for r := range out {
+		done[r.height-start] = true
+		if rr.OnHeight != nil {
+			rr.OnHeight(r.height)
+		}
+		res.Retried += uint64(r.retries)
+		if r.err != nil {
+			res.fail(r.height, r.err, r.retries)
+			continue
+		}
+		if batch == nil {
+			res.assign(r)
+			continue
+		}
+		if batch.add(r) {
+			rr.commit(ctx, batch, res)
+		}
+	}
+	if batch != nil {
+		rr.commit(ctx, batch, res)
+	}
+
+	// heights never processed because of cancellation
+	for i, d := range done {
+		if !d {
+			res.fail(start+uint64(i), ctx.Err(), 0)
+		}
+	}
+}
+
+// commit writes the batch, assigning its heights to result (or failing all of them)
+func (rr *RangeRequester) commit(ctx context.Context, batch *storeBatch, res *HeightsResult) {
+	results, retries, err := batch.commit(ctx)
+	res.Retried += uint64(retries)
+	for _, r := range results {
+		if err != nil {
+			res.fail(r.height, err, r.retries+retries)
+			continue
+		}
+		res.assign(r)
+	}
+}
+
+func (rr *RangeRequester) asyncBlockAndTx(ctx context.Context, wg *sync.WaitGroup, heights <-chan uint64, out chan<- heightResult) {
+	defer wg.Done()
+	for h := range heights {
+		r := heightResult{height: h}
+		if r.err = rr.Limiter.acquire(ctx); r.err != nil {
+			out <- r
+			continue
+		}
+		for i := 1; ; i++ {
+			r.block, r.txs, r.err = rr.BTX.BlockAndTx(ctx, h)
+			if r.err == nil || i == heightMaxRetries || ctx.Err() != nil {
+				break
+			}
+			// forks are not retried, as heights have to be invalidated by the manager
+			reason := errorReason(r.err)
+			if reason == ReasonHashMismatch || reason == ReasonCanceled {
+				break
+			}
+
+			r.retries++
+			heightRetriesMetric.WithLabels(string(reason)).Inc()
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Duration(i) * heightRetryDelay):
+			}
+		}
+		rr.Limiter.release()
+		out <- r
+	}
+}
+
+func (res *HeightsResult) fail(height uint64, err error, retries int) {
+	if err == nil {
+		err = context.Canceled
+	}
+	he := HeightError{Height: height, Reason: errorReason(err), Message: err.Error(), Retries: retries}
+	errors.As(err, &he.Fork)
+	res.Failed = append(res.Failed, he)
+	res.ErrorAt = append(res.ErrorAt, height)
+}
+
+func (res *HeightsResult) assign(r heightResult) {
+	res.Heights.Heights = append(res.Heights.Heights, r.height)
+	res.NumberOfHeights++
+	res.NumberOfTx += r.block.Block.NumberOfTransactions
+
+	if res.LatestData.LastTime.IsZero() || res.LatestData.LastHeight <= r.height {
+		res.LatestData.LastEpoch = r.block.Block.Epoch
+		res.LatestData.LastHash = r.block.Block.Hash
+		res.LatestData.LastHeight = r.height
+		res.LatestData.LastTime = r.block.Block.Time
+	}
+}
@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/figment-networks/indexing-engine/structs"
+)
+
+// fakeBTX fails heights the given number of times with the given error
+type fakeBTX struct {
+	l sync.Mutex
+
+	failures map[uint64]int
+	errs     map[uint64]error
+	calls    map[uint64]int
+}
+
+func (f *fakeBTX) BlockAndTx(ctx context.Context, height uint64) (blockWM structs.BlockWithMeta, txsWM []structs.TransactionWithMeta, err error) {
+	f.l.Lock()
+	defer f.l.Unlock()
+
+	f.calls[height]++
+	if f.failures[height] > 0 {
+		f.failures[height]--
+		return blockWM, nil, f.errs[height]
+	}
+	blockWM.Block = structs.Block{Height: height, Hash: fmt.Sprintf("HASH%d", height), NumberOfTransactions: 1}
+	return blockWM, []structs.TransactionWithMeta{{}}, nil
+}
+
+func TestGetRangeFailures(t *testing.T) {
+	fetchErr := &stepError{ReasonFetchTransactions, errors.New("connection reset")}
+	btx := &fakeBTX{
+		failures: map[uint64]int{
+			// recovers on the second attempt
+			2: 1,
+			// fails every attempt
+			3: heightMaxRetries,
+			// forks are not retried
+			4: heightMaxRetries,
+			// unclassified errors are retried
+			5: heightMaxRetries,
+		},
+		errs: map[uint64]error{
+			2: fetchErr,
+			3: &stepError{ReasonFetchBlock, errors.New("timeout")},
+			4: &ForkError{Height: 4, Hash: "OTHER", KnownHeight: 3},
+			5: errors.New("unknown"),
+		},
+		calls: make(map[uint64]int),
+	}
+	rr := NewRangeRequester(btx, 4)
+	rr.ChunkSize = 3
+
+	var onHeight []uint64
+	var l sync.Mutex
+	rr.OnHeight = func(h uint64) {
+		l.Lock()
+		defer l.Unlock()
+		onHeight = append(onHeight, h)
+	}
+
+	res, err := rr.GetRange(context.Background(), structs.HeightRange{StartHeight: 1, EndHeight: 6})
+	var re *RangeError
+	if !errors.As(err, &re) || len(re.Failed) != 3 {
+		t.Fatalf("GetRange() error = %v, want RangeError of 3 heights", err)
+	}
+	for _, h := range []string{"3 [fetch_block]", "4 [hash_mismatch]", "5 [unknown]"} {
+		if !strings.Contains(err.Error(), h) {
+			t.Errorf("error %q doesn't report height %s", err, h)
+		}
+	}
+
+	want := []HeightError{
+		{Height: 3, Reason: ReasonFetchBlock, Message: "timeout", Retries: heightMaxRetries - 1},
+		{Height: 4, Reason: ReasonHashMismatch, Retries: 0},
+		{Height: 5, Reason: ReasonUnknown, Message: "unknown", Retries: heightMaxRetries - 1},
+	}
+	for i, w := range want {
+		got := res.Failed[i]
+		if got.Height != w.Height || got.Reason != w.Reason || got.Retries != w.Retries || (w.Message != "" && got.Message != w.Message) {
+			t.Errorf("failed height %+v, want %+v", got, w)
+		}
+	}
+	if res.Failed[1].Fork == nil || res.Failed[1].Fork.Hash != "OTHER" {
+		t.Errorf("fork of height 4 not reported: %+v", res.Failed[1].Fork)
+	}
+
+	if fmt.Sprint(res.Heights.Heights) != "[1 2 6]" || fmt.Sprint(res.ErrorAt) != "[3 4 5]" {
+		t.Errorf("heights %v, errors at %v", res.Heights.Heights, res.ErrorAt)
+	}
+	if res.NumberOfHeights != 3 || res.NumberOfTx != 3 || res.LatestData.LastHeight != 6 || res.LatestData.LastHash != "HASH6" {
+		t.Errorf("summary %+v", res.Heights)
+	}
+	// one retry of height 2, two of heights 3 and 5
+	if res.Retried != 1+2*(heightMaxRetries-1) {
+		t.Errorf("retried %d times", res.Retried)
+	}
+	if btx.calls[4] != 1 || btx.calls[3] != heightMaxRetries {
+		t.Errorf("BlockAndTx called %d times for fork, %d times for failing height", btx.calls[4], btx.calls[3])
+	}
+	if len(onHeight) != 6 {
+		t.Errorf("OnHeight called for %v", onHeight)
+	}
+}
+
+func TestGetRangeCanceled(t *testing.T) {
+	btx := &fakeBTX{failures: map[uint64]int{}, calls: make(map[uint64]int)}
+	rr := NewRangeRequester(btx, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res, err := rr.GetRange(ctx, structs.HeightRange{StartHeight: 1, EndHeight: 10})
+	if err == nil {
+		t.Fatal("expected error for cancelled range")
+	}
+	// every height is reported, whether it was scheduled or not
+	if len(res.Failed)+len(res.Heights.Heights) != 10 {
+		t.Fatalf("%d heights failed, %d succeeded, want 10 in total", len(res.Failed), len(res.Heights.Heights))
+	}
+	for _, f := range res.Failed {
+		if f.Reason != ReasonCanceled || f.Retries != 0 {
+			t.Errorf("height %d failed with %s after %d retries", f.Height, f.Reason, f.Retries)
+		}
+	}
+}
+
+func TestGetRangeWrongRange(t *testing.T) {
+	rr := NewRangeRequester(&fakeBTX{calls: make(map[uint64]int)}, 1)
+	if _, err := rr.GetRange(context.Background(), structs.HeightRange{StartHeight: 5, EndHeight: 4}); err == nil {
+		t.Error("expected error for range ending before start")
+	}
+}
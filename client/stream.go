@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+
+	cStructs "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/figment-networks/indexing-engine/metrics"
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ReqIDStreamTransactions is a task type streaming blocks and transactions of the range back to the requester
+const ReqIDStreamTransactions = "StreamTransactions"
+
+// StreamRequest is the payload of StreamTransactions task
+type StreamRequest struct {
+	structs.HeightRange
+
+	// SkipStore disables writing data into the search store
+	SkipStore bool `json:"skip_store"`
+}
+
+// streamingBTX processes heights sending every produced block and transaction out
+type streamingBTX struct {
+	ic    *IndexerClient
+	id    uuid.UUID
+	store bool
+	out   chan<- cStructs.OutResp
+}
+
+func (s *streamingBTX) BlockAndTx(ctx context.Context, height uint64) (blockWM structs.BlockWithMeta, txsWM []structs.TransactionWithMeta, err error) {
+	blockWM, txsWM, err = s.ic.blockAndTx(ctx, height, s.store)
+	if err != nil {
+		return blockWM, txsWM, err
+	}
+
+	// sent only when the whole height succeeded, so retries don't produce duplicates
+	select {
+	case <-ctx.Done():
+		return blockWM, txsWM, ctx.Err()
+	case s.out <- cStructs.OutResp{ID: s.id, Type: "Block", Payload: blockWM}:
+	}
+	for _, t := range txsWM {
+		select {
+		case <-ctx.Done():
+			return blockWM, txsWM, ctx.Err()
+		case s.out <- cStructs.OutResp{ID: s.id, Type: "Transaction", Payload: s.ic.withLedger(t)}:
+		}
+	}
+	return blockWM, txsWM, nil
+}
+
+// StreamTransactions gets blocks and transactions of the range, sending them back as they are produced.
+// The last part is the Heights summary (see GetTransactions).
+func (ic *IndexerClient) StreamTransactions(ctx context.Context, tr cStructs.TaskRequest, stream *cStructs.StreamAccess) {
+	timer := metrics.NewTimer(streamTransactionsDuration)
+	defer timer.ObserveDuration()
+
+	sr := &StreamRequest{}
+	if err := json.Unmarshal(tr.Payload, sr); err != nil {
+		ic.logger.Debug("[KAVA-CLIENT] Cannot unmarshal payload", zap.String("contents", string(tr.Payload)))
+		stream.Send(cStructs.TaskResponse{
+			Id:    tr.Id,
+			Error: cStructs.TaskError{Msg: "cannot unmarshal payload: " + err.Error()},
+			Final: true,
+		})
+		return
+	}
+	if err := ic.checkRange(sr.HeightRange); err != nil {
+		stream.Send(cStructs.TaskResponse{
+			Id:    tr.Id,
+			Error: cStructs.TaskError{Msg: err.Error()},
+			Final: true,
+		})
+		return
+	}
+
+	ic.logger.Debug("[KAVA-CLIENT] Streaming Range", zap.Stringer("taskID", tr.Id), zap.Uint64("start", sr.StartHeight), zap.Uint64("end", sr.EndHeight), zap.Bool("skip_store", sr.SkipStore))
+
+	out := make(chan cStructs.OutResp, page)
+	fin := make(chan bool, 1)
+	go sendResp(ctx, tr.Id, out, ic.logger, stream, fin)
+
+	rr := ic.newRangeRequester(&streamingBTX{ic: ic, id: tr.Id, store: !sr.SkipStore, out: out})
+	rr.OnHeight = func(uint64) { ic.Tasks.Progress(tr.Id) }
+	heights, err := rr.GetRange(ctx, sr.HeightRange)
+	if err != nil {
+		ic.logger.Error("[KAVA-CLIENT] Error getting range (Stream Transactions) ", zap.Error(err), zap.Stringer("taskID", tr.Id), zap.Int("failed", len(heights.Failed)))
+	}
+
+	select {
+	case <-ctx.Done():
+	case out <- cStructs.OutResp{ID: tr.Id, Type: "Heights", Payload: heights}:
+	}
+	close(out)
+	<-fin
+	ic.logger.Debug("[KAVA-CLIENT] Finished streaming all", zap.Stringer("taskID", tr.Id))
+}
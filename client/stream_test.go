@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	cStructs "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/google/uuid"
+)
+
+// streamTask runs StreamTransactions with the payload, returning all its responses
+func streamTask(t *testing.T, ic *IndexerClient, payload string) []cStructs.TaskResponse {
+	t.Helper()
+	stream := cStructs.NewStreamAccess()
+
+	go ic.StreamTransactions(context.Background(), cStructs.TaskRequest{Id: uuid.New(), Type: ReqIDStreamTransactions, Payload: []byte(payload)}, stream)
+	return responses(t, stream)
+}
+
+func TestStreamTransactions(t *testing.T) {
+	tests := []struct {
+		name      string
+		skipStore bool
+		failing   uint64
+		// wantHeights are the heights streamed
+		wantHeights []uint64
+	}{
+		{name: "stored", wantHeights: []uint64{1, 2, 3}},
+		{name: "skip store", skipStore: true, wantHeights: []uint64{1, 2, 3}},
+		{name: "failed height is not streamed", failing: 2, wantHeights: []uint64{1, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rpc := newFakeRPC()
+			rpc.txs[1], rpc.txs[2], rpc.txs[3] = 2, 0, 1
+			if tt.failing > 0 {
+				rpc.failures[tt.failing] = heightMaxRetries
+				rpc.txs[tt.failing] = 1
+			}
+			ms := newMemStore()
+			ic := newTestClient(t, rpc, ms, Limits{RangeWorkers: 1})
+
+			resp := streamTask(t, ic, fmt.Sprintf(`{"StartHeight": 1, "EndHeight": 3, "skip_store": %v}`, tt.skipStore))
+			if len(resp) < 2 {
+				t.Fatalf("responses %+v, want streamed heights and summary", resp)
+			}
+
+			// every height is streamed once, block followed by its transactions
+			var streamed []uint64
+			var current uint64
+			for _, r := range resp[:len(resp)-2] {
+				switch r.Type {
+				case "Block":
+					b := structs.BlockWithMeta{}
+					if err := json.Unmarshal(r.Payload, &b); err != nil {
+						t.Fatal(err)
+					}
+					current = b.Block.Height
+					streamed = append(streamed, current)
+				case "Transaction":
+					tx := TransactionWithLedger{}
+					if err := json.Unmarshal(r.Payload, &tx); err != nil {
+						t.Fatal(err)
+					}
+					if tx.Transaction.Height != current {
+						t.Errorf("transaction of height %d streamed after block %d", tx.Transaction.Height, current)
+					}
+				default:
+					t.Errorf("unexpected response %s before the summary", r.Type)
+				}
+			}
+			if fmt.Sprint(streamed) != fmt.Sprint(tt.wantHeights) {
+				t.Errorf("streamed heights %v, want %v", streamed, tt.wantHeights)
+			}
+			for i, r := range resp {
+				if r.Order != uint64(i) {
+					t.Errorf("response %d has order %d", i, r.Order)
+				}
+			}
+
+			summary := resp[len(resp)-2]
+			if summary.Type != "Heights" || resp[len(resp)-1].Type != "END" {
+				t.Fatalf("last responses are %s and %s, want Heights and END", summary.Type, resp[len(resp)-1].Type)
+			}
+			heights := HeightsResult{}
+			if err := json.Unmarshal(summary.Payload, &heights); err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(heights.Heights.Heights) != fmt.Sprint(tt.wantHeights) {
+				t.Errorf("summary heights %v, want %v", heights.Heights.Heights, tt.wantHeights)
+			}
+			if tt.failing > 0 && (len(heights.Failed) != 1 || heights.Failed[0].Height != tt.failing || heights.Failed[0].Reason != ReasonFetchTransactions) {
+				t.Errorf("summary failures %+v", heights.Failed)
+			}
+
+			if stored := ms.numWrites("blocks") > 0; stored == tt.skipStore {
+				t.Errorf("store written = %v with skip_store %v", stored, tt.skipStore)
+			}
+		})
+	}
+}
+
+func TestStreamTransactionsWrongRequest(t *testing.T) {
+	ic := newTestClient(t, newFakeRPC(), newMemStore(), Limits{})
+	for _, payload := range []string{`{"StartHeight": `, `{"StartHeight": 5, "EndHeight": 4}`, `{"StartHeight": 1, "EndHeight": 5000}`} {
+		resp := streamTask(t, ic, payload)
+		if len(resp) != 1 || resp[0].Error.Msg == "" {
+			t.Errorf("responses to %s: %+v, want single error", payload, resp)
+		}
+	}
+}
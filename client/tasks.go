@@ -0,0 +1,202 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	cStructs "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/google/uuid"
+)
+
+// ReqIDCancelTask is a task type cancelling another in-flight task
+const ReqIDCancelTask = "CancelTask"
+
+// DefaultTaskTimeout is used for task types without configured timeout
+const DefaultTaskTimeout = 5 * time.Minute
+
+// CancelTaskRequest is the payload of CancelTask task
+type CancelTaskRequest struct {
+	TaskID uuid.UUID `json:"task_id"`
+}
+
+// CancelTaskResponse is the response of CancelTask task
+type CancelTaskResponse struct {
+	TaskID    uuid.UUID `json:"task_id"`
+	Cancelled bool      `json:"cancelled"`
+}
+
+// TaskInfo describes in-flight task
+type TaskInfo struct {
+	// progress is accessed atomically, kept first for 64-bit alignment
+	progress uint64
+
+	ID       uuid.UUID            `json:"id"`
+	StreamID uuid.UUID            `json:"stream_id"`
+	Type     string               `json:"type"`
+	Started  time.Time            `json:"started"`
+	Timeout  string               `json:"timeout"`
+	Range    *structs.HeightRange `json:"range,omitempty"`
+	// Total is the number of heights of the range
+	Total uint64 `json:"total,omitempty"`
+
+	cancel context.CancelFunc
+}
+
+// TaskStatus is a snapshot of in-flight task
+type TaskStatus struct {
+	*TaskInfo
+	// Progress is the number of heights processed so far
+	Progress uint64 `json:"progress"`
+	Running  string `json:"running"`
+}
+
+// TaskRegistry keeps track of in-flight tasks
+type TaskRegistry struct {
+	tasks map[uuid.UUID]*TaskInfo
+	l     sync.RWMutex
+
+	defaultTimeout time.Duration
+	timeouts       map[string]time.Duration
+}
+
+// NewTaskRegistry is TaskRegistry constructor
+func NewTaskRegistry(defaultTimeout time.Duration, timeouts map[string]time.Duration) *TaskRegistry {
+	if defaultTimeout <= 0 {
+		defaultTimeout = DefaultTaskTimeout
+	}
+	return &TaskRegistry{
+		tasks:          make(map[uuid.UUID]*TaskInfo),
+		defaultTimeout: defaultTimeout,
+		timeouts:       timeouts,
+	}
+}
+
+// Start registers the task, returning its context (with type timeout) and function to be called when task is done
+func (tr *TaskRegistry) Start(ctx context.Context, req cStructs.TaskRequest, streamID uuid.UUID) (context.Context, func()) {
+	timeout, ok := tr.timeouts[req.Type]
+	if !ok {
+		timeout = tr.defaultTimeout
+	}
+
+	nCtx, cancel := context.WithTimeout(ctx, timeout)
+	ti := &TaskInfo{
+		ID:       req.Id,
+		StreamID: streamID,
+		Type:     req.Type,
+		Started:  time.Now(),
+		Timeout:  timeout.String(),
+		cancel:   cancel,
+	}
+
+	hr := &structs.HeightRange{}
+	if err := json.Unmarshal(req.Payload, hr); err == nil && hr.EndHeight >= hr.StartHeight && hr.EndHeight > 0 {
+		ti.Range = hr
+		ti.Total = hr.EndHeight - hr.StartHeight + 1
+	}
+
+	tr.l.Lock()
+	tr.tasks[req.Id] = ti
+	tr.l.Unlock()
+
+	return nCtx, func() {
+		cancel()
+		tr.l.Lock()
+		delete(tr.tasks, req.Id)
+		tr.l.Unlock()
+	}
+}
+
+// Progress marks another height of the task as processed
+func (tr *TaskRegistry) Progress(id uuid.UUID) {
+	tr.l.RLock()
+	defer tr.l.RUnlock()
+
+	if ti, ok := tr.tasks[id]; ok {
+		atomic.AddUint64(&ti.progress, 1)
+	}
+}
+
+// Cancel cancels in-flight task, returns false when there is no such task
+func (tr *TaskRegistry) Cancel(id uuid.UUID) bool {
+	tr.l.RLock()
+	defer tr.l.RUnlock()
+
+	ti, ok := tr.tasks[id]
+	if ok {
+		ti.cancel()
+	}
+	return ok
+}
+
+// CancelStream cancels all in-flight tasks of the stream
+func (tr *TaskRegistry) CancelStream(streamID uuid.UUID) {
+	tr.l.RLock()
+	defer tr.l.RUnlock()
+
+	for _, ti := range tr.tasks {
+		if ti.StreamID == streamID {
+			ti.cancel()
+		}
+	}
+}
+
+// CancelAll cancels all in-flight tasks
+func (tr *TaskRegistry) CancelAll() {
+	tr.l.RLock()
+	defer tr.l.RUnlock()
+
+	for _, ti := range tr.tasks {
+		ti.cancel()
+	}
+}
+
+// List returns in-flight tasks ordered by start time
+func (tr *TaskRegistry) List() []TaskStatus {
+	tr.l.RLock()
+	defer tr.l.RUnlock()
+
+	now := time.Now()
+	list := make([]TaskStatus, 0, len(tr.tasks))
+	for _, ti := range tr.tasks {
+		list = append(list, TaskStatus{
+			TaskInfo: ti,
+			Progress: atomic.LoadUint64(&ti.progress),
+			Running:  now.Sub(ti.Started).String(),
+		})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Started.Before(list[j].Started) })
+	return list
+}
+
+// ServeHTTP lists in-flight tasks
+func (tr *TaskRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(tr.List()); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// CancelTask cancels task given in the payload, dequeue removes the task when it still waits for a worker
+func (ic *IndexerClient) CancelTask(ctx context.Context, tr cStructs.TaskRequest, stream *cStructs.StreamAccess, dequeue func(id uuid.UUID) bool) {
+	ctr := &CancelTaskRequest{}
+	if err := json.Unmarshal(tr.Payload, ctr); err != nil {
+		stream.Send(cStructs.TaskResponse{
+			Id:    tr.Id,
+			Error: cStructs.TaskError{Msg: "Cannot unmarshal payload"},
+			Final: true,
+		})
+		return
+	}
+
+	resp := cStructs.TaskResponse{Id: tr.Id, Type: ReqIDCancelTask, Final: true}
+	cancelled := ic.Tasks.Cancel(ctr.TaskID) || (dequeue != nil && dequeue(ctr.TaskID))
+	resp.Payload, _ = json.Marshal(CancelTaskResponse{TaskID: ctr.TaskID, Cancelled: cancelled})
+	stream.Send(resp)
+}
@@ -0,0 +1,216 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mStructs "github.com/figment-networks/indexer-manager/structs"
+	cStructs "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/google/uuid"
+)
+
+func TestTaskRegistry(t *testing.T) {
+	tr := NewTaskRegistry(time.Hour, map[string]time.Duration{ReqIDGetBlock: time.Minute})
+	streamA, streamB := uuid.New(), uuid.New()
+
+	rangeTask := cStructs.TaskRequest{Id: uuid.New(), Type: mStructs.ReqIDGetTransactions, Payload: []byte(`{"StartHeight": 10, "EndHeight": 19}`)}
+	rangeCtx, finishRange := tr.Start(context.Background(), rangeTask, streamA)
+	blockTask := cStructs.TaskRequest{Id: uuid.New(), Type: ReqIDGetBlock, Payload: []byte(`{"height": 1}`)}
+	blockCtx, finishBlock := tr.Start(context.Background(), blockTask, streamB)
+	defer finishBlock()
+
+	// timeouts are set per task type
+	for _, c := range []struct {
+		ctx     context.Context
+		timeout time.Duration
+	}{{rangeCtx, time.Hour}, {blockCtx, time.Minute}} {
+		deadline, ok := c.ctx.Deadline()
+		if left := time.Until(deadline); !ok || left > c.timeout || left < c.timeout-time.Second {
+			t.Errorf("deadline in %s, want %s", left, c.timeout)
+		}
+	}
+
+	tr.Progress(rangeTask.Id)
+	tr.Progress(rangeTask.Id)
+	tr.Progress(uuid.New())
+	list := tr.List()
+	if len(list) != 2 || list[0].ID != rangeTask.Id || list[1].ID != blockTask.Id {
+		t.Fatalf("List() = %+v", list)
+	}
+	if list[0].Progress != 2 || list[0].Total != 10 || list[0].Range == nil || list[0].Range.StartHeight != 10 || list[0].Timeout != "1h0m0s" {
+		t.Errorf("range task status %+v", list[0])
+	}
+	if list[1].Range != nil || list[1].Total != 0 {
+		t.Errorf("block task has range %+v", list[1].Range)
+	}
+
+	rec := httptest.NewRecorder()
+	tr.ServeHTTP(rec, httptest.NewRequest("GET", "/tasks", nil))
+	listed := []map[string]interface{}{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &listed); err != nil || len(listed) != 2 || listed[0]["progress"] != float64(2) {
+		t.Errorf("listed %s, %v", rec.Body.String(), err)
+	}
+
+	// cancelling a stream doesn't touch tasks of other streams
+	tr.CancelStream(streamA)
+	if rangeCtx.Err() == nil || blockCtx.Err() != nil {
+		t.Errorf("after stream cancellation: range task %v, block task %v", rangeCtx.Err(), blockCtx.Err())
+	}
+
+	finishRange()
+	if tr.Cancel(rangeTask.Id) {
+		t.Error("finished task was cancelled")
+	}
+	if !tr.Cancel(blockTask.Id) || blockCtx.Err() == nil {
+		t.Error("in-flight task not cancelled")
+	}
+	if len(tr.List()) != 1 {
+		t.Errorf("finished task still listed: %+v", tr.List())
+	}
+}
+
+func TestTaskRegistryCancelAll(t *testing.T) {
+	tr := NewTaskRegistry(0, nil)
+	var ctxs []context.Context
+	for i := 0; i < 3; i++ {
+		ctx, finish := tr.Start(context.Background(), cStructs.TaskRequest{Id: uuid.New(), Type: ReqIDGetBlock}, uuid.New())
+		defer finish()
+		ctxs = append(ctxs, ctx)
+
+		if deadline, _ := ctx.Deadline(); time.Until(deadline) < DefaultTaskTimeout-time.Second {
+			t.Errorf("deadline in %s, want default %s", time.Until(deadline), DefaultTaskTimeout)
+		}
+	}
+	tr.CancelAll()
+	for i, ctx := range ctxs {
+		if ctx.Err() == nil {
+			t.Errorf("task %d not cancelled", i)
+		}
+	}
+}
+
+// runStream registers the stream of the client until the test ends
+func runStream(t *testing.T, ic *IndexerClient) *cStructs.StreamAccess {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	stream := cStructs.NewStreamAccess()
+	if err := ic.RegisterStream(ctx, stream); err != nil {
+		t.Fatal(err)
+	}
+	return stream
+}
+
+func waitResponse(t *testing.T, stream *cStructs.StreamAccess) cStructs.TaskResponse {
+	t.Helper()
+	select {
+	case resp := <-stream.ResponseListener:
+		return resp
+	case <-time.After(5 * time.Second):
+		t.Fatal("no response received")
+	}
+	return cStructs.TaskResponse{}
+}
+
+func cancelRequest(t *testing.T, id uuid.UUID) cStructs.TaskRequest {
+	t.Helper()
+	payload, err := json.Marshal(CancelTaskRequest{TaskID: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cStructs.TaskRequest{Id: uuid.New(), Type: ReqIDCancelTask, Payload: payload}
+}
+
+func TestCancelTaskWithBusyWorkers(t *testing.T) {
+	rpc := newFakeRPC()
+	rpc.wait = make(chan struct{})
+	ic := newTestClient(t, rpc, newMemStore(), Limits{StreamWorkers: 1})
+	stream := runStream(t, ic)
+
+	// the only worker is busy with the range, the block task waits for it
+	rangeTask := cStructs.TaskRequest{Id: uuid.New(), Type: mStructs.ReqIDGetTransactions, Payload: []byte(`{"StartHeight": 1, "EndHeight": 5}`)}
+	blockTask := cStructs.TaskRequest{Id: uuid.New(), Type: ReqIDGetBlock, Payload: []byte(`{"height": 1}`)}
+	stream.Req(rangeTask)
+	stream.Req(blockTask)
+	for len(ic.Tasks.List()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// waiting task is removed from the queue
+	stream.Req(cancelRequest(t, blockTask.Id))
+	resp := waitResponse(t, stream)
+	if resp.Id != blockTask.Id || !strings.Contains(resp.Error.Msg, "cancelled") {
+		t.Fatalf("response %+v, want cancellation of the waiting task", resp)
+	}
+	resp = waitResponse(t, stream)
+	ctr := CancelTaskResponse{}
+	if err := json.Unmarshal(resp.Payload, &ctr); err != nil || resp.Type != ReqIDCancelTask || ctr.TaskID != blockTask.Id || !ctr.Cancelled {
+		t.Fatalf("cancel response %+v, %s", resp, resp.Payload)
+	}
+
+	// running task is cancelled without waiting for a worker
+	stream.Req(cancelRequest(t, rangeTask.Id))
+	resp = waitResponse(t, stream)
+	if err := json.Unmarshal(resp.Payload, &ctr); err != nil || resp.Type != ReqIDCancelTask || ctr.TaskID != rangeTask.Id || !ctr.Cancelled {
+		t.Fatalf("cancel response %+v, %s", resp, resp.Payload)
+	}
+	resp = waitResponse(t, stream)
+	if resp.Id != rangeTask.Id || !resp.Final || resp.Error.Msg == "" {
+		t.Errorf("response of cancelled range %+v", resp)
+	}
+
+	// unknown task is reported as not cancelled
+	stream.Req(cancelRequest(t, uuid.New()))
+	resp = waitResponse(t, stream)
+	if err := json.Unmarshal(resp.Payload, &ctr); err != nil || ctr.Cancelled {
+		t.Errorf("cancel response of unknown task %s", resp.Payload)
+	}
+}
+
+func TestTaskQueueFull(t *testing.T) {
+	rpc := newFakeRPC()
+	rpc.wait = make(chan struct{})
+	ic := newTestClient(t, rpc, newMemStore(), Limits{StreamWorkers: 1, MaxQueuedTasks: 1})
+	stream := runStream(t, ic)
+
+	// the only worker is busy, one task waits in the queue and the next one is rejected
+	stream.Req(cStructs.TaskRequest{Id: uuid.New(), Type: ReqIDGetBlock, Payload: []byte(`{"height": 1}`)})
+	for len(ic.Tasks.List()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	queued := cStructs.TaskRequest{Id: uuid.New(), Type: ReqIDGetBlock, Payload: []byte(`{"height": 2}`)}
+	stream.Req(queued)
+	rejected := cStructs.TaskRequest{Id: uuid.New(), Type: ReqIDGetBlock, Payload: []byte(`{"height": 3}`)}
+	stream.Req(rejected)
+
+	resp := waitResponse(t, stream)
+	if resp.Id != rejected.Id || !resp.Final || resp.Error.Msg != ErrQueueFull.Error() {
+		t.Fatalf("response %+v, want rejection of the task over the queue cap", resp)
+	}
+
+	// queued task is still served
+	close(rpc.wait)
+	for resp.Id != queued.Id || !resp.Final {
+		resp = waitResponse(t, stream)
+	}
+	if resp.Error.Msg != "" {
+		t.Errorf("queued task failed: %+v", resp)
+	}
+}
+
+func TestTaskTimeout(t *testing.T) {
+	rpc := newFakeRPC()
+	rpc.wait = make(chan struct{})
+	ic := newTestClient(t, rpc, newMemStore(), Limits{TaskTimeouts: map[string]time.Duration{ReqIDGetBlock: 20 * time.Millisecond}})
+	stream := runStream(t, ic)
+
+	stream.Req(cStructs.TaskRequest{Id: uuid.New(), Type: ReqIDGetBlock, Payload: []byte(`{"height": 1}`)})
+	resp := waitResponse(t, stream)
+	if !resp.Final || !strings.Contains(resp.Error.Msg, "deadline exceeded") {
+		t.Errorf("response %+v, want timeout error", resp)
+	}
+}
@@ -3,31 +3,58 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	cStructs "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
 	"github.com/figment-networks/indexing-engine/metrics"
 	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/figment-networks/indexing-engine/worker/store"
 	"go.uber.org/zap"
 )
 
+// BlockAndTx gets block and transactions of the height and writes them into the search store
 func (ic *IndexerClient) BlockAndTx(ctx context.Context, height uint64) (blockWM structs.BlockWithMeta, txsWM []structs.TransactionWithMeta, err error) {
+	return ic.blockAndTx(ctx, height, true)
+}
+
+func (ic *IndexerClient) blockAndTx(ctx context.Context, height uint64, withStore bool) (blockWM structs.BlockWithMeta, txsWM []structs.TransactionWithMeta, err error) {
 	defer ic.logger.Sync()
-	ic.logger.Debug("[KAVA-CLIENT] Getting height", zap.Uint64("block", height))
 
-	hSess, err := ic.storeClient.GetSearchSession(ctx)
-	if err != nil {
-		return blockWM, nil, err
+	var hSess store.SearchStore
+	if withStore {
+		if hSess, err = ic.storeClient.GetSearchSession(ctx); err != nil {
+			return blockWM, nil, &stepError{ReasonStore, err}
+		}
+	}
+
+	if blockWM, txsWM, err = ic.fetchHeight(ctx, hSess, height); err != nil {
+		return blockWM, txsWM, err
 	}
 
+	if hSess != nil {
+		if err := storeHeights(ctx, hSess, []structs.BlockWithMeta{blockWM}, txsWM); err != nil {
+			return blockWM, txsWM, err
+		}
+	}
+	ic.logger.Debug("[KAVA-CLIENT] Got block", zap.Uint64("block", height), zap.Uint64("txs", blockWM.Block.NumberOfTransactions))
+	return blockWM, txsWM, nil
+}
+
+// fetchHeight gets block and transactions of the height, checking continuity of the block (against the store when session is given)
+func (ic *IndexerClient) fetchHeight(ctx context.Context, hSess store.SearchStore, height uint64) (blockWM structs.BlockWithMeta, txsWM []structs.TransactionWithMeta, err error) {
+	ic.logger.Debug("[KAVA-CLIENT] Getting height", zap.Uint64("block", height))
+
 	blockWM = structs.BlockWithMeta{Network: "kava", Version: "0.0.1"}
-	blockWM.Block, err = ic.rpcCli.GetBlock(ctx, structs.HeightHash{Height: uint64(height)})
+	var parentHash string
+	blockWM.Block, parentHash, err = ic.rpcCli.GetBlockWithParent(ctx, structs.HeightHash{Height: uint64(height)})
 	blockWM.ChainID = blockWM.Block.ChainID
 	if err != nil {
 		ic.logger.Error("[KAVA-CLIENT] Err Getting block", zap.Uint64("block", height), zap.Error(err), zap.Uint64("txs", blockWM.Block.NumberOfTransactions))
-		return blockWM, nil, fmt.Errorf("error fetching block: %d %w ", uint64(height), err)
+		return blockWM, nil, &stepError{ReasonFetchBlock, fmt.Errorf("error fetching block: %d %w ", uint64(height), err)}
 	}
-	if err := hSess.StoreBlocks(ctx, []structs.BlockWithMeta{blockWM}); err != nil {
+
+	if err := ic.checkContinuity(ctx, hSess, blockWM.Block, parentHash); err != nil {
 		return blockWM, nil, err
 	}
 
@@ -35,22 +62,101 @@ func (ic *IndexerClient) BlockAndTx(ctx context.Context, height uint64) (blockWM
 		ic.logger.Debug("[KAVA-CLIENT] Getting txs", zap.Uint64("block", height), zap.Uint64("txs", blockWM.Block.NumberOfTransactions))
 		var txs []structs.Transaction
 		txs, err = ic.rpcCli.SearchTx(ctx, structs.HeightHash{Height: height}, blockWM.Block, page)
+		if err != nil {
+			ic.logger.Debug("[KAVA-CLIENT] txErr Getting txs", zap.Uint64("block", height), zap.Error(err), zap.Uint64("txs", blockWM.Block.NumberOfTransactions))
+			return blockWM, nil, &stepError{ReasonFetchTransactions, fmt.Errorf("error fetching transactions: %d %w ", height, err)}
+		}
 		for _, t := range txs {
 			txsWM = append(txsWM, structs.TransactionWithMeta{Network: "kava", ChainID: t.ChainID, Version: "0.0.1", Transaction: t})
 		}
-		if len(txsWM) > 0 {
-			if err := hSess.StoreTransactions(ctx, txsWM); err != nil {
-				return blockWM, txsWM, err
-			}
+	}
+	return blockWM, txsWM, nil
+}
+
+// storeHeights writes blocks and transactions, confirming heights only when both succeeded
+func storeHeights(ctx context.Context, hSess store.SearchStore, blocks []structs.BlockWithMeta, txs []structs.TransactionWithMeta) error {
+	timer := metrics.NewTimer(storeDuration.WithLabels("blocks"))
+	err := hSess.StoreBlocks(ctx, blocks)
+	timer.ObserveDuration()
+	if err != nil {
+		return &stepError{ReasonStore, fmt.Errorf("error storing blocks: %w", err)}
+	}
+
+	if len(txs) > 0 {
+		timer = metrics.NewTimer(storeDuration.WithLabels("transactions"))
+		err = hSess.StoreTransactions(ctx, txs)
+		timer.ObserveDuration()
+		if err != nil {
+			return &stepError{ReasonStore, fmt.Errorf("error storing transactions: %w", err)}
 		}
-		ic.logger.Debug("[KAVA-CLIENT] txErr Getting txs", zap.Uint64("block", height), zap.Error(err), zap.Uint64("txs", blockWM.Block.NumberOfTransactions))
 	}
 
-	if err := hSess.ConfirmHeights(ctx, []structs.BlockWithMeta{blockWM}); err != nil {
-		return blockWM, txsWM, err
+	timer = metrics.NewTimer(storeDuration.WithLabels("confirm"))
+	err = hSess.ConfirmHeights(ctx, blocks)
+	timer.ObserveDuration()
+	if err != nil {
+		return &stepError{ReasonStore, fmt.Errorf("error confirming heights: %w", err)}
 	}
-	ic.logger.Debug("[KAVA-CLIENT] Got block", zap.Uint64("block", height), zap.Uint64("txs", blockWM.Block.NumberOfTransactions))
-	return blockWM, txsWM, err
+	return nil
+}
+
+// checkContinuity verifies that block links with neighbouring heights seen before and with confirmed heights of the store.
+// On mismatch, both heights are invalidated and ForkError is returned.
+func (ic *IndexerClient) checkContinuity(ctx context.Context, hSess store.SearchStore, block structs.Block, parentHash string) error {
+	link := blockLink{hash: block.Hash, parentHash: parentHash}
+
+	var fe *ForkError
+	chg, hasConfirmed := hSess.(ConfirmedHashGetter)
+	if hasConfirmed {
+		if err := checkConfirmed(ctx, chg, block.Height, link); err != nil && !errors.As(err, &fe) {
+			return fmt.Errorf("error getting confirmed hash: %w", err)
+		}
+	}
+	if fe == nil {
+		if err := ic.links.check(block.Height, link); err != nil && !errors.As(err, &fe) {
+			return err
+		}
+	}
+	if fe == nil {
+		return nil
+	}
+
+	ic.logger.Warn("[KAVA-CLIENT] Hash mismatch detected", zap.Uint64("height", fe.Height), zap.String("hash", fe.Hash), zap.String("parent_hash", fe.ParentHash),
+		zap.Uint64("known_height", fe.KnownHeight), zap.String("known_hash", fe.KnownHash))
+	hashMismatchMetric.WithLabels().Inc()
+
+	ic.links.forget(fe.Height, fe.KnownHeight)
+	ic.rpcCli.InvalidateBlock(fe.Height)
+	ic.rpcCli.InvalidateBlock(fe.KnownHeight)
+	// confirmations would raise the same error on every attempt to index heights again
+	if hasConfirmed {
+		if err := chg.UnconfirmHeights(ctx, []uint64{fe.Height, fe.KnownHeight}); err != nil {
+			ic.logger.Error("[KAVA-CLIENT] Error removing confirmations of mismatched heights", zap.Error(err), zap.Uint64("height", fe.Height), zap.Uint64("known_height", fe.KnownHeight))
+		}
+	}
+	return fe
+}
+
+func checkConfirmed(ctx context.Context, chg ConfirmedHashGetter, height uint64, link blockLink) error {
+	hash, ok, err := chg.GetConfirmedHash(ctx, height)
+	if err != nil {
+		return err
+	}
+	if ok && hash != link.hash {
+		return &ForkError{Height: height, Hash: link.hash, ParentHash: link.parentHash, KnownHeight: height, KnownHash: hash}
+	}
+
+	if height < 2 || link.parentHash == "" {
+		return nil
+	}
+	hash, ok, err = chg.GetConfirmedHash(ctx, height-1)
+	if err != nil {
+		return err
+	}
+	if ok && hash != link.parentHash {
+		return &ForkError{Height: height, Hash: link.hash, ParentHash: link.parentHash, KnownHeight: height - 1, KnownHash: hash}
+	}
+	return nil
 }
 
 // GetTransactions gets new transactions and blocks from kava for given range
@@ -69,10 +175,10 @@ func (ic *IndexerClient) GetTransactions(ctx context.Context, tr cStructs.TaskRe
 		})
 		return
 	}
-	if hr.EndHeight == 0 {
+	if err := ic.checkRange(*hr); err != nil {
 		stream.Send(cStructs.TaskResponse{
 			Id:    tr.Id,
-			Error: cStructs.TaskError{Msg: "end height is zero" + err.Error()},
+			Error: cStructs.TaskError{Msg: err.Error()},
 			Final: true,
 		})
 		return
@@ -80,22 +186,33 @@ func (ic *IndexerClient) GetTransactions(ctx context.Context, tr cStructs.TaskRe
 
 	ic.logger.Debug("[KAVA-CLIENT] Getting Range", zap.Stringer("taskID", tr.Id), zap.Uint64("start", hr.StartHeight), zap.Uint64("end", hr.EndHeight))
 
-	heights, err := ic.Reqester.GetRange(ctx, *hr)
+	rr := ic.newStoringRangeRequester()
+	rr.OnHeight = func(uint64) { ic.Tasks.Progress(tr.Id) }
+	heights, err := rr.GetRange(ctx, *hr)
 	resp := &cStructs.TaskResponse{
 		Id:    tr.Id,
 		Type:  "Heights",
 		Final: true,
 	}
-	if heights.NumberOfHeights > 0 {
+	if heights.NumberOfHeights > 0 || len(heights.Failed) > 0 {
 		resp.Payload, _ = json.Marshal(heights)
 	}
 	if err != nil {
 		resp.Error = cStructs.TaskError{Msg: err.Error()}
-		ic.logger.Error("[KAVA-CLIENT] Error getting range (Get Transactions) ", zap.Error(err), zap.Stringer("taskID", tr.Id))
-		if err := stream.Send(*resp); err != nil {
-			ic.logger.Error("[KAVA-CLIENT] Error sending message (Get Transactions) ", zap.Error(err), zap.Stringer("taskID", tr.Id))
+		ic.logger.Error("[KAVA-CLIENT] Error getting range (Get Transactions) ", zap.Error(err), zap.Stringer("taskID", tr.Id), zap.Int("failed", len(heights.Failed)))
+
+		// let the manager know which heights have to be invalidated and indexed again
+		for _, he := range heights.Failed {
+			if he.Fork == nil {
+				continue
+			}
+			mResp := cStructs.TaskResponse{Id: tr.Id, Type: "HashMismatch", Order: resp.Order}
+			mResp.Payload, _ = json.Marshal(he.Fork)
+			if err := stream.Send(mResp); err != nil {
+				ic.logger.Error("[KAVA-CLIENT] Error sending message (Get Transactions) ", zap.Error(err), zap.Stringer("taskID", tr.Id))
+			}
+			resp.Order++
 		}
-		return
 	}
 	if err := stream.Send(*resp); err != nil {
 		ic.logger.Error("[KAVA-CLIENT] Error sending message (Get Transactions) ", zap.Error(err), zap.Stringer("taskID", tr.Id))
@@ -1,18 +1,67 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"io"
 
+	"github.com/figment-networks/indexing-engine/structs"
 	"github.com/figment-networks/kava-worker/api"
 	"go.uber.org/zap"
 )
 
-var cli *api.Client
+// Version of the plugin interface, loader should check it before looking up other symbols.
+// Major version changes when signature of any exported function changes.
+var Version = "2.0.0"
+
+// CodecVersion is the codec version transactions are decoded with
+var CodecVersion = api.DefaultCodec
+
+var (
+	cli *api.Client
+	dec *api.Decoder
+)
 
 func init() {
-	cli = api.NewClient("", "", nil, nil, 0)
+	cli = api.NewClient("", "", zap.NewNop(), nil, 0)
+
+	var err error
+	if dec, err = api.NewDecoder(CodecVersion, zap.NewNop()); err != nil {
+		panic(err)
+	}
 }
 
+// DecodeFee returns fee coins of base64 encoded transaction
 func DecodeFee(logger *zap.Logger, reader io.Reader) []map[string]interface{} {
 	return cli.GetFromRaw(logger, reader)
 }
+
+// DecodeTransaction converts Raw (base64 encoded transaction) and RawLog of the transaction,
+// the same way as worker does. Block hash, chain id and time are not set.
+func DecodeTransaction(logger *zap.Logger, raw, rawLog []byte) (structs.Transaction, error) {
+	return dec.WithLogger(logger).Raw(context.Background(), raw, rawLog)
+}
+
+// DecodeMessages returns messages of base64 encoded transaction, each with
+// "index", "route", "type", "signers" ([]string) and "amino" (json.RawMessage) keys
+func DecodeMessages(logger *zap.Logger, raw []byte) ([]map[string]interface{}, error) {
+	msgs, err := dec.WithLogger(logger).Messages(string(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	slice := make([]map[string]interface{}, len(msgs))
+	for i, m := range msgs {
+		slice[i] = map[string]interface{}{
+			"index":   m.Index,
+			"route":   m.Route,
+			"type":    m.Type,
+			"signers": m.Signers,
+			"amino":   json.RawMessage(m.Amino),
+		}
+	}
+	return slice, nil
+}
+
+// main is required to build the package outside of -buildmode=plugin
+func main() {}
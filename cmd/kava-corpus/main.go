@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/figment-networks/kava-worker/api"
+	"github.com/figment-networks/kava-worker/api/recorder"
+	"github.com/figment-networks/kava-worker/api/types"
+	"github.com/figment-networks/kava-worker/cmd/common/logger"
+)
+
+// syntheticMemos are memo prefixes of transactions constructed by hand (corpus entries and bundled fixtures),
+// such transactions are never taken as recorded ones
+var syntheticMemos = []string{"golden ", "fixture"}
+
+type flags struct {
+	fixturesDir string
+	out         string
+	codec       string
+	overwrite   bool
+}
+
+var configFlags = flags{}
+
+func init() {
+	flag.StringVar(&configFlags.fixturesDir, "fixtures", "", "Directory of fixtures recorded from the chain (RECORD_FIXTURES_DIR of the worker)")
+	flag.StringVar(&configFlags.out, "out", "api/testdata/corpus", "Corpus directory")
+	flag.StringVar(&configFlags.codec, "codec", api.DefaultCodec, "Codec version")
+	flag.BoolVar(&configFlags.overwrite, "overwrite", false, "Replace recorded corpus entries as well (synthetic ones are always replaced)")
+}
+
+// kava-corpus copies successful transactions of recorded /tx_search responses into the golden corpus,
+// named <route>_<type>.json after the first message of the transaction.
+// Written entries are printed to stdout, golden files have to be updated afterwards.
+func main() {
+	flag.Parse()
+	if configFlags.fixturesDir == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	if err := logger.Init("console", "info", []string{"stderr"}, nil); err != nil {
+		log.Fatalf("error initializing logger [ERR: %v]", err.Error())
+	}
+	defer logger.Sync()
+
+	dec, err := api.NewDecoder(configFlags.codec, logger.GetLogger())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	written, err := extract(dec, configFlags.fixturesDir, configFlags.out, configFlags.overwrite)
+	for _, name := range written {
+		fmt.Println(name)
+	}
+	if err != nil {
+		logger.Error(err)
+		os.Exit(1)
+	}
+}
+
+// extract writes corpus entries of recorded transactions, first transaction of every message type wins
+func extract(dec *api.Decoder, fixturesDir, out string, overwrite bool) (written []string, err error) {
+	fixtures, err := recorder.List(fixturesDir)
+	if err != nil {
+		return nil, fmt.Errorf("error listing fixtures: %w", err)
+	}
+
+	seen := map[string]bool{}
+	for _, f := range fixtures {
+		if f.Path != "/tx_search" || f.Status != 200 || f.Body == nil {
+			continue
+		}
+		resp := struct {
+			Result struct {
+				Txs []json.RawMessage `json:"txs"`
+			} `json:"result"`
+		}{}
+		if err := json.Unmarshal(f.Body, &resp); err != nil {
+			return written, fmt.Errorf("error decoding /tx_search fixture: %w", err)
+		}
+
+		for _, raw := range resp.Result.Txs {
+			name, err := entryName(dec, raw)
+			if err != nil {
+				return written, err
+			}
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			path := filepath.Join(out, name+".json")
+			recorded, err := isRecorded(dec, path)
+			if err != nil {
+				return written, err
+			}
+			if recorded && !overwrite {
+				continue
+			}
+
+			b := &bytes.Buffer{}
+			if err := json.Indent(b, raw, "", "  "); err != nil {
+				return written, fmt.Errorf("error encoding corpus entry %s: %w", name, err)
+			}
+			b.WriteByte('\n')
+			if err := ioutil.WriteFile(path, b.Bytes(), 0644); err != nil {
+				return written, fmt.Errorf("error writing corpus entry %s: %w", name, err)
+			}
+			written = append(written, name)
+		}
+	}
+	return written, nil
+}
+
+// entryName names transaction after its first message, failed transactions are not named
+func entryName(dec *api.Decoder, raw json.RawMessage) (string, error) {
+	in := types.TxResponse{}
+	if err := json.Unmarshal(raw, &in); err != nil {
+		return "", fmt.Errorf("error decoding recorded transaction: %w", err)
+	}
+	if in.TxResult.Code != 0 {
+		return "", nil
+	}
+	s, err := synthetic(dec, in.TxData)
+	if err != nil {
+		return "", fmt.Errorf("error decoding recorded transaction %s: %w", in.Hash, err)
+	}
+	if s {
+		return "", nil
+	}
+	msgs, err := dec.Messages(in.TxData)
+	if err != nil {
+		return "", fmt.Errorf("error decoding recorded transaction %s: %w", in.Hash, err)
+	}
+	if len(msgs) == 0 {
+		return "", nil
+	}
+	return msgs[0].Route + "_" + msgs[0].Type, nil
+}
+
+// isRecorded tells if the corpus entry exists and was not constructed by hand
+func isRecorded(dec *api.Decoder, path string) (bool, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	in := types.TxResponse{}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return false, fmt.Errorf("error decoding corpus entry %s: %w", path, err)
+	}
+	s, err := synthetic(dec, in.TxData)
+	if err != nil {
+		return false, fmt.Errorf("error decoding corpus entry %s: %w", path, err)
+	}
+	return !s, nil
+}
+
+// synthetic tells if the transaction was constructed by hand, by its memo
+func synthetic(dec *api.Decoder, txData string) (bool, error) {
+	amino, err := dec.AminoJSON(txData)
+	if err != nil {
+		return false, err
+	}
+	tx := struct {
+		Value struct {
+			Memo string `json:"memo"`
+		} `json:"value"`
+	}{}
+	if err := json.Unmarshal(amino, &tx); err != nil {
+		return false, err
+	}
+	for _, memo := range syntheticMemos {
+		if strings.HasPrefix(tx.Value.Memo, memo) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
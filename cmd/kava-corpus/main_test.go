@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/figment-networks/kava-worker/api"
+	"github.com/figment-networks/kava-worker/api/recorder"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+	"github.com/cosmos/cosmos-sdk/x/gov"
+	"go.uber.org/zap"
+)
+
+func encodeTx(t *testing.T, dec *api.Decoder, msg sdk.Msg, memo string) string {
+	t.Helper()
+	tx := auth.NewStdTx([]sdk.Msg{msg}, auth.NewStdFee(200000, sdk.NewCoins(sdk.NewInt64Coin("ukava", 5000))), nil, memo)
+	data, err := dec.Codec().MarshalBinaryLengthPrefixed(tx)
+	if err != nil {
+		t.Fatalf("error encoding transaction: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func searchResult(hash string, code int, txData string) map[string]interface{} {
+	return map[string]interface{}{
+		"hash":      hash,
+		"height":    "2000",
+		"index":     0,
+		"tx":        txData,
+		"tx_result": map[string]interface{}{"code": code, "log": "[]"},
+	}
+}
+
+func writeEntry(t *testing.T, dir, name string, entry map[string]interface{}) {
+	t.Helper()
+	data, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, name+".json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	dec, err := api.NewDecoder(api.DefaultCodec, zap.NewNop())
+	if err != nil {
+		t.Fatalf("error creating decoder: %v", err)
+	}
+
+	from := sdk.AccAddress([]byte("from________________"))
+	to := sdk.AccAddress([]byte("to__________________"))
+	send := bank.NewMsgSend(from, to, sdk.NewCoins(sdk.NewInt64Coin("ukava", 100)))
+	vote := gov.NewMsgVote(from, 1, gov.OptionYes)
+
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"result": map[string]interface{}{"txs": []interface{}{
+			searchResult("FAILED", 5, encodeTx(t, dec, send, "")),
+			searchResult("HANDMADE", 0, encodeTx(t, dec, send, "fixture")),
+			searchResult("SEND", 0, encodeTx(t, dec, send, "")),
+			searchResult("SEND2", 0, encodeTx(t, dec, send, "")),
+			searchResult("VOTE", 0, encodeTx(t, dec, vote, "")),
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixtures := t.TempDir()
+	if err := recorder.Save(fixtures, recorder.Fixture{Method: "GET", Path: "/tx_search", Query: "query=tx.height%3D2000", Status: 200, Body: body}); err != nil {
+		t.Fatal(err)
+	}
+
+	readHash := func(dir, name string) string {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name+".json"))
+		if err != nil {
+			t.Fatalf("error reading entry %s: %v", name, err)
+		}
+		entry := struct{ Hash string }{}
+		if err := json.Unmarshal(data, &entry); err != nil {
+			t.Fatal(err)
+		}
+		return entry.Hash
+	}
+
+	tests := []struct {
+		name      string
+		existing  map[string]map[string]interface{}
+		overwrite bool
+		want      []string
+		wantHash  map[string]string
+	}{
+		{
+			name:     "empty corpus",
+			want:     []string{"bank_send", "gov_vote"},
+			wantHash: map[string]string{"bank_send": "SEND", "gov_vote": "VOTE"},
+		},
+		{
+			name: "synthetic entry is replaced, recorded one is kept",
+			existing: map[string]map[string]interface{}{
+				"bank_send": searchResult("OLD_SEND", 0, encodeTx(t, dec, send, "golden bank_send")),
+				"gov_vote":  searchResult("OLD_VOTE", 0, encodeTx(t, dec, vote, "")),
+			},
+			want:     []string{"bank_send"},
+			wantHash: map[string]string{"bank_send": "SEND", "gov_vote": "OLD_VOTE"},
+		},
+		{
+			name: "overwrite",
+			existing: map[string]map[string]interface{}{
+				"gov_vote": searchResult("OLD_VOTE", 0, encodeTx(t, dec, vote, "")),
+			},
+			overwrite: true,
+			want:      []string{"bank_send", "gov_vote"},
+			wantHash:  map[string]string{"bank_send": "SEND", "gov_vote": "VOTE"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := t.TempDir()
+			for name, entry := range tt.existing {
+				writeEntry(t, out, name, entry)
+			}
+
+			written, err := extract(dec, fixtures, out, tt.overwrite)
+			if err != nil {
+				t.Fatalf("extract() error = %v", err)
+			}
+			if !reflect.DeepEqual(written, tt.want) {
+				t.Errorf("extract() = %v, want %v", written, tt.want)
+			}
+			for name, hash := range tt.wantHash {
+				if got := readHash(out, name); got != hash {
+					t.Errorf("entry %s has hash %s, want %s", name, got, hash)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractSkipsBundledFixtures(t *testing.T) {
+	dec, err := api.NewDecoder(api.DefaultCodec, zap.NewNop())
+	if err != nil {
+		t.Fatalf("error creating decoder: %v", err)
+	}
+	written, err := extract(dec, filepath.Join("..", "..", "api", "testdata", "fixtures"), t.TempDir(), false)
+	if err != nil {
+		t.Fatalf("extract() error = %v", err)
+	}
+	if len(written) != 0 {
+		t.Errorf("hand made fixtures were taken as recorded: %v", written)
+	}
+}
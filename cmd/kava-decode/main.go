@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/figment-networks/kava-worker/api"
+	"github.com/figment-networks/kava-worker/api/recorder"
+	"github.com/figment-networks/kava-worker/api/types"
+	"github.com/figment-networks/kava-worker/cmd/common/logger"
+)
+
+type flags struct {
+	tx          string
+	search      string
+	height      uint64
+	fixturesDir string
+	codec       string
+	amino       bool
+}
+
+var configFlags = flags{}
+
+func init() {
+	flag.StringVar(&configFlags.tx, "tx", "", "Base64 encoded transaction (- reads stdin)")
+	flag.StringVar(&configFlags.search, "search", "", "File with /tx_search response (- reads stdin)")
+	flag.Uint64Var(&configFlags.height, "height", 0, "Height to decode from recorded fixtures")
+	flag.StringVar(&configFlags.fixturesDir, "fixtures", "api/testdata/fixtures", "Directory of recorded fixtures (used with -height)")
+	flag.StringVar(&configFlags.codec, "codec", api.DefaultCodec, "Codec version")
+	flag.BoolVar(&configFlags.amino, "amino", false, "Print raw amino JSON of the transaction instead (used with -tx)")
+}
+
+// kava-decode converts transactions the same way as the worker, without the node.
+// Input is either single base64 encoded transaction, /tx_search response or height of recorded fixtures.
+// Result is written to stdout as JSON.
+func main() {
+	flag.Parse()
+
+	var inputs int
+	for _, set := range []bool{configFlags.tx != "", configFlags.search != "", configFlags.height > 0} {
+		if set {
+			inputs++
+		}
+	}
+	if inputs != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	if err := logger.Init("console", "info", []string{"stderr"}, nil); err != nil {
+		log.Fatalf("error initializing logger [ERR: %v]", err.Error())
+	}
+	defer logger.Sync()
+
+	dec, err := api.NewDecoder(configFlags.codec, logger.GetLogger())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	out, err := decode(context.Background(), dec)
+	if err != nil {
+		logger.Error(err)
+		os.Exit(1)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		logger.Error(fmt.Errorf("error encoding output: %w", err))
+		os.Exit(1)
+	}
+}
+
+func decode(ctx context.Context, dec *api.Decoder) (interface{}, error) {
+	switch {
+	case configFlags.tx != "":
+		data, err := readInput(configFlags.tx, false)
+		if err != nil {
+			return nil, err
+		}
+		if configFlags.amino {
+			return dec.AminoJSON(string(data))
+		}
+		in, err := api.TxResponseFromBase64(string(data))
+		if err != nil {
+			return nil, err
+		}
+		return dec.Transaction(ctx, in)
+
+	case configFlags.search != "":
+		data, err := readInput(configFlags.search, true)
+		if err != nil {
+			return nil, err
+		}
+		result, err := parseSearch(data)
+		if err != nil {
+			return nil, err
+		}
+		return dec.Search(ctx, result, nil)
+
+	default:
+		tr, err := recorder.New(configFlags.fixturesDir, recorder.ModeReplay, nil)
+		if err != nil {
+			return nil, err
+		}
+		c := api.NewClient("http://fixtures", "", logger.GetLogger(), &http.Client{Transport: tr}, 100)
+		c.SetCodec(dec.Codec())
+
+		block, err := c.GetBlock(ctx, structs.HeightHash{Height: configFlags.height})
+		if err != nil {
+			return nil, fmt.Errorf("error getting block: %w", err)
+		}
+		return c.SearchTx(ctx, structs.HeightHash{Height: configFlags.height}, block, 100)
+	}
+}
+
+// parseSearch accepts either whole JSON-RPC response or its result only
+func parseSearch(data []byte) (result types.ResultTxSearch, err error) {
+	resp := &types.GetTxSearchResponse{}
+	if err := json.Unmarshal(data, resp); err != nil {
+		return result, fmt.Errorf("error decoding /tx_search response: %w", err)
+	}
+	if resp.Error.Message != "" {
+		return result, fmt.Errorf("error in /tx_search response: %s", resp.Error.Message)
+	}
+	if resp.Result.Txs != nil {
+		return resp.Result, nil
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return result, fmt.Errorf("error decoding /tx_search result: %w", err)
+	}
+	return result, nil
+}
+
+// readInput returns stdin contents for "-", the file contents when value is a path or the value itself
+func readInput(value string, path bool) ([]byte, error) {
+	var r io.Reader
+	switch {
+	case value == "-":
+		r = os.Stdin
+	case path:
+		f, err := os.Open(value)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	default:
+		return []byte(value), nil
+	}
+	return ioutil.ReadAll(r)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSearch(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantTxs   []string
+		wantTotal string
+		wantErr   string
+	}{
+		{
+			name:      "whole response",
+			data:      `{"jsonrpc":"2.0","id":-1,"result":{"txs":[{"hash":"AA","height":"10"},{"hash":"BB","height":"10"}],"total_count":"2"}}`,
+			wantTxs:   []string{"AA", "BB"},
+			wantTotal: "2",
+		},
+		{
+			name:      "whole response without transactions",
+			data:      `{"jsonrpc":"2.0","id":-1,"result":{"txs":[],"total_count":"0"}}`,
+			wantTotal: "0",
+		},
+		{
+			name:      "result only",
+			data:      `{"txs":[{"hash":"CC","height":"11"}],"total_count":"1"}`,
+			wantTxs:   []string{"CC"},
+			wantTotal: "1",
+		},
+		{
+			name:    "error response",
+			data:    `{"jsonrpc":"2.0","id":-1,"error":{"code":-32603,"message":"Internal error","data":"page should be within [0, 1] range"}}`,
+			wantErr: "error in /tx_search response: Internal error",
+		},
+		{
+			name:    "not json",
+			data:    `txs`,
+			wantErr: "error decoding /tx_search response",
+		},
+		{
+			name:    "result of wrong type",
+			data:    `{"txs":"AA"}`,
+			wantErr: "error decoding /tx_search result",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseSearch([]byte(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.TotalCount != tt.wantTotal {
+				t.Errorf("expected total count %q, got %q", tt.wantTotal, result.TotalCount)
+			}
+			if len(result.Txs) != len(tt.wantTxs) {
+				t.Fatalf("expected %d transactions, got %d", len(tt.wantTxs), len(result.Txs))
+			}
+			for i, hash := range tt.wantTxs {
+				if result.Txs[i].Hash != hash {
+					t.Errorf("transaction %d: expected hash %s, got %s", i, hash, result.Txs[i].Hash)
+				}
+			}
+		})
+	}
+}
+
+func TestReadInput(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "search.json")
+	if err := ioutil.WriteFile(path, []byte("from file"), 0600); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+	stdin := filepath.Join(dir, "stdin")
+	if err := ioutil.WriteFile(stdin, []byte("from stdin"), 0600); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		path    bool
+		want    string
+		wantErr bool
+	}{
+		{name: "value", value: "CpYBCh", want: "CpYBCh"},
+		{name: "value that looks like path", value: path, want: path},
+		{name: "file", value: path, path: true, want: "from file"},
+		{name: "missing file", value: filepath.Join(dir, "missing.json"), path: true, wantErr: true},
+		{name: "stdin", value: "-", want: "from stdin"},
+		{name: "stdin instead of path", value: "-", path: true, want: "from stdin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := os.Open(stdin)
+			if err != nil {
+				t.Fatalf("error opening file: %v", err)
+			}
+			defer f.Close()
+			orig := os.Stdin
+			os.Stdin = f
+			defer func() { os.Stdin = orig }()
+
+			data, err := readInput(tt.value, tt.path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, data)
+			}
+		})
+	}
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/figment-networks/kava-worker/api"
+	"github.com/figment-networks/kava-worker/api/recorder"
+	"github.com/figment-networks/kava-worker/sink"
+
+	"go.uber.org/zap"
+)
+
+const page = 100
+
+// Kinds of transaction differences
+const (
+	StatusChanged = "changed"
+	StatusAdded   = "added"
+	StatusRemoved = "removed"
+)
+
+// Change is a single structural difference, Old or New is missing when the path exists only on one side
+type Change struct {
+	Path string      `json:"path"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// TxDiff lists differences of a single transaction
+type TxDiff struct {
+	Hash    string   `json:"hash"`
+	Height  uint64   `json:"height"`
+	Status  string   `json:"status"`
+	Changes []Change `json:"changes,omitempty"`
+}
+
+// Group summarizes differences of transactions with the same route/type
+type Group struct {
+	Transactions int      `json:"transactions"`
+	Changed      int      `json:"changed"`
+	Added        int      `json:"added"`
+	Removed      int      `json:"removed"`
+	Diffs        []TxDiff `json:"diffs,omitempty"`
+}
+
+// Report is a summary of differences between baseline and current output
+type Report struct {
+	StartHeight    uint64            `json:"start_height"`
+	EndHeight      uint64            `json:"end_height"`
+	Baseline       string            `json:"baseline"`
+	Current        string            `json:"current"`
+	SkippedHeights []uint64          `json:"skipped_heights,omitempty"`
+	Transactions   int               `json:"transactions"`
+	Different      int               `json:"different"`
+	Groups         map[string]*Group `json:"groups"`
+}
+
+// Source produces transactions of the range, keyed by hash
+type Source interface {
+	Transactions(ctx context.Context, start, end uint64) (txs map[string]structs.Transaction, skipped []uint64, err error)
+}
+
+// FixtureSource converts transactions from recorded fixtures
+type FixtureSource struct {
+	client *api.Client
+}
+
+// NewFixtureSource is FixtureSource constructor, transactions are decoded with given codec version
+func NewFixtureSource(dir, codec string, logger *zap.Logger) (*FixtureSource, error) {
+	dec, err := api.NewDecoder(codec, logger)
+	if err != nil {
+		return nil, err
+	}
+	tr, err := recorder.New(dir, recorder.ModeReplay, nil)
+	if err != nil {
+		return nil, err
+	}
+	c := api.NewClient("http://fixtures", "", logger, &http.Client{Transport: tr}, 1000)
+	c.SetCodec(dec.Codec())
+	return &FixtureSource{client: c}, nil
+}
+
+// Transactions converts recorded heights of the range, heights without block fixture are skipped
+func (fs *FixtureSource) Transactions(ctx context.Context, start, end uint64) (txs map[string]structs.Transaction, skipped []uint64, err error) {
+	txs = make(map[string]structs.Transaction)
+	for h := start; h <= end; h++ {
+		block, err := fs.client.GetBlock(ctx, structs.HeightHash{Height: h})
+		if errors.Is(err, recorder.ErrNoFixture) {
+			skipped = append(skipped, h)
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("error getting block %d: %w", h, err)
+		}
+		if block.NumberOfTransactions == 0 {
+			continue
+		}
+
+		hTxs, err := fs.client.SearchTx(ctx, structs.HeightHash{Height: h}, block, page)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error getting transactions of height %d: %w", h, err)
+		}
+		for _, t := range hTxs {
+			txs[t.Hash] = t
+		}
+	}
+	return txs, skipped, nil
+}
+
+// JSONLSource reads transactions from files written by the JSONL sink
+type JSONLSource struct {
+	pattern string
+}
+
+// NewJSONLSource is JSONLSource constructor, pattern is a path or glob of exported files
+func NewJSONLSource(pattern string) *JSONLSource {
+	return &JSONLSource{pattern: pattern}
+}
+
+// Transactions reads exported transactions of the range
+func (js *JSONLSource) Transactions(ctx context.Context, start, end uint64) (txs map[string]structs.Transaction, skipped []uint64, err error) {
+	files, err := filepath.Glob(js.pattern)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(files) == 0 {
+		return nil, nil, fmt.Errorf("no files matching %q", js.pattern)
+	}
+
+	txs = make(map[string]structs.Transaction)
+	for _, name := range files {
+		if err := readJSONL(name, start, end, txs); err != nil {
+			return nil, nil, err
+		}
+	}
+	return txs, nil, nil
+}
+
+func readJSONL(name string, start, end uint64, txs map[string]structs.Transaction) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		r := sink.Record{}
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return fmt.Errorf("error decoding %s:%d: %w", name, line, err)
+		}
+		if r.Kind != sink.KindTransaction || r.Transaction == nil || r.Height < start || r.Height > end {
+			continue
+		}
+		txs[r.Transaction.Transaction.Hash] = r.Transaction.Transaction
+	}
+	return scanner.Err()
+}
+
+// Compare reports differences between baseline and current transactions, listing at most maxDiffs transactions per group
+func Compare(baseline, current map[string]structs.Transaction, maxDiffs int) (rep Report, err error) {
+	rep.Groups = make(map[string]*Group)
+
+	hashes := make([]string, 0, len(current))
+	for h := range current {
+		hashes = append(hashes, h)
+	}
+	for h := range baseline {
+		if _, ok := current[h]; !ok {
+			hashes = append(hashes, h)
+		}
+	}
+	sort.Strings(hashes)
+
+	for _, hash := range hashes {
+		old, inOld := baseline[hash]
+		cur, inCur := current[hash]
+
+		var d TxDiff
+		switch {
+		case !inOld:
+			d = TxDiff{Hash: hash, Height: cur.Height, Status: StatusAdded}
+		case !inCur:
+			d = TxDiff{Hash: hash, Height: old.Height, Status: StatusRemoved}
+			cur = old
+		default:
+			d = TxDiff{Hash: hash, Height: cur.Height, Status: StatusChanged}
+			if d.Changes, err = diffTransactions(old, cur); err != nil {
+				return rep, fmt.Errorf("error comparing transaction %s: %w", hash, err)
+			}
+		}
+
+		key := groupKey(cur)
+		g, ok := rep.Groups[key]
+		if !ok {
+			g = &Group{}
+			rep.Groups[key] = g
+		}
+		g.Transactions++
+		rep.Transactions++
+
+		if d.Status == StatusChanged && len(d.Changes) == 0 {
+			continue
+		}
+		switch d.Status {
+		case StatusAdded:
+			g.Added++
+		case StatusRemoved:
+			g.Removed++
+		default:
+			g.Changed++
+		}
+		rep.Different++
+		if len(g.Diffs) < maxDiffs {
+			g.Diffs = append(g.Diffs, d)
+		}
+	}
+	return rep, nil
+}
+
+// groupKey is the sorted list of distinct route/type pairs of transaction messages
+func groupKey(t structs.Transaction) string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, ev := range t.Events {
+		if ev.Kind == "" || ev.Kind == "error" || ev.Kind == api.ValuationEventKind {
+			continue
+		}
+		module := ""
+		if len(ev.Sub) > 0 {
+			module = ev.Sub[0].Module
+		}
+		k := module + "/" + ev.Kind
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return "unknown"
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+// diffTransactions compares JSON representations of transactions.
+// Valuation is left out, as it depends on prices available to the worker and FixtureSource doesn't value transactions.
+func diffTransactions(old, cur structs.Transaction) (changes []Change, err error) {
+	o, err := toGeneric(withoutValuation(old))
+	if err != nil {
+		return nil, err
+	}
+	c, err := toGeneric(withoutValuation(cur))
+	if err != nil {
+		return nil, err
+	}
+	return diffValues("", o, c, changes), nil
+}
+
+func withoutValuation(t structs.Transaction) structs.Transaction {
+	events := make(structs.TransactionEvents, 0, len(t.Events))
+	for _, ev := range t.Events {
+		if ev.Kind != api.ValuationEventKind {
+			events = append(events, ev)
+		}
+	}
+	t.Events = events
+	return t
+}
+
+// toGeneric converts transaction into JSON values, keeping numbers as they are (amounts may exceed float precision)
+func toGeneric(t structs.Transaction) (v interface{}, err error) {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return v, dec.Decode(&v)
+}
+
+// diffValues walks both values, arrays are compared by index
+func diffValues(path string, old, cur interface{}, changes []Change) []Change {
+	switch o := old.(type) {
+	case map[string]interface{}:
+		c, ok := cur.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(o)+len(c))
+		for k := range o {
+			keys = append(keys, k)
+		}
+		for k := range c {
+			if _, ok := o[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			ov, inOld := o[k]
+			cv, inCur := c[k]
+			p := joinPath(path, k)
+			switch {
+			case !inOld:
+				changes = append(changes, Change{Path: p, New: cv})
+			case !inCur:
+				changes = append(changes, Change{Path: p, Old: ov})
+			default:
+				changes = diffValues(p, ov, cv, changes)
+			}
+		}
+		return changes
+	case []interface{}:
+		c, ok := cur.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(o) || i < len(c); i++ {
+			p := joinPath(path, strconv.Itoa(i))
+			switch {
+			case i >= len(o):
+				changes = append(changes, Change{Path: p, New: c[i]})
+			case i >= len(c):
+				changes = append(changes, Change{Path: p, Old: o[i]})
+			default:
+				changes = diffValues(p, o[i], c[i], changes)
+			}
+		}
+		return changes
+	}
+
+	if !reflect.DeepEqual(old, cur) {
+		changes = append(changes, Change{Path: path, Old: old, New: cur})
+	}
+	return changes
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/figment-networks/kava-worker/api"
+)
+
+func TestDiffValues(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		cur  string
+		want []Change
+	}{
+		{
+			name: "equal",
+			old:  `{"a": 1, "b": ["x", "y"]}`,
+			cur:  `{"b": ["x", "y"], "a": 1}`,
+		},
+		{
+			name: "changed value",
+			old:  `{"a": {"b": "x"}}`,
+			cur:  `{"a": {"b": "y"}}`,
+			want: []Change{{Path: "a.b", Old: "x", New: "y"}},
+		},
+		{
+			name: "added key",
+			old:  `{"a": 1}`,
+			cur:  `{"a": 1, "b": 2}`,
+			want: []Change{{Path: "b", New: json.Number("2")}},
+		},
+		{
+			name: "removed key",
+			old:  `{"a": 1, "b": 2}`,
+			cur:  `{"a": 1}`,
+			want: []Change{{Path: "b", Old: json.Number("2")}},
+		},
+		{
+			name: "added array element",
+			old:  `{"a": ["x"]}`,
+			cur:  `{"a": ["x", "y"]}`,
+			want: []Change{{Path: "a.1", New: "y"}},
+		},
+		{
+			name: "removed array element",
+			old:  `{"a": ["x", "y"]}`,
+			cur:  `{"a": ["x"]}`,
+			want: []Change{{Path: "a.1", Old: "y"}},
+		},
+		{
+			name: "changed type",
+			old:  `{"a": ["x"]}`,
+			cur:  `{"a": "x"}`,
+			want: []Change{{Path: "a", Old: []interface{}{"x"}, New: "x"}},
+		},
+		{
+			name: "big numbers",
+			old:  `{"a": 100000000000000000001}`,
+			cur:  `{"a": 100000000000000000002}`,
+			want: []Change{{Path: "a", Old: json.Number("100000000000000000001"), New: json.Number("100000000000000000002")}},
+		},
+	}
+
+	generic := func(t *testing.T, s string) (v interface{}) {
+		t.Helper()
+		dec := json.NewDecoder(strings.NewReader(s))
+		dec.UseNumber()
+		if err := dec.Decode(&v); err != nil {
+			t.Fatalf("error decoding %s: %v", s, err)
+		}
+		return v
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diffValues("", generic(t, tt.old), generic(t, tt.cur), nil)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffValues() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func sendTx(hash string, height uint64, memo string) structs.Transaction {
+	return structs.Transaction{
+		Hash:   hash,
+		Height: height,
+		Memo:   memo,
+		Events: structs.TransactionEvents{{
+			Kind: "send",
+			Sub:  []structs.SubsetEvent{{Type: []string{"send"}, Module: "bank"}},
+		}},
+	}
+}
+
+func TestCompare(t *testing.T) {
+	valued := sendTx("VALUED", 1, "")
+	valued.Events = append(valued.Events, structs.TransactionEvent{Kind: api.ValuationEventKind})
+
+	tests := []struct {
+		name      string
+		baseline  map[string]structs.Transaction
+		current   map[string]structs.Transaction
+		maxDiffs  int
+		want      map[string]Group
+		different int
+	}{
+		{
+			name:     "unchanged",
+			baseline: map[string]structs.Transaction{"A": sendTx("A", 1, "")},
+			current:  map[string]structs.Transaction{"A": sendTx("A", 1, "")},
+			maxDiffs: 10,
+			want:     map[string]Group{"bank/send": {Transactions: 1}},
+		},
+		{
+			name:     "added, removed and changed",
+			baseline: map[string]structs.Transaction{"A": sendTx("A", 1, ""), "B": sendTx("B", 1, "old")},
+			current:  map[string]structs.Transaction{"B": sendTx("B", 1, "new"), "C": sendTx("C", 2, "")},
+			maxDiffs: 10,
+			want: map[string]Group{"bank/send": {Transactions: 3, Changed: 1, Added: 1, Removed: 1, Diffs: []TxDiff{
+				{Hash: "A", Height: 1, Status: StatusRemoved},
+				{Hash: "B", Height: 1, Status: StatusChanged, Changes: []Change{{Path: "memo", Old: "old", New: "new"}}},
+				{Hash: "C", Height: 2, Status: StatusAdded},
+			}}},
+			different: 3,
+		},
+		{
+			name:     "listed diffs are limited",
+			baseline: map[string]structs.Transaction{},
+			current:  map[string]structs.Transaction{"A": sendTx("A", 1, ""), "B": sendTx("B", 1, "")},
+			maxDiffs: 1,
+			want: map[string]Group{"bank/send": {Transactions: 2, Added: 2, Diffs: []TxDiff{
+				{Hash: "A", Height: 1, Status: StatusAdded},
+			}}},
+			different: 2,
+		},
+		{
+			name:     "valuation is not compared",
+			baseline: map[string]structs.Transaction{"VALUED": valued},
+			current:  map[string]structs.Transaction{"VALUED": sendTx("VALUED", 1, "")},
+			maxDiffs: 10,
+			want:     map[string]Group{"bank/send": {Transactions: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rep, err := Compare(tt.baseline, tt.current, tt.maxDiffs)
+			if err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+			if rep.Different != tt.different {
+				t.Errorf("Different = %d, want %d", rep.Different, tt.different)
+			}
+			got := map[string]Group{}
+			for k, g := range rep.Groups {
+				got[k] = *g
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Compare() groups = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupKey(t *testing.T) {
+	tx := sendTx("A", 1, "")
+	tx.Events = append(tx.Events,
+		structs.TransactionEvent{Kind: "error"},
+		structs.TransactionEvent{Kind: api.ValuationEventKind},
+		structs.TransactionEvent{Kind: "deposit", Sub: []structs.SubsetEvent{{Module: "hard"}}},
+		structs.TransactionEvent{Kind: "send", Sub: []structs.SubsetEvent{{Module: "bank"}}},
+	)
+	if got := groupKey(tx); got != "bank/send,hard/deposit" {
+		t.Errorf("groupKey() = %q", got)
+	}
+	if got := groupKey(structs.Transaction{}); got != "unknown" {
+		t.Errorf("groupKey() of transaction without events = %q", got)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/figment-networks/kava-worker/api"
+	"github.com/figment-networks/kava-worker/cmd/common/logger"
+	"github.com/figment-networks/kava-worker/sink"
+)
+
+type flags struct {
+	fixturesDir   string
+	startHeight   uint64
+	endHeight     uint64
+	codec         string
+	baselineJSONL string
+	export        string
+	maxDiffs      int
+}
+
+var configFlags = flags{}
+
+func init() {
+	flag.StringVar(&configFlags.fixturesDir, "fixtures", "api/testdata/fixtures", "Directory of recorded fixtures")
+	flag.Uint64Var(&configFlags.startHeight, "start", 0, "First height of the range")
+	flag.Uint64Var(&configFlags.endHeight, "end", 0, "Last height of the range")
+	flag.StringVar(&configFlags.codec, "codec", api.DefaultCodec, "Codec version of the current output")
+	flag.StringVar(&configFlags.baselineJSONL, "baseline", "", "Path or glob of JSONL export of the baseline output")
+	flag.StringVar(&configFlags.export, "export", "", "Write current output as JSONL export into the file (to be used as -baseline later)")
+	flag.IntVar(&configFlags.maxDiffs, "max-diffs", 20, "Maximum number of listed transactions per route/type")
+}
+
+// kava-diff converts recorded heights of the range with the current code and compares the output
+// with a baseline, JSONL export of previous version.
+// Report of per transaction differences grouped by route/type is written to stdout, the exit code is 2 when anything differs.
+func main() {
+	flag.Parse()
+	if configFlags.startHeight == 0 || configFlags.endHeight < configFlags.startHeight ||
+		(configFlags.baselineJSONL == "" && configFlags.export == "") {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	if err := logger.Init("console", "info", []string{"stderr"}, nil); err != nil {
+		log.Fatalf("error initializing logger [ERR: %v]", err.Error())
+	}
+	defer logger.Sync()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	osSig := make(chan os.Signal, 1)
+	signal.Notify(osSig, syscall.SIGTERM, syscall.SIGINT)
+	go func() {
+		<-osSig
+		cancel()
+	}()
+
+	current, err := NewFixtureSource(configFlags.fixturesDir, configFlags.codec, logger.GetLogger())
+	if err != nil {
+		log.Fatal(err)
+	}
+	curTxs, skipped, err := current.Transactions(ctx, configFlags.startHeight, configFlags.endHeight)
+	if err != nil {
+		logger.Error(fmt.Errorf("error converting current output: %w", err))
+		os.Exit(1)
+	}
+
+	if configFlags.export != "" {
+		if err := export(ctx, configFlags.export, curTxs); err != nil {
+			logger.Error(fmt.Errorf("error exporting current output: %w", err))
+			os.Exit(1)
+		}
+		if configFlags.baselineJSONL == "" {
+			return
+		}
+	}
+
+	oldTxs, _, err := NewJSONLSource(configFlags.baselineJSONL).Transactions(ctx, configFlags.startHeight, configFlags.endHeight)
+	if err != nil {
+		logger.Error(fmt.Errorf("error reading baseline output: %w", err))
+		os.Exit(1)
+	}
+
+	rep, err := Compare(oldTxs, curTxs, configFlags.maxDiffs)
+	if err != nil {
+		logger.Error(err)
+		os.Exit(1)
+	}
+	rep.StartHeight, rep.EndHeight = configFlags.startHeight, configFlags.endHeight
+	rep.Baseline, rep.Current = configFlags.baselineJSONL, "codec "+configFlags.codec
+	rep.SkippedHeights = skipped
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(rep); err != nil {
+		logger.Error(fmt.Errorf("error encoding report: %w", err))
+		os.Exit(1)
+	}
+	if rep.Different > 0 {
+		os.Exit(2)
+	}
+}
+
+// export writes transactions as records of the JSONL sink, ordered by height
+func export(ctx context.Context, path string, txs map[string]structs.Transaction) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	records := make([]sink.Record, 0, len(txs))
+	for _, t := range txs {
+		records = append(records, sink.Record{
+			Kind:        sink.KindTransaction,
+			Height:      t.Height,
+			Transaction: &structs.TransactionWithMeta{Network: "kava", ChainID: t.ChainID, Version: "0.0.1", Transaction: t},
+		})
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Height != records[j].Height {
+			return records[i].Height < records[j].Height
+		}
+		return records[i].Transaction.Transaction.Hash < records[j].Transaction.Transaction.Hash
+	})
+
+	err = sink.NewStream(f).Write(ctx, records)
+	if cErr := f.Close(); err == nil {
+		err = cErr
+	}
+	return err
+}
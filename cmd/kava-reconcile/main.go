@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/figment-networks/kava-worker/api"
+	"github.com/figment-networks/kava-worker/cmd/common/logger"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+type flags struct {
+	rpcAddr         string
+	lcdAddr         string
+	datahubKey      string
+	addresses       string
+	startHeight     uint64
+	endHeight       uint64
+	checkpointEvery uint64
+	workers         int
+	requestsPerSec  int
+	genesisFile     string
+}
+
+var configFlags = flags{}
+
+func init() {
+	flag.StringVar(&configFlags.rpcAddr, "rpc", "http://127.0.0.1:26657", "Tendermint RPC address")
+	flag.StringVar(&configFlags.lcdAddr, "lcd", "http://127.0.0.1:1317", "LCD address")
+	flag.StringVar(&configFlags.datahubKey, "key", "", "Datahub key")
+	flag.StringVar(&configFlags.addresses, "addresses", "", "Comma separated list of addresses to reconcile")
+	flag.Uint64Var(&configFlags.startHeight, "start", 0, "First height of the range")
+	flag.Uint64Var(&configFlags.endHeight, "end", 0, "Last height of the range")
+	flag.Uint64Var(&configFlags.checkpointEvery, "checkpoint", 100, "Number of heights between balance checks")
+	flag.IntVar(&configFlags.workers, "workers", 4, "Number of heights fetched concurrently")
+	flag.IntVar(&configFlags.requestsPerSec, "rps", 20, "Limit of requests per second")
+	flag.StringVar(&configFlags.genesisFile, "genesis", "", "Genesis file with initial balances, used instead of node's genesis when starting from height 1")
+}
+
+// kava-reconcile replays transfers, fees, staking and begin/end block events of the range for given addresses
+// and compares computed balances against the LCD balances on checkpoints.
+// Report is written to stdout, the exit code is 2 when any discrepancy is found.
+func main() {
+	flag.Parse()
+	if configFlags.addresses == "" || configFlags.startHeight == 0 || configFlags.endHeight == 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	if err := logger.Init("console", "info", []string{"stderr"}, nil); err != nil {
+		log.Fatalf("error initializing logger [ERR: %v]", err.Error())
+	}
+	defer logger.Sync()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	osSig := make(chan os.Signal, 1)
+	signal.Notify(osSig, syscall.SIGTERM, syscall.SIGINT)
+	go func() {
+		<-osSig
+		cancel()
+	}()
+
+	api.InitMetrics()
+	rpcClient := api.NewClient(configFlags.rpcAddr, configFlags.datahubKey, logger.GetLogger(), nil, configFlags.requestsPerSec)
+	lcdClient := api.NewClient(configFlags.lcdAddr, configFlags.datahubKey, logger.GetLogger(), nil, configFlags.requestsPerSec)
+
+	var addresses []string
+	for _, a := range strings.Split(configFlags.addresses, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			addresses = append(addresses, a)
+		}
+	}
+
+	var rpc RPC = rpcClient
+	if configFlags.genesisFile != "" {
+		rpc = fileGenesis{Client: rpcClient, path: configFlags.genesisFile}
+	}
+
+	r := NewReconciler(rpc, lcdClient, logger.GetLogger(), addresses, configFlags.checkpointEvery, configFlags.workers)
+	rep, err := r.Run(ctx, configFlags.startHeight, configFlags.endHeight)
+	if err != nil {
+		logger.Error(fmt.Errorf("error reconciling: %w", err))
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(rep); err != nil {
+		logger.Error(fmt.Errorf("error encoding report: %w", err))
+	}
+
+	if err != nil {
+		os.Exit(1)
+	}
+	if len(rep.Discrepancies) > 0 {
+		os.Exit(2)
+	}
+}
+
+// fileGenesis reads genesis balances from the file, for genesis too large to be served by node
+type fileGenesis struct {
+	*api.Client
+	path string
+}
+
+func (fg fileGenesis) GetGenesisBalances(ctx context.Context, addresses []string) (map[string]sdk.Coins, error) {
+	f, err := os.Open(fg.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return api.ReadGenesisBalances(f, addresses)
+}
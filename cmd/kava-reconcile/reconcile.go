@@ -0,0 +1,363 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/figment-networks/kava-worker/api"
+	"github.com/figment-networks/kava-worker/api/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"go.uber.org/zap"
+)
+
+const page = 100
+
+// RPC is a source of blocks, transactions and block events
+type RPC interface {
+	GetBlock(ctx context.Context, params structs.HeightHash) (block structs.Block, err error)
+	SearchTx(ctx context.Context, r structs.HeightHash, block structs.Block, perPage uint64) (txs []structs.Transaction, err error)
+	GetBlockResults(ctx context.Context, height uint64) (results types.ResultBlockResults, err error)
+	GetGenesisBalances(ctx context.Context, addresses []string) (map[string]sdk.Coins, error)
+	Ledger(tx structs.Transaction) ([]api.LedgerEntry, error)
+}
+
+// LCD is a source of real balances
+type LCD interface {
+	GetBalances(ctx context.Context, params structs.HeightAccount) (coins sdk.Coins, err error)
+}
+
+// Discrepancy is a difference between balance computed from ledger and the one returned by node
+type Discrepancy struct {
+	Address string `json:"address"`
+	Denom   string `json:"denom"`
+	// Height is the first height where balances differ
+	Height   uint64 `json:"height"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+	// Entries are ledger entries of the address at offending height
+	Entries []api.LedgerEntry `json:"entries,omitempty"`
+}
+
+// Report is a summary of reconciliation
+type Report struct {
+	StartHeight   uint64        `json:"start_height"`
+	EndHeight     uint64        `json:"end_height"`
+	Addresses     []string      `json:"addresses"`
+	Checkpoints   []uint64      `json:"checkpoints"`
+	Discrepancies []Discrepancy `json:"discrepancies"`
+}
+
+// balances are amounts per denom
+type balances map[string]*big.Int
+
+func (b balances) add(denom string, amount *big.Int) {
+	a, ok := b[denom]
+	if !ok {
+		a = new(big.Int)
+		b[denom] = a
+	}
+	a.Add(a, amount)
+}
+
+func (b balances) copy() balances {
+	c := make(balances, len(b))
+	for d, a := range b {
+		c[d] = new(big.Int).Set(a)
+	}
+	return c
+}
+
+func (b balances) amountOf(denom string) *big.Int {
+	if a, ok := b[denom]; ok {
+		return a
+	}
+	return new(big.Int)
+}
+
+// diff returns denoms with different amounts
+func (b balances) diff(o balances) (denoms []string) {
+	seen := map[string]bool{}
+	for d := range b {
+		seen[d] = true
+	}
+	for d := range o {
+		seen[d] = true
+	}
+	for d := range seen {
+		if b.amountOf(d).Cmp(o.amountOf(d)) != 0 {
+			denoms = append(denoms, d)
+		}
+	}
+	return denoms
+}
+
+func fromCoins(coins sdk.Coins) balances {
+	b := make(balances, len(coins))
+	for _, c := range coins {
+		b.add(c.Denom, c.Amount.BigInt())
+	}
+	return b
+}
+
+// heightLedger is a set of ledger entries of tracked addresses at height
+type heightLedger struct {
+	height  uint64
+	entries map[string][]api.LedgerEntry
+	err     error
+}
+
+// Reconciler replays ledger of the range and compares it with balances returned by node
+type Reconciler struct {
+	rpc    RPC
+	lcd    LCD
+	logger *zap.Logger
+
+	addresses       []string
+	tracked         map[string]bool
+	checkpointEvery uint64
+	workers         int
+}
+
+// NewReconciler is Reconciler constructor
+func NewReconciler(rpc RPC, lcd LCD, logger *zap.Logger, addresses []string, checkpointEvery uint64, workers int) *Reconciler {
+	tracked := make(map[string]bool, len(addresses))
+	for _, a := range addresses {
+		tracked[a] = true
+	}
+	if checkpointEvery == 0 {
+		checkpointEvery = 100
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+	return &Reconciler{
+		rpc:             rpc,
+		lcd:             lcd,
+		logger:          logger,
+		addresses:       addresses,
+		tracked:         tracked,
+		checkpointEvery: checkpointEvery,
+		workers:         workers,
+	}
+}
+
+// Run reconciles balances of addresses between start and end height (inclusive)
+func (r *Reconciler) Run(ctx context.Context, start, end uint64) (rep Report, err error) {
+	rep = Report{StartHeight: start, EndHeight: end, Addresses: r.addresses}
+	if start == 0 || end < start {
+		return rep, fmt.Errorf("wrong range %d-%d", start, end)
+	}
+
+	// balances at the last height where ledger matched the node, genesis balances when starting from the first height
+	baseline := make(map[string]balances, len(r.addresses))
+	if start == 1 {
+		genesis, err := r.rpc.GetGenesisBalances(ctx, r.addresses)
+		if err != nil {
+			return rep, fmt.Errorf("error getting genesis balances: %w", err)
+		}
+		for _, addr := range r.addresses {
+			baseline[addr] = fromCoins(genesis[addr])
+		}
+	} else {
+		for _, addr := range r.addresses {
+			if baseline[addr], err = r.actual(ctx, addr, start-1); err != nil {
+				return rep, err
+			}
+		}
+	}
+
+	lastOK := start - 1
+	for lastOK < end {
+		cp := lastOK + r.checkpointEvery
+		if cp > end {
+			cp = end
+		}
+
+		window, err := r.fetchWindow(ctx, lastOK+1, cp)
+		if err != nil {
+			return rep, err
+		}
+
+		r.logger.Info("[RECONCILE] Checkpoint", zap.Uint64("height", cp))
+		rep.Checkpoints = append(rep.Checkpoints, cp)
+
+		for _, addr := range r.addresses {
+			expected := expectedAt(baseline[addr], window, addr, cp)
+			actual, err := r.actual(ctx, addr, cp)
+			if err != nil {
+				return rep, err
+			}
+
+			if len(expected.diff(actual)) > 0 {
+				d, err := r.bisect(ctx, addr, baseline[addr], window, lastOK, cp)
+				if err != nil {
+					return rep, err
+				}
+				rep.Discrepancies = append(rep.Discrepancies, d...)
+			}
+			// (re)synchronize with the node, so following discrepancies are detected separately
+			baseline[addr] = actual
+		}
+		lastOK = cp
+	}
+
+	return rep, nil
+}
+
+// bisect finds the first height in (lo, hi] where ledger differs from the node
+func (r *Reconciler) bisect(ctx context.Context, addr string, base balances, window []heightLedger, lo, hi uint64) (ds []Discrepancy, err error) {
+	var actualHi balances
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		actual, err := r.actual(ctx, addr, mid)
+		if err != nil {
+			return nil, err
+		}
+		if len(expectedAt(base, window, addr, mid).diff(actual)) > 0 {
+			hi, actualHi = mid, actual
+		} else {
+			lo = mid
+		}
+	}
+
+	if actualHi == nil {
+		if actualHi, err = r.actual(ctx, addr, hi); err != nil {
+			return nil, err
+		}
+	}
+
+	expected := expectedAt(base, window, addr, hi)
+	for _, denom := range expected.diff(actualHi) {
+		d := Discrepancy{
+			Address:  addr,
+			Denom:    denom,
+			Height:   hi,
+			Expected: expected.amountOf(denom).String(),
+			Actual:   actualHi.amountOf(denom).String(),
+		}
+		for _, hl := range window {
+			if hl.height == hi {
+				d.Entries = hl.entries[addr]
+			}
+		}
+		r.logger.Warn("[RECONCILE] Discrepancy found", zap.String("address", addr), zap.String("denom", denom), zap.Uint64("height", hi), zap.String("expected", d.Expected), zap.String("actual", d.Actual))
+		ds = append(ds, d)
+	}
+	return ds, nil
+}
+
+// expectedAt computes balances of address at height, applying ledger entries to the baseline
+func expectedAt(base balances, window []heightLedger, addr string, height uint64) balances {
+	b := base.copy()
+	for _, hl := range window {
+		if hl.height > height {
+			break
+		}
+		for _, e := range hl.entries[addr] {
+			if e.Direction == api.LedgerCredit {
+				b.add(e.Denom, e.Amount)
+			} else {
+				b.add(e.Denom, new(big.Int).Neg(e.Amount))
+			}
+		}
+	}
+	return b
+}
+
+func (r *Reconciler) actual(ctx context.Context, addr string, height uint64) (balances, error) {
+	coins, err := r.lcd.GetBalances(ctx, structs.HeightAccount{Account: addr, Height: height})
+	if err != nil {
+		return nil, fmt.Errorf("error getting balances of %s at %d: %w", addr, height, err)
+	}
+	return fromCoins(coins), nil
+}
+
+// fetchWindow gets ledger of every height in range, ordered by height
+func (r *Reconciler) fetchWindow(ctx context.Context, start, end uint64) ([]heightLedger, error) {
+	window := make([]heightLedger, end-start+1)
+	heights := make(chan uint64)
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < r.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for h := range heights {
+				window[h-start] = r.fetchHeight(ctx, h)
+			}
+		}()
+	}
+
+	for h := start; h <= end; h++ {
+		heights <- h
+	}
+	close(heights)
+	wg.Wait()
+
+	for _, hl := range window {
+		if hl.err != nil {
+			return nil, hl.err
+		}
+	}
+	return window, nil
+}
+
+func (r *Reconciler) fetchHeight(ctx context.Context, height uint64) (hl heightLedger) {
+	hl = heightLedger{height: height, entries: make(map[string][]api.LedgerEntry)}
+
+	results, err := r.rpc.GetBlockResults(ctx, height)
+	if err != nil {
+		hl.err = fmt.Errorf("error getting block results %d: %w", height, err)
+		return hl
+	}
+
+	begin, err := api.ProduceBlockLedger("begin_block", results.BeginBlockEvents)
+	if err != nil {
+		hl.err = fmt.Errorf("error producing begin block ledger %d: %w", height, err)
+		return hl
+	}
+	r.track(hl.entries, begin)
+
+	block, err := r.rpc.GetBlock(ctx, structs.HeightHash{Height: height})
+	if err != nil {
+		hl.err = fmt.Errorf("error getting block %d: %w", height, err)
+		return hl
+	}
+
+	if block.NumberOfTransactions > 0 {
+		txs, err := r.rpc.SearchTx(ctx, structs.HeightHash{Height: height}, block, page)
+		if err != nil {
+			hl.err = fmt.Errorf("error getting transactions %d: %w", height, err)
+			return hl
+		}
+		for _, tx := range txs {
+			ledger, err := r.rpc.Ledger(tx)
+			if err != nil {
+				hl.err = fmt.Errorf("error producing ledger of transaction %s: %w", tx.Hash, err)
+				return hl
+			}
+			r.track(hl.entries, ledger)
+		}
+	}
+
+	end, err := api.ProduceBlockLedger("end_block", results.EndBlockEvents)
+	if err != nil {
+		hl.err = fmt.Errorf("error producing end block ledger %d: %w", height, err)
+		return hl
+	}
+	r.track(hl.entries, end)
+
+	return hl
+}
+
+func (r *Reconciler) track(out map[string][]api.LedgerEntry, entries []api.LedgerEntry) {
+	for _, e := range entries {
+		if r.tracked[e.Account] {
+			out[e.Account] = append(out[e.Account], e)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/figment-networks/kava-worker/api"
+	"github.com/figment-networks/kava-worker/api/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"go.uber.org/zap"
+)
+
+const testAddr = "kava1delegator"
+
+// fakeChain serves one transaction of recorded ledger per height and balances of the chain,
+// where deltas are the real balance changes of testAddr in ukava (including the ones missing in ledger)
+type fakeChain struct {
+	genesis int64
+	ledger  map[uint64]int64
+	deltas  map[uint64]int64
+}
+
+func (fc fakeChain) GetBlock(ctx context.Context, params structs.HeightHash) (structs.Block, error) {
+	return structs.Block{Height: params.Height, NumberOfTransactions: 1}, nil
+}
+
+func (fc fakeChain) SearchTx(ctx context.Context, r structs.HeightHash, block structs.Block, perPage uint64) ([]structs.Transaction, error) {
+	return []structs.Transaction{{Height: r.Height, Hash: strconv.FormatUint(r.Height, 10)}}, nil
+}
+
+func (fc fakeChain) GetBlockResults(ctx context.Context, height uint64) (types.ResultBlockResults, error) {
+	return types.ResultBlockResults{}, nil
+}
+
+func (fc fakeChain) GetGenesisBalances(ctx context.Context, addresses []string) (map[string]sdk.Coins, error) {
+	return map[string]sdk.Coins{testAddr: sdk.NewCoins(sdk.NewInt64Coin("ukava", fc.genesis))}, nil
+}
+
+func (fc fakeChain) Ledger(tx structs.Transaction) ([]api.LedgerEntry, error) {
+	delta, ok := fc.ledger[tx.Height]
+	if !ok {
+		return nil, nil
+	}
+	return []api.LedgerEntry{entry(delta), {Direction: api.LedgerCredit, Account: "kava1other", Denom: "ukava", Amount: big.NewInt(1)}}, nil
+}
+
+func (fc fakeChain) GetBalances(ctx context.Context, params structs.HeightAccount) (sdk.Coins, error) {
+	balance := fc.genesis
+	for h, d := range fc.deltas {
+		if h <= params.Height {
+			balance += d
+		}
+	}
+	return sdk.NewCoins(sdk.NewInt64Coin("ukava", balance)), nil
+}
+
+func entry(delta int64) api.LedgerEntry {
+	if delta < 0 {
+		return api.LedgerEntry{Direction: api.LedgerDebit, Account: testAddr, Denom: "ukava", Amount: big.NewInt(-delta)}
+	}
+	return api.LedgerEntry{Direction: api.LedgerCredit, Account: testAddr, Denom: "ukava", Amount: big.NewInt(delta)}
+}
+
+func TestReconcilerRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		start       uint64
+		ledger      map[uint64]int64
+		deltas      map[uint64]int64
+		checkpoints []uint64
+		want        []Discrepancy
+	}{
+		{
+			name:        "matching ledger",
+			start:       1,
+			ledger:      map[uint64]int64{2: 100, 5: -30, 10: 7},
+			deltas:      map[uint64]int64{2: 100, 5: -30, 10: 7},
+			checkpoints: []uint64{4, 8, 10},
+		},
+		{
+			name:        "missing balance change",
+			start:       1,
+			ledger:      map[uint64]int64{2: 100},
+			deltas:      map[uint64]int64{2: 100, 6: -50},
+			checkpoints: []uint64{4, 8, 10},
+			want:        []Discrepancy{{Address: testAddr, Denom: "ukava", Height: 6, Expected: "1100", Actual: "1050"}},
+		},
+		{
+			name:        "wrong amount is reported with entries",
+			start:       1,
+			ledger:      map[uint64]int64{3: -20},
+			deltas:      map[uint64]int64{3: -25},
+			checkpoints: []uint64{4, 8, 10},
+			want:        []Discrepancy{{Address: testAddr, Denom: "ukava", Height: 3, Expected: "980", Actual: "975", Entries: []api.LedgerEntry{entry(-20)}}},
+		},
+		{
+			name:        "discrepancies of separate windows",
+			start:       1,
+			deltas:      map[uint64]int64{1: 5, 9: 5},
+			checkpoints: []uint64{4, 8, 10},
+			want: []Discrepancy{
+				{Address: testAddr, Denom: "ukava", Height: 1, Expected: "1000", Actual: "1005"},
+				{Address: testAddr, Denom: "ukava", Height: 9, Expected: "1005", Actual: "1010"},
+			},
+		},
+		{
+			name:        "discrepancy at checkpoint",
+			start:       1,
+			deltas:      map[uint64]int64{8: 1},
+			checkpoints: []uint64{4, 8, 10},
+			want:        []Discrepancy{{Address: testAddr, Denom: "ukava", Height: 8, Expected: "1000", Actual: "1001"}},
+		},
+		{
+			name:        "baseline from node",
+			start:       5,
+			ledger:      map[uint64]int64{7: 10},
+			deltas:      map[uint64]int64{2: 300, 7: 10},
+			checkpoints: []uint64{8, 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := fakeChain{genesis: 1000, ledger: tt.ledger, deltas: tt.deltas}
+			r := NewReconciler(fc, fc, zap.NewNop(), []string{testAddr}, 4, 3)
+
+			rep, err := r.Run(context.Background(), tt.start, 10)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(rep.Checkpoints, tt.checkpoints) {
+				t.Errorf("checkpoints = %v, want %v", rep.Checkpoints, tt.checkpoints)
+			}
+			if !reflect.DeepEqual(rep.Discrepancies, tt.want) {
+				t.Errorf("discrepancies = %+v, want %+v", rep.Discrepancies, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcilerWrongRange(t *testing.T) {
+	fc := fakeChain{}
+	r := NewReconciler(fc, fc, zap.NewNop(), []string{testAddr}, 4, 1)
+	if _, err := r.Run(context.Background(), 10, 9); err == nil {
+		t.Error("expected error for end before start")
+	}
+	if _, err := r.Run(context.Background(), 0, 9); err == nil {
+		t.Error("expected error for start of 0")
+	}
+}
+
+func TestExpectedAt(t *testing.T) {
+	base := balances{"ukava": big.NewInt(100)}
+	window := []heightLedger{
+		{height: 3, entries: map[string][]api.LedgerEntry{testAddr: {entry(10)}, "kava1other": {entry(1000)}}},
+		{height: 4, entries: map[string][]api.LedgerEntry{testAddr: {entry(-30), {Direction: api.LedgerCredit, Account: testAddr, Denom: "hard", Amount: big.NewInt(5)}}}},
+		{height: 5, entries: map[string][]api.LedgerEntry{}},
+		{height: 6, entries: map[string][]api.LedgerEntry{testAddr: {entry(-80)}}},
+	}
+
+	tests := []struct {
+		name   string
+		height uint64
+		want   balances
+	}{
+		{name: "before window", height: 2, want: balances{"ukava": big.NewInt(100)}},
+		{name: "credit", height: 3, want: balances{"ukava": big.NewInt(110)}},
+		{name: "debit and new denom", height: 4, want: balances{"ukava": big.NewInt(80), "hard": big.NewInt(5)}},
+		{name: "height without entries", height: 5, want: balances{"ukava": big.NewInt(80), "hard": big.NewInt(5)}},
+		{name: "whole window", height: 6, want: balances{"ukava": big.NewInt(0), "hard": big.NewInt(5)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := expectedAt(base, window, testAddr, tt.height)
+			if d := got.diff(tt.want); len(d) > 0 {
+				t.Errorf("expectedAt(%d) = %v, want %v", tt.height, got, tt.want)
+			}
+		})
+	}
+
+	if base["ukava"].Int64() != 100 {
+		t.Errorf("expectedAt modified baseline: %v", base)
+	}
+}
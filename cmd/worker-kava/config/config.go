@@ -2,7 +2,10 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"reflect"
+	"strconv"
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
@@ -39,23 +42,142 @@ type Config struct {
 	StoreHTTPEndpoints  string        `json:"store_http_endpoints" envconfig:"STORE_HTTP_ENDPOINTS"`
 	HealthCheckInterval time.Duration `json:"health_check_interval" envconfig:"HEALTH_CHECK_INTERVAL" default:"10s"`
 
+	// Outputs of indexed data as comma separated list of `store`, `local`, `jsonl`, `stdout` and `webhook`
+	Sinks               string        `json:"sinks" envconfig:"SINKS" default:"store"`
+	SinkJSONLDir        string        `json:"sink_jsonl_dir" envconfig:"SINK_JSONL_DIR" default:"data"`
+	SinkJSONLRotate     uint64        `json:"sink_jsonl_rotate" envconfig:"SINK_JSONL_ROTATE" default:"10000"`
+	SinkWebhookURL      string        `json:"sink_webhook_url" envconfig:"SINK_WEBHOOK_URL"`
+	SinkWebhookBatch    int           `json:"sink_webhook_batch" envconfig:"SINK_WEBHOOK_BATCH" default:"100"`
+	SinkWebhookInterval time.Duration `json:"sink_webhook_interval" envconfig:"SINK_WEBHOOK_INTERVAL" default:"5s"`
+	LocalStorePath      string        `json:"local_store_path" envconfig:"LOCAL_STORE_PATH" default:"localstore"`
+
+	// ShutdownTimeout is the time given to in-flight tasks to finish on shutdown
+	ShutdownTimeout time.Duration `json:"shutdown_timeout" envconfig:"SHUTDOWN_TIMEOUT" default:"30s"`
+
+	// CacheDir enables persisting raw /block and /tx_search responses of past heights on disk
+	CacheDir string `json:"cache_dir" envconfig:"CACHE_DIR"`
+
+	// RecordFixturesDir enables recording responses of the node into fixture files (see api/recorder)
+	RecordFixturesDir string `json:"record_fixtures_dir" envconfig:"RECORD_FIXTURES_DIR"`
+
+	// DecodeEndpoint enables /decode endpoint converting raw transactions for other services (see api.Decoder)
+	DecodeEndpoint bool `json:"decode_endpoint" envconfig:"DECODE_ENDPOINT" default:"false"`
+
 	MaximumHeightsToGet float64 `json:"maximum_heights_to_get" envconfig:"MAXIMUM_HEIGHTS_TO_GET" default:"10000"`
 	RequestsPerSecond   int64   `json:"requests_per_second" envconfig:"REQUESTS_PER_SECOND" default:"33"`
 
+	// Concurrency limits, workers are per stream and per range, max concurrent values are global caps (0 - no limit).
+	// Heights of range are written into the store in batches of StoreBatchHeights or StoreBatchBytes (whichever is reached first).
+	StreamWorkers        int    `json:"stream_workers" envconfig:"STREAM_WORKERS" default:"20"`
+	RangeWorkers         int    `json:"range_workers" envconfig:"RANGE_WORKERS" default:"20"`
+	RangeChunkSize       uint64 `json:"range_chunk_size" envconfig:"RANGE_CHUNK_SIZE" default:"100"`
+	StoreBatchHeights    int    `json:"store_batch_heights" envconfig:"STORE_BATCH_HEIGHTS" default:"1"`
+	StoreBatchBytes      int    `json:"store_batch_bytes" envconfig:"STORE_BATCH_BYTES" default:"0"`
+	MaxConcurrentTasks   int    `json:"max_concurrent_tasks" envconfig:"MAX_CONCURRENT_TASKS" default:"40"`
+	MaxConcurrentHeights int    `json:"max_concurrent_heights" envconfig:"MAX_CONCURRENT_HEIGHTS" default:"40"`
+	MaxQueuedTasks       int    `json:"max_queued_tasks" envconfig:"MAX_QUEUED_TASKS"`
+
+	// MaxAccountTransactions caps transactions returned by a single GetAccountTransactions task, the rest is requested with next height
+	MaxAccountTransactions uint64 `json:"max_account_transactions" envconfig:"MAX_ACCOUNT_TRANSACTIONS" default:"1000"`
+
+	// Timeouts of tasks, TaskTimeouts overrides timeout per task type eg. `GetTransactions=10m,GetLatestMark=5s`
+	TaskTimeout  time.Duration `json:"task_timeout" envconfig:"TASK_TIMEOUT" default:"5m"`
+	TaskTimeouts string        `json:"task_timeouts" envconfig:"TASK_TIMEOUTS"`
+
+	// Follow mode indexes new blocks as soon as they are committed, using the websocket of FollowRPCAddr (TendermintRPCAddr when empty)
+	Follow            bool   `json:"follow" envconfig:"FOLLOW" default:"false"`
+	FollowRPCAddr     string `json:"follow_rpc_addr" envconfig:"FOLLOW_RPC_ADDR"`
+	FollowStartHeight uint64 `json:"follow_start_height" envconfig:"FOLLOW_START_HEIGHT"`
+
+	// Standalone mode indexes the chain without manager, persisting progress in StandaloneCheckpoint file
+	Standalone            bool          `json:"standalone" envconfig:"STANDALONE" default:"false"`
+	StandaloneCheckpoint  string        `json:"standalone_checkpoint" envconfig:"STANDALONE_CHECKPOINT" default:"checkpoint.json"`
+	StandaloneStartHeight uint64        `json:"standalone_start_height" envconfig:"STANDALONE_START_HEIGHT"`
+	StandaloneBatch       uint64        `json:"standalone_batch" envconfig:"STANDALONE_BATCH" default:"100"`
+	StandaloneInterval    time.Duration `json:"standalone_interval" envconfig:"STANDALONE_INTERVAL" default:"5s"`
+
+	// USD valuation (enabled when markets are set) eg. `ukava=kava:usd,hard=hard:usd,usdx=peg`
+	USDValuationMarkets string `json:"usd_valuation_markets" envconfig:"USD_VALUATION_MARKETS"`
+
+	// Denominations registry as `base=DISPLAY:exponent` list
+	Denoms string `json:"denoms" envconfig:"DENOMS" default:"ukava=KAVA:6,hard=HARD:6,swp=SWP:6,usdx=USDX:6,bnb=BNB:8,btcb=BTCB:8,xrpb=XRPB:8,busd=BUSD:8"`
+
 	// Rollbar
 	RollbarAccessToken string `json:"rollbar_access_token" envconfig:"ROLLBAR_ACCESS_TOKEN"`
 	RollbarServerRoot  string `json:"rollbar_server_root" envconfig:"ROLLBAR_SERVER_ROOT" default:"github.com/figment-networks/kava-worker"`
 }
 
-// FromFile reads the config from a file
+// FromFile reads the config from a file, fields missing in the file get their default values
 func FromFile(path string, config *Config) error {
 	data, err := ioutil.ReadFile(path)
 	if err != nil {
 		return err
 	}
+	if err := setDefaults(config); err != nil {
+		return err
+	}
 	return json.Unmarshal(data, config)
 }
 
+// setDefaults sets fields to values of their `default` tags, the same ones envconfig uses
+func setDefaults(config *Config) error {
+	v := reflect.ValueOf(config).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		def, ok := t.Field(i).Tag.Lookup("default")
+		if !ok {
+			continue
+		}
+		if err := setValue(v.Field(i), def); err != nil {
+			return fmt.Errorf("wrong default of %s: %w", t.Field(i).Name, err)
+		}
+	}
+	return nil
+}
+
+func setValue(f reflect.Value, value string) error {
+	if f.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		f.SetInt(int64(d))
+		return nil
+	}
+
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", f.Type())
+	}
+	return nil
+}
+
 // FromEnv reads the config from environment variables
 func FromEnv(config *Config) error {
 	return envconfig.Process("", config)
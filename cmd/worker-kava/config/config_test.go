@@ -0,0 +1,37 @@
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFromFileDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"tendermint_rpc_addr":"http://127.0.0.1:26657","max_concurrent_heights":0,"sinks":"jsonl"}`
+	if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("error writing config: %v", err)
+	}
+
+	cfg := &Config{}
+	if err := FromFile(path, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.TendermintRPCAddr != "http://127.0.0.1:26657" || cfg.Sinks != "jsonl" {
+		t.Errorf("expected values from file, got %q and %q", cfg.TendermintRPCAddr, cfg.Sinks)
+	}
+	if cfg.MaxConcurrentHeights != 0 {
+		t.Errorf("expected explicit zero to be kept, got %d", cfg.MaxConcurrentHeights)
+	}
+	if cfg.ShutdownTimeout != 30*time.Second || cfg.SinkWebhookInterval != 5*time.Second {
+		t.Errorf("expected default durations, got %s and %s", cfg.ShutdownTimeout, cfg.SinkWebhookInterval)
+	}
+	if cfg.MaxConcurrentTasks != 40 || cfg.MaximumHeightsToGet != 10000 || cfg.SinkJSONLRotate != 10000 {
+		t.Errorf("expected default limits, got %d, %f and %d", cfg.MaxConcurrentTasks, cfg.MaximumHeightsToGet, cfg.SinkJSONLRotate)
+	}
+	if cfg.Managers != "127.0.0.1:8085" || cfg.Follow {
+		t.Errorf("expected default managers and follow, got %q and %t", cfg.Managers, cfg.Follow)
+	}
+}
@@ -17,12 +17,12 @@ import (
 	grpcIndexer "github.com/figment-networks/indexer-manager/worker/transport/grpc"
 	grpcProtoIndexer "github.com/figment-networks/indexer-manager/worker/transport/grpc/indexer"
 
-	httpStore "github.com/figment-networks/indexing-engine/worker/store/transport/http"
-
 	"github.com/figment-networks/indexing-engine/health"
 	"github.com/figment-networks/indexing-engine/metrics"
 	"github.com/figment-networks/indexing-engine/metrics/prometheusmetrics"
 	"github.com/figment-networks/kava-worker/api"
+	"github.com/figment-networks/kava-worker/api/recorder"
+	"github.com/figment-networks/kava-worker/api/util"
 	"github.com/figment-networks/kava-worker/client"
 	"github.com/figment-networks/kava-worker/cmd/common/logger"
 	"github.com/figment-networks/kava-worker/cmd/worker-kava/config"
@@ -32,6 +32,11 @@ import (
 	grpc "google.golang.org/grpc"
 )
 
+const (
+	grpcStopTimeout = 10 * time.Second
+	httpStopTimeout = 5 * time.Second
+)
+
 type flags struct {
 	configPath  string
 	showVersion bool
@@ -42,10 +47,10 @@ var configFlags = flags{}
 func init() {
 	flag.BoolVar(&configFlags.showVersion, "v", false, "Show application version")
 	flag.StringVar(&configFlags.configPath, "config", "", "Path to config")
-	flag.Parse()
 }
 
 func main() {
+	flag.Parse()
 	ctx, cancel := context.WithCancel(context.Background())
 	// Initialize configuration
 	cfg, err := initConfig(configFlags.configPath)
@@ -73,6 +78,15 @@ func main() {
 	logger.Info(config.IdentityString())
 	defer logger.Sync()
 
+	if cfg.Denoms != "" {
+		denoms, err := util.ParseDenoms(cfg.Denoms)
+		if err != nil {
+			logger.Error(fmt.Errorf("error parsing denoms: %w", err))
+			return
+		}
+		util.SetDenoms(denoms)
+	}
+
 	// Initialize metrics
 	prom := prometheusmetrics.New()
 	err = metrics.AddEngine(prom)
@@ -103,19 +117,89 @@ func main() {
 		c.AddManager(m + "/client_ping")
 	}
 
-	logger.Info(fmt.Sprintf("Connecting to managers (%s)", strings.Join(managers, ",")))
-
-	go c.Run(ctx, logger.GetLogger(), cfg.ManagerInterval)
+	if !cfg.Standalone {
+		logger.Info(fmt.Sprintf("Connecting to managers (%s)", strings.Join(managers, ",")))
+		go c.Run(ctx, logger.GetLogger(), cfg.ManagerInterval)
+	}
 
 	grpcServer := grpc.NewServer()
 
-	rpcClient := api.NewClient(cfg.TendermintRPCAddr, cfg.DatahubKey, logger.GetLogger(), nil, int(cfg.RequestsPerSecond))
-	lcdClient := api.NewClient(cfg.TendermintLCDAddr, cfg.DatahubKey, logger.GetLogger(), nil, int(cfg.RequestsPerSecond))
+	var nodeHTTPClient *http.Client
+	if cfg.RecordFixturesDir != "" {
+		rec, err := recorder.New(cfg.RecordFixturesDir, recorder.ModeRecord, nil)
+		if err != nil {
+			logger.Error(fmt.Errorf("error creating fixtures recorder: %w", err))
+			return
+		}
+		nodeHTTPClient = &http.Client{Timeout: 10 * time.Second, Transport: rec}
+	}
 
-	storeEndpoints := strings.Split(cfg.StoreHTTPEndpoints, ",")
-	hStore := httpStore.NewHTTPStore(storeEndpoints, &http.Client{})
+	rpcClient := api.NewClient(cfg.TendermintRPCAddr, cfg.DatahubKey, logger.GetLogger(), nodeHTTPClient, int(cfg.RequestsPerSecond))
+	lcdClient := api.NewClient(cfg.TendermintLCDAddr, cfg.DatahubKey, logger.GetLogger(), nodeHTTPClient, int(cfg.RequestsPerSecond))
 
-	workerClient := client.NewIndexerClient(ctx, logger.GetLogger(), rpcClient, lcdClient, hStore, uint64(cfg.MaximumHeightsToGet))
+	if cfg.CacheDir != "" {
+		if rpcClient.RawCache, err = api.NewDiskCache(cfg.CacheDir, cfg.ChainID); err != nil {
+			logger.Error(fmt.Errorf("error creating cache: %w", err))
+			return
+		}
+	}
+
+	if cfg.USDValuationMarkets != "" {
+		markets, err := parseValuationMarkets(cfg.USDValuationMarkets)
+		if err != nil {
+			logger.Error(fmt.Errorf("error parsing usd valuation config: %w", err))
+			return
+		}
+		rpcClient.Valuator = api.NewUSDValuator(lcdClient, markets, logger.GetLogger())
+	}
+
+	var decoder *api.Decoder
+	if cfg.DecodeEndpoint {
+		if decoder, err = api.NewDecoder(api.DefaultCodec, logger.GetLogger()); err != nil {
+			logger.Error(fmt.Errorf("error creating decoder: %w", err))
+			return
+		}
+	}
+
+	taskTimeouts, err := parseTaskTimeouts(cfg.TaskTimeouts)
+	if err != nil {
+		logger.Error(fmt.Errorf("error parsing task timeouts: %w", err))
+		return
+	}
+
+	outputs, err := buildSinks(ctx, cfg, logger.GetLogger())
+	if err != nil {
+		logger.Error(fmt.Errorf("error creating sinks: %w", err))
+		return
+	}
+
+	workerClient := client.NewIndexerClient(ctx, logger.GetLogger(), rpcClient, lcdClient, outputs.caller, uint64(cfg.MaximumHeightsToGet), client.Limits{
+		StreamWorkers:        cfg.StreamWorkers,
+		RangeWorkers:         cfg.RangeWorkers,
+		RangeChunkSize:       cfg.RangeChunkSize,
+		StoreBatchHeights:    cfg.StoreBatchHeights,
+		StoreBatchBytes:      cfg.StoreBatchBytes,
+		MaxConcurrentTasks:   cfg.MaxConcurrentTasks,
+		MaxConcurrentHeights: cfg.MaxConcurrentHeights,
+		MaxQueuedTasks:       cfg.MaxQueuedTasks,
+
+		MaxAccountTransactions: cfg.MaxAccountTransactions,
+		TaskTimeout:            cfg.TaskTimeout,
+		TaskTimeouts:           taskTimeouts,
+	})
+
+	if cfg.Follow {
+		followAddr := cfg.FollowRPCAddr
+		if followAddr == "" {
+			followAddr = cfg.TendermintRPCAddr
+		}
+		go workerClient.Follow(ctx, followAddr, cfg.FollowStartHeight)
+	}
+
+	if cfg.Standalone {
+		sched := newScheduler(workerClient, rpcClient, logger.GetLogger(), cfg.StandaloneCheckpoint, cfg.StandaloneStartHeight, cfg.StandaloneBatch, cfg.StandaloneInterval)
+		go sched.Run(ctx)
+	}
 
 	worker := grpcIndexer.NewIndexerServer(ctx, workerClient, logger.GetLogger())
 	grpcProtoIndexer.RegisterIndexerServiceServer(grpcServer, worker)
@@ -129,6 +213,14 @@ func main() {
 	monitor.AttachHttp(mux)
 
 	mux.Handle("/metrics", metrics.Handler())
+	mux.Handle("/tasks", workerClient.Tasks)
+	mux.Handle("/denoms", util.Registry())
+	if outputs.local != nil {
+		outputs.local.AttachHTTP(mux)
+	}
+	if decoder != nil {
+		decoder.AttachHTTP(mux)
+	}
 
 	s := &http.Server{
 		Addr:         "0.0.0.0:" + cfg.HTTPPort,
@@ -137,12 +229,14 @@ func main() {
 		WriteTimeout: 10 * time.Second,
 	}
 
-	osSig := make(chan os.Signal)
+	osSig := make(chan os.Signal, 1)
 	exit := make(chan string, 2)
 	signal.Notify(osSig, syscall.SIGTERM)
 	signal.Notify(osSig, syscall.SIGINT)
 
-	go runGRPC(grpcServer, cfg.Port, logger.GetLogger(), exit)
+	if !cfg.Standalone {
+		go runGRPC(grpcServer, cfg.Port, logger.GetLogger(), exit)
+	}
 	go runHTTP(s, cfg.HTTPPort, logger.GetLogger(), exit)
 
 RunLoop:
@@ -150,17 +244,37 @@ RunLoop:
 		select {
 		case sig := <-osSig:
 			logger.Info("Stopping worker... ", zap.String("signal", sig.String()))
+			// tasks sent by managers from now on are rejected
+			workerClient.StopAccepting()
+			// managers have no deregistration call, this only stops the pings, so managers drop the worker once pings are missed
+			for _, m := range managers {
+				c.RemoveManager(m + "/client_ping")
+			}
+			logger.Info("Stopped accepting tasks and pinging managers, draining in-flight tasks")
+			dCtx, dCancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+			if err := workerClient.Drain(dCtx); err != nil {
+				logger.GetLogger().Error("Error draining tasks ", zap.Error(err))
+			}
+			dCancel()
+			if err := outputs.Close(); err != nil {
+				logger.GetLogger().Error("Error closing sinks ", zap.Error(err))
+			}
 			cancel()
 			logger.Info("Canceled context, gracefully stopping grpc")
-			grpcServer.Stop()
+			gracefulStop(grpcServer, grpcStopTimeout)
 			logger.Info("Stopped grpc, stopping http")
-			err := s.Shutdown(ctx)
+			sCtx, sCancel := context.WithTimeout(context.Background(), httpStopTimeout)
+			err := s.Shutdown(sCtx)
+			sCancel()
 			if err != nil {
 				logger.GetLogger().Error("Error stopping http server ", zap.Error(err))
 			}
 			break RunLoop
 		case k := <-exit:
 			logger.Info("Stopping worker... ", zap.String("reason", k))
+			if err := outputs.Close(); err != nil {
+				logger.GetLogger().Error("Error closing sinks ", zap.Error(err))
+			}
 			cancel()
 			logger.Info("Canceled context, gracefully stopping grpc")
 			if k == "grpc" { // (lukanus): when grpc is finished, stop http and vice versa
@@ -196,6 +310,52 @@ func initConfig(path string) (*config.Config, error) {
 	return cfg, nil
 }
 
+// parseValuationMarkets parses comma separated `denom=market` list
+func parseValuationMarkets(marketsList string) (markets map[string]string, err error) {
+	markets = make(map[string]string)
+	for _, pair := range strings.Split(marketsList, ",") {
+		kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			return nil, fmt.Errorf("wrong market mapping %q, expected denom=market", pair)
+		}
+		markets[strings.ToLower(kv[0])] = kv[1]
+	}
+	return markets, nil
+}
+
+// parseTaskTimeouts parses comma separated `type=duration` list
+func parseTaskTimeouts(timeoutsList string) (timeouts map[string]time.Duration, err error) {
+	timeouts = make(map[string]time.Duration)
+	if timeoutsList == "" {
+		return timeouts, nil
+	}
+	for _, pair := range strings.Split(timeoutsList, ",") {
+		kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("wrong task timeout %q, expected type=duration", pair)
+		}
+		if timeouts[kv[0]], err = time.ParseDuration(kv[1]); err != nil {
+			return nil, fmt.Errorf("wrong task timeout %q: %w", pair, err)
+		}
+	}
+	return timeouts, nil
+}
+
+// gracefulStop stops grpc server waiting for open streams to finish, forcing stop after the timeout
+func gracefulStop(grpcServer *grpc.Server, timeout time.Duration) {
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		grpcServer.Stop()
+	}
+}
+
 func runGRPC(grpcServer *grpc.Server, port string, logger *zap.Logger, exit chan<- string) {
 	defer logger.Sync()
 
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/figment-networks/indexing-engine/worker/store"
+	httpStore "github.com/figment-networks/indexing-engine/worker/store/transport/http"
+	"github.com/figment-networks/kava-worker/cmd/worker-kava/config"
+	"github.com/figment-networks/kava-worker/localstore"
+	"github.com/figment-networks/kava-worker/sink"
+
+	"go.uber.org/zap"
+)
+
+// Sink names of SINKS config
+const (
+	sinkStore   = "store"
+	sinkJSONL   = "jsonl"
+	sinkStdout  = "stdout"
+	sinkWebhook = "webhook"
+	sinkLocal   = "local"
+)
+
+// sinks are outputs of indexed data listed in config
+type sinks struct {
+	caller store.SearchStoreCaller
+	// local is the embedded store, when configured
+	local   *localstore.Store
+	closers []func() error
+}
+
+// Close closes all sinks keeping open files
+func (s *sinks) Close() error {
+	for _, c := range s.closers {
+		if err := c(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildSinks creates outputs listed in config
+func buildSinks(ctx context.Context, cfg *config.Config, logger *zap.Logger) (*sinks, error) {
+	var (
+		callers []store.SearchStoreCaller
+		s       = &sinks{}
+	)
+
+	names := cfg.Sinks
+	if strings.TrimSpace(names) == "" {
+		names = sinkStore
+	}
+
+	for _, name := range strings.Split(names, ",") {
+		switch name = strings.TrimSpace(name); name {
+		case sinkStore:
+			callers = append(callers, httpStore.NewHTTPStore(strings.Split(cfg.StoreHTTPEndpoints, ","), &http.Client{}))
+		case sinkJSONL:
+			j, err := sink.NewJSONL(cfg.SinkJSONLDir, cfg.ChainID, cfg.SinkJSONLRotate)
+			if err != nil {
+				return nil, err
+			}
+			callers = append(callers, j)
+			s.closers = append(s.closers, j.Close)
+		case sinkStdout:
+			callers = append(callers, sink.NewStream(os.Stdout))
+		case sinkWebhook:
+			if cfg.SinkWebhookURL == "" {
+				return nil, fmt.Errorf("webhook sink requires SINK_WEBHOOK_URL")
+			}
+			wh := sink.NewWebhook(cfg.SinkWebhookURL, &http.Client{}, logger, cfg.SinkWebhookBatch, cfg.SinkWebhookInterval)
+			go wh.Run(ctx)
+			callers = append(callers, wh)
+		case sinkLocal:
+			ls, err := localstore.New(cfg.LocalStorePath)
+			if err != nil {
+				return nil, err
+			}
+			callers = append(callers, ls)
+			s.local = ls
+			s.closers = append(s.closers, ls.Close)
+		default:
+			return nil, fmt.Errorf("unknown sink %q", name)
+		}
+	}
+
+	switch len(callers) {
+	case 0:
+		return nil, fmt.Errorf("no sinks configured")
+	case 1:
+		s.caller = callers[0]
+	default:
+		s.caller = sink.NewMulti(callers...)
+	}
+	return s, nil
+}
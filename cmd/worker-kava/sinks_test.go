@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/figment-networks/kava-worker/cmd/worker-kava/config"
+	"go.uber.org/zap"
+)
+
+func TestBuildSinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		sinks   string
+		wantErr string
+	}{
+		{name: "store", sinks: "store"},
+		{name: "empty list", sinks: ""},
+		{name: "blank list", sinks: "  "},
+		{name: "stdout and store", sinks: "stdout, store"},
+		{name: "unknown", sinks: "store,kafka", wantErr: `unknown sink "kafka"`},
+		{name: "webhook without url", sinks: "webhook", wantErr: "webhook sink requires SINK_WEBHOOK_URL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := buildSinks(context.Background(), &config.Config{Sinks: tt.sinks}, zap.NewNop())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if s.caller == nil {
+				t.Error("expected store caller")
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/figment-networks/indexing-engine/metrics"
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/figment-networks/kava-worker/client"
+
+	"go.uber.org/zap"
+)
+
+var standaloneCheckpointMetric = metrics.MustNewGaugeWithTags(metrics.Options{
+	Namespace: "indexers",
+	Subsystem: "worker_kava_standalone",
+	Name:      "checkpoint",
+	Desc:      "Last height indexed without a gap in standalone mode",
+})
+
+// checkpoint is the last height indexed in standalone mode, all heights below are indexed as well
+type checkpoint struct {
+	Height uint64    `json:"height"`
+	Hash   string    `json:"hash,omitempty"`
+	Time   time.Time `json:"time,omitempty"`
+}
+
+// tipGetter gets the latest block of the chain
+type tipGetter interface {
+	GetBlock(ctx context.Context, params structs.HeightHash) (block structs.Block, err error)
+}
+
+// scheduler indexes the chain without manager, following the tip and persisting progress in checkpoint file
+type scheduler struct {
+	client *client.IndexerClient
+	tip    tipGetter
+	logger *zap.Logger
+
+	checkpointPath string
+	startHeight    uint64
+	batch          uint64
+	interval       time.Duration
+}
+
+func newScheduler(ic *client.IndexerClient, tip tipGetter, logger *zap.Logger, checkpointPath string, startHeight, batch uint64, interval time.Duration) *scheduler {
+	if batch == 0 {
+		batch = 100
+	}
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	return &scheduler{
+		client:         ic,
+		tip:            tip,
+		logger:         logger,
+		checkpointPath: checkpointPath,
+		startHeight:    startHeight,
+		batch:          batch,
+		interval:       interval,
+	}
+}
+
+// Run schedules missing heights every interval, until context is done or worker is draining
+func (s *scheduler) Run(ctx context.Context) {
+	defer s.logger.Sync()
+
+	cp, err := s.readCheckpoint()
+	if err != nil {
+		s.logger.Error("[STANDALONE] Error reading checkpoint", zap.Error(err))
+		return
+	}
+	if cp.Height == 0 && s.startHeight > 0 {
+		cp.Height = s.startHeight - 1
+	}
+	s.logger.Info("[STANDALONE] Starting", zap.Uint64("checkpoint", cp.Height), zap.String("path", s.checkpointPath))
+
+	tckr := time.NewTicker(s.interval)
+	defer tckr.Stop()
+
+	for {
+		if cp, err = s.catchUp(ctx, cp); err != nil {
+			if errors.Is(err, client.ErrDraining) || ctx.Err() != nil {
+				return
+			}
+			s.logger.Error("[STANDALONE] Error indexing", zap.Error(err), zap.Uint64("checkpoint", cp.Height))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-tckr.C:
+		}
+	}
+}
+
+// catchUp indexes heights between checkpoint and the tip, batch by batch
+func (s *scheduler) catchUp(ctx context.Context, cp checkpoint) (checkpoint, error) {
+	tCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	tip, err := s.tip.GetBlock(tCtx, structs.HeightHash{})
+	cancel()
+	if err != nil {
+		return cp, fmt.Errorf("error getting tip: %w", err)
+	}
+
+	for cp.Height < tip.Height {
+		end := cp.Height + s.batch
+		if end > tip.Height {
+			end = tip.Height
+		}
+
+		res, err := s.client.GetRange(ctx, structs.HeightRange{StartHeight: cp.Height + 1, EndHeight: end})
+		if errors.Is(err, client.ErrDraining) {
+			return cp, err
+		}
+
+		// checkpoint moves only over heights indexed without a gap
+		next := end
+		if len(res.ErrorAt) > 0 {
+			next = res.ErrorAt[0] - 1
+		}
+		if next > cp.Height {
+			cp = checkpoint{Height: next}
+			if res.LatestData.LastHeight == next {
+				cp.Hash, cp.Time = res.LatestData.LastHash, res.LatestData.LastTime
+			}
+			if wErr := s.writeCheckpoint(cp); wErr != nil {
+				return cp, wErr
+			}
+			standaloneCheckpointMetric.WithLabels().Set(float64(cp.Height))
+		}
+
+		if err != nil {
+			return cp, err
+		}
+	}
+	return cp, nil
+}
+
+func (s *scheduler) readCheckpoint() (cp checkpoint, err error) {
+	data, err := ioutil.ReadFile(s.checkpointPath)
+	if os.IsNotExist(err) {
+		return cp, nil
+	}
+	if err != nil {
+		return cp, err
+	}
+	return cp, json.Unmarshal(data, &cp)
+}
+
+// writeCheckpoint replaces checkpoint file atomically
+func (s *scheduler) writeCheckpoint(cp checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(s.checkpointPath), filepath.Base(s.checkpointPath)+".*")
+	if err != nil {
+		return fmt.Errorf("error creating checkpoint file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+	if cErr := tmp.Close(); err == nil {
+		err = cErr
+	}
+	if err != nil {
+		return fmt.Errorf("error writing checkpoint file: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), s.checkpointPath)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/figment-networks/indexing-engine/structs"
+	"go.uber.org/zap"
+)
+
+type fakeTip struct {
+	err error
+}
+
+func (ft fakeTip) GetBlock(ctx context.Context, params structs.HeightHash) (structs.Block, error) {
+	return structs.Block{}, ft.err
+}
+
+func dirFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("error reading dir: %v", err)
+	}
+	names := make([]string, 0, len(infos))
+	for _, i := range infos {
+		names = append(names, i.Name())
+	}
+	return names
+}
+
+func TestCheckpointRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	s := newScheduler(nil, nil, zap.NewNop(), filepath.Join(dir, "checkpoint.json"), 0, 0, 0)
+
+	cp, err := s.readCheckpoint()
+	if err != nil {
+		t.Fatalf("unexpected error reading missing checkpoint: %v", err)
+	}
+	if cp != (checkpoint{}) {
+		t.Errorf("expected zero checkpoint, got %+v", cp)
+	}
+
+	tm := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, want := range []checkpoint{
+		{Height: 10, Hash: "AA", Time: tm},
+		{Height: 20, Hash: "BB", Time: tm.Add(time.Minute)},
+		{Height: 30},
+	} {
+		if err := s.writeCheckpoint(want); err != nil {
+			t.Fatalf("unexpected error writing checkpoint: %v", err)
+		}
+		got, err := s.readCheckpoint()
+		if err != nil {
+			t.Fatalf("unexpected error reading checkpoint: %v", err)
+		}
+		if got.Height != want.Height || got.Hash != want.Hash || !got.Time.Equal(want.Time) {
+			t.Errorf("expected %+v, got %+v", want, got)
+		}
+		if files := dirFiles(t, dir); len(files) != 1 || files[0] != "checkpoint.json" {
+			t.Errorf("expected only checkpoint file in dir, got %v", files)
+		}
+	}
+}
+
+func TestCheckpointWriteFailure(t *testing.T) {
+	dir := t.TempDir()
+	// rename over a non-empty directory fails after the temporary file is written
+	path := filepath.Join(dir, "checkpoint.json")
+	if err := os.MkdirAll(filepath.Join(path, "keep"), 0700); err != nil {
+		t.Fatalf("error creating dir: %v", err)
+	}
+	s := newScheduler(nil, nil, zap.NewNop(), path, 0, 0, 0)
+
+	if err := s.writeCheckpoint(checkpoint{Height: 10}); err == nil {
+		t.Fatal("expected error writing checkpoint")
+	}
+	if files := dirFiles(t, dir); len(files) != 1 || files[0] != "checkpoint.json" {
+		t.Errorf("expected temporary file to be removed, got %v", files)
+	}
+	if _, err := os.Stat(filepath.Join(path, "keep")); err != nil {
+		t.Errorf("expected existing path to be left intact: %v", err)
+	}
+
+	s.checkpointPath = filepath.Join(dir, "missing", "checkpoint.json")
+	if err := s.writeCheckpoint(checkpoint{Height: 10}); err == nil {
+		t.Fatal("expected error writing checkpoint in missing dir")
+	}
+}
+
+func TestCheckpointCorrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	if err := ioutil.WriteFile(path, []byte(`{"height":`), 0600); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+	s := newScheduler(nil, nil, zap.NewNop(), path, 0, 0, 0)
+	if _, err := s.readCheckpoint(); err == nil {
+		t.Error("expected error reading truncated checkpoint")
+	}
+}
+
+func TestCatchUpTipError(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "checkpoint.json")
+	tipErr := errors.New("node down")
+	s := newScheduler(nil, fakeTip{err: tipErr}, zap.NewNop(), path, 0, 0, 0)
+
+	want := checkpoint{Height: 10, Hash: "AA"}
+	if err := s.writeCheckpoint(want); err != nil {
+		t.Fatalf("unexpected error writing checkpoint: %v", err)
+	}
+
+	cp, err := s.catchUp(context.Background(), want)
+	if !errors.Is(err, tipErr) {
+		t.Fatalf("expected tip error, got %v", err)
+	}
+	if cp != want {
+		t.Errorf("expected checkpoint to stay at %+v, got %+v", want, cp)
+	}
+	got, err := s.readCheckpoint()
+	if err != nil {
+		t.Fatalf("unexpected error reading checkpoint: %v", err)
+	}
+	if got != want {
+		t.Errorf("expected persisted checkpoint %+v, got %+v", want, got)
+	}
+}
@@ -13,6 +13,7 @@ require (
 	github.com/kelseyhightower/envconfig v1.4.0
 	github.com/rollbar/rollbar-go v1.4.1
 	github.com/sirupsen/logrus v1.7.0
+	github.com/syndtr/goleveldb v1.0.1-0.20190923125748-758128399b1d
 	github.com/tendermint/tendermint v0.33.9
 	go.uber.org/zap v1.17.0
 	golang.org/x/crypto v0.0.0-20200709230013-948cd5f35899 // indirect
@@ -0,0 +1,110 @@
+package localstore
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// maxTransactions is the limit of transactions returned by single query
+const maxTransactions = 1000
+
+// AttachHTTP registers query endpoints of the store:
+//   - /local/status - the latest confirmed height
+//   - /local/block?height= - block of the height
+//   - /local/transaction?hash= - transaction of the hash
+//   - /local/transactions?start_height=&end_height= - transactions of the range
+func (s *Store) AttachHTTP(mux *http.ServeMux) {
+	mux.HandleFunc("/local/status", s.handleStatus)
+	mux.HandleFunc("/local/block", s.handleBlock)
+	mux.HandleFunc("/local/transaction", s.handleTransaction)
+	mux.HandleFunc("/local/transactions", s.handleTransactions)
+}
+
+func (s *Store) handleStatus(w http.ResponseWriter, r *http.Request) {
+	height, ok, err := s.LatestHeight(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, struct {
+		LatestHeight uint64 `json:"latest_height"`
+		Empty        bool   `json:"empty"`
+	}{height, !ok})
+}
+
+func (s *Store) handleBlock(w http.ResponseWriter, r *http.Request) {
+	height, err := strconv.ParseUint(r.URL.Query().Get("height"), 10, 64)
+	if err != nil {
+		http.Error(w, "wrong height", http.StatusBadRequest)
+		return
+	}
+
+	bl, err := s.GetBlock(r.Context(), height)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, bl)
+}
+
+func (s *Store) handleTransaction(w http.ResponseWriter, r *http.Request) {
+	hash := r.URL.Query().Get("hash")
+	if hash == "" {
+		http.Error(w, "hash is required", http.StatusBadRequest)
+		return
+	}
+
+	t, err := s.GetTransaction(r.Context(), hash)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, t)
+}
+
+func (s *Store) handleTransactions(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	start, err := parseHeight(q.Get("start_height"), 0)
+	if err != nil {
+		http.Error(w, "wrong start_height", http.StatusBadRequest)
+		return
+	}
+	end, err := parseHeight(q.Get("end_height"), math.MaxUint64-1)
+	if err != nil || end < start {
+		http.Error(w, "wrong end_height", http.StatusBadRequest)
+		return
+	}
+
+	txs, err := s.GetTransactions(r.Context(), start, end, maxTransactions)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, txs)
+}
+
+func parseHeight(value string, def uint64) (uint64, error) {
+	if value == "" {
+		return def, nil
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(v); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
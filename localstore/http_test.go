@@ -0,0 +1,76 @@
+package localstore
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/figment-networks/indexing-engine/structs"
+)
+
+func TestHTTP(t *testing.T) {
+	s := newTestStore(t)
+	mux := http.NewServeMux()
+	s.AttachHTTP(mux)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	get := func(path string, out interface{}) int {
+		t.Helper()
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("error getting %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusOK && out != nil {
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				t.Fatalf("error decoding %s: %v", path, err)
+			}
+		}
+		return resp.StatusCode
+	}
+
+	status := struct {
+		LatestHeight uint64 `json:"latest_height"`
+		Empty        bool   `json:"empty"`
+	}{}
+	if code := get("/local/status", &status); code != http.StatusOK || status.LatestHeight != 12 || status.Empty {
+		t.Errorf("unexpected status %d %+v", code, status)
+	}
+
+	bl := structs.BlockWithMeta{}
+	if code := get("/local/block?height=10", &bl); code != http.StatusOK || bl.Block.Hash != blockHash(10) {
+		t.Errorf("unexpected block %d %+v", code, bl)
+	}
+
+	tx := structs.TransactionWithMeta{}
+	if code := get("/local/transaction?hash="+txHash(11, "A"), &tx); code != http.StatusOK || tx.Transaction.Height != 11 {
+		t.Errorf("unexpected transaction %d %+v", code, tx)
+	}
+
+	var txs []structs.TransactionWithMeta
+	if code := get("/local/transactions?start_height=11", &txs); code != http.StatusOK || len(txs) != 4 {
+		t.Errorf("unexpected transactions %d, got %d", code, len(txs))
+	}
+	if code := get("/local/transactions?start_height=11&end_height=18446744073709551615", &txs); code != http.StatusOK || len(txs) != 4 {
+		t.Errorf("unexpected transactions of range up to max height %d, got %d", code, len(txs))
+	}
+
+	errTests := []struct {
+		path string
+		code int
+	}{
+		{"/local/block?height=99", http.StatusNotFound},
+		{"/local/block?height=x", http.StatusBadRequest},
+		{"/local/transaction?hash=MISSING", http.StatusNotFound},
+		{"/local/transaction", http.StatusBadRequest},
+		{"/local/transactions?start_height=12&end_height=11", http.StatusBadRequest},
+		{"/local/transactions?start_height=x", http.StatusBadRequest},
+	}
+	for _, tt := range errTests {
+		if code := get(tt.path, nil); code != tt.code {
+			t.Errorf("%s: expected status %d, got %d", tt.path, tt.code, code)
+		}
+	}
+}
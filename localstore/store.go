@@ -0,0 +1,185 @@
+// Package localstore is an embedded search store backed by leveldb, for development and tests.
+// It implements store.SearchStoreCaller, so the worker can run end-to-end without indexer-search.
+package localstore
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/figment-networks/indexing-engine/worker/store"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// ErrNotFound is returned when there is no such block or transaction
+var ErrNotFound = errors.New("not found")
+
+// Key prefixes, heights are encoded big endian so keys are ordered by height
+var (
+	prefixBlock       = []byte("b/") // b/<height> - block
+	prefixTransaction = []byte("t/") // t/<height><hash> - transaction
+	prefixHash        = []byte("h/") // h/<hash> - key of transaction
+	prefixConfirmed   = []byte("c/") // c/<height> - hash of confirmed block
+)
+
+// Store keeps blocks and transactions in leveldb database
+type Store struct {
+	db *leveldb.DB
+}
+
+// New is Store constructor, opening (or creating) database at path
+func New(path string) (*Store, error) {
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error opening local store: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// GetSearchSession returns the store itself, as writes are not transactional across calls
+func (s *Store) GetSearchSession(ctx context.Context) (store.SearchStore, error) {
+	return s, nil
+}
+
+// StoreTransactions writes transactions, replacing ones with the same height and hash
+func (s *Store) StoreTransactions(ctx context.Context, txs []structs.TransactionWithMeta) error {
+	b := new(leveldb.Batch)
+	for _, t := range txs {
+		data, err := json.Marshal(t)
+		if err != nil {
+			return fmt.Errorf("error encoding transaction: %w", err)
+		}
+		key := transactionKey(t.Transaction.Height, t.Transaction.Hash)
+		b.Put(key, data)
+		b.Put(append(append([]byte{}, prefixHash...), t.Transaction.Hash...), key)
+	}
+	return s.db.Write(b, nil)
+}
+
+// StoreBlocks writes blocks, replacing ones of the same height
+func (s *Store) StoreBlocks(ctx context.Context, blocks []structs.BlockWithMeta) error {
+	b := new(leveldb.Batch)
+	for _, bl := range blocks {
+		data, err := json.Marshal(bl)
+		if err != nil {
+			return fmt.Errorf("error encoding block: %w", err)
+		}
+		b.Put(heightKey(prefixBlock, bl.Block.Height), data)
+	}
+	return s.db.Write(b, nil)
+}
+
+// ConfirmHeights marks heights as fully indexed
+func (s *Store) ConfirmHeights(ctx context.Context, heights []structs.BlockWithMeta) error {
+	b := new(leveldb.Batch)
+	for _, h := range heights {
+		b.Put(heightKey(prefixConfirmed, h.Block.Height), []byte(h.Block.Hash))
+	}
+	return s.db.Write(b, nil)
+}
+
+// GetConfirmedHash returns hash of the confirmed height (implements client.ConfirmedHashGetter)
+func (s *Store) GetConfirmedHash(ctx context.Context, height uint64) (hash string, ok bool, err error) {
+	data, err := s.db.Get(heightKey(prefixConfirmed, height), nil)
+	if err == leveldb.ErrNotFound {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return string(data), true, nil
+}
+
+// UnconfirmHeights removes confirmations of heights, so they are indexed again (implements client.ConfirmedHashGetter)
+func (s *Store) UnconfirmHeights(ctx context.Context, heights []uint64) error {
+	b := new(leveldb.Batch)
+	for _, h := range heights {
+		b.Delete(heightKey(prefixConfirmed, h))
+	}
+	return s.db.Write(b, nil)
+}
+
+// GetBlock returns block of the height
+func (s *Store) GetBlock(ctx context.Context, height uint64) (bl structs.BlockWithMeta, err error) {
+	data, err := s.db.Get(heightKey(prefixBlock, height), nil)
+	if err == leveldb.ErrNotFound {
+		return bl, ErrNotFound
+	}
+	if err != nil {
+		return bl, err
+	}
+	return bl, json.Unmarshal(data, &bl)
+}
+
+// GetTransaction returns transaction of the hash
+func (s *Store) GetTransaction(ctx context.Context, hash string) (t structs.TransactionWithMeta, err error) {
+	key, err := s.db.Get(append(append([]byte{}, prefixHash...), hash...), nil)
+	if err == nil {
+		var data []byte
+		if data, err = s.db.Get(key, nil); err == nil {
+			return t, json.Unmarshal(data, &t)
+		}
+	}
+	if err == leveldb.ErrNotFound {
+		return t, ErrNotFound
+	}
+	return t, err
+}
+
+// GetTransactions returns transactions between start and end height (inclusive), ordered by height, up to limit (0 - no limit)
+func (s *Store) GetTransactions(ctx context.Context, startHeight, endHeight uint64, limit int) (txs []structs.TransactionWithMeta, err error) {
+	// the last height is excluded, so the limit of the range doesn't overflow
+	if endHeight == math.MaxUint64 {
+		endHeight--
+	}
+	iter := s.db.NewIterator(&util.Range{
+		Start: heightKey(prefixTransaction, startHeight),
+		Limit: heightKey(prefixTransaction, endHeight+1),
+	}, nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		if limit > 0 && len(txs) == limit {
+			break
+		}
+		t := structs.TransactionWithMeta{}
+		if err := json.Unmarshal(iter.Value(), &t); err != nil {
+			return nil, fmt.Errorf("error decoding transaction: %w", err)
+		}
+		txs = append(txs, t)
+	}
+	return txs, iter.Error()
+}
+
+// LatestHeight returns the highest confirmed height
+func (s *Store) LatestHeight(ctx context.Context) (height uint64, ok bool, err error) {
+	iter := s.db.NewIterator(util.BytesPrefix(prefixConfirmed), nil)
+	defer iter.Release()
+
+	if iter.Last() {
+		height, ok = binary.BigEndian.Uint64(iter.Key()[len(prefixConfirmed):]), true
+	}
+	return height, ok, iter.Error()
+}
+
+func heightKey(prefix []byte, height uint64) []byte {
+	key := make([]byte, len(prefix)+8)
+	copy(key, prefix)
+	binary.BigEndian.PutUint64(key[len(prefix):], height)
+	return key
+}
+
+func transactionKey(height uint64, hash string) []byte {
+	return append(heightKey(prefixTransaction, height), hash...)
+}
@@ -0,0 +1,137 @@
+package localstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/figment-networks/indexing-engine/structs"
+)
+
+// newTestStore opens store in temporary directory, filled with heights 10-12 (two transactions each, 11 unconfirmed)
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("error opening store: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	ctx := context.Background()
+	var (
+		blocks []structs.BlockWithMeta
+		txs    []structs.TransactionWithMeta
+	)
+	for h := uint64(10); h <= 12; h++ {
+		blocks = append(blocks, structs.BlockWithMeta{Network: "kava", ChainID: "kava-4", Block: structs.Block{Height: h, Hash: blockHash(h), NumberOfTransactions: 2}})
+		// hashes are not ordered, transactions still have to be ordered by height
+		txs = append(txs,
+			structs.TransactionWithMeta{Network: "kava", Transaction: structs.Transaction{Height: h, Hash: txHash(h, "B")}},
+			structs.TransactionWithMeta{Network: "kava", Transaction: structs.Transaction{Height: h, Hash: txHash(h, "A")}},
+		)
+	}
+	if err := s.StoreBlocks(ctx, blocks); err != nil {
+		t.Fatalf("error storing blocks: %v", err)
+	}
+	if err := s.StoreTransactions(ctx, txs); err != nil {
+		t.Fatalf("error storing transactions: %v", err)
+	}
+	if err := s.ConfirmHeights(ctx, []structs.BlockWithMeta{blocks[0], blocks[2]}); err != nil {
+		t.Fatalf("error confirming heights: %v", err)
+	}
+	return s
+}
+
+func blockHash(h uint64) string { return "BLOCK" + string(rune('0'+h%10)) }
+
+func txHash(h uint64, suffix string) string { return "TX" + string(rune('0'+h%10)) + suffix }
+
+func TestStoreRoundTrip(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	bl, err := s.GetBlock(ctx, 11)
+	if err != nil {
+		t.Fatalf("error getting block: %v", err)
+	}
+	if bl.Block.Hash != blockHash(11) || bl.ChainID != "kava-4" || bl.Block.NumberOfTransactions != 2 {
+		t.Errorf("unexpected block %+v", bl)
+	}
+	if _, err := s.GetBlock(ctx, 13); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for missing block, got %v", err)
+	}
+
+	tx, err := s.GetTransaction(ctx, txHash(12, "B"))
+	if err != nil {
+		t.Fatalf("error getting transaction: %v", err)
+	}
+	if tx.Transaction.Height != 12 || tx.Network != "kava" {
+		t.Errorf("unexpected transaction %+v", tx)
+	}
+	if _, err := s.GetTransaction(ctx, "MISSING"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for missing transaction, got %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		start, end uint64
+		limit      int
+		want       []string
+	}{
+		{"all", 0, 100, 0, []string{txHash(10, "A"), txHash(10, "B"), txHash(11, "A"), txHash(11, "B"), txHash(12, "A"), txHash(12, "B")}},
+		{"single height", 11, 11, 0, []string{txHash(11, "A"), txHash(11, "B")}},
+		{"limit", 10, 12, 3, []string{txHash(10, "A"), txHash(10, "B"), txHash(11, "A")}},
+		{"empty", 13, 20, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txs, err := s.GetTransactions(ctx, tt.start, tt.end, tt.limit)
+			if err != nil {
+				t.Fatalf("error getting transactions: %v", err)
+			}
+			if len(txs) != len(tt.want) {
+				t.Fatalf("expected %d transactions, got %d", len(tt.want), len(txs))
+			}
+			for i, tx := range txs {
+				if tx.Transaction.Hash != tt.want[i] {
+					t.Errorf("transaction %d: expected %s, got %s", i, tt.want[i], tx.Transaction.Hash)
+				}
+			}
+		})
+	}
+
+	height, ok, err := s.LatestHeight(ctx)
+	if err != nil || !ok || height != 12 {
+		t.Errorf("expected latest height 12, got %d (ok: %t, err: %v)", height, ok, err)
+	}
+
+	hash, ok, err := s.GetConfirmedHash(ctx, 10)
+	if err != nil || !ok || hash != blockHash(10) {
+		t.Errorf("expected confirmed hash %s, got %q (ok: %t, err: %v)", blockHash(10), hash, ok, err)
+	}
+	if _, ok, err := s.GetConfirmedHash(ctx, 11); ok || err != nil {
+		t.Errorf("expected unconfirmed height 11, got ok: %t, err: %v", ok, err)
+	}
+
+	if err := s.UnconfirmHeights(ctx, []uint64{10, 11}); err != nil {
+		t.Fatalf("unexpected error removing confirmations: %v", err)
+	}
+	if _, ok, err := s.GetConfirmedHash(ctx, 10); ok || err != nil {
+		t.Errorf("expected height 10 to be unconfirmed, got ok: %t, err: %v", ok, err)
+	}
+	if _, err := s.GetBlock(ctx, 10); err != nil {
+		t.Errorf("expected block of unconfirmed height to be kept: %v", err)
+	}
+}
+
+func TestLatestHeightEmpty(t *testing.T) {
+	s, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("error opening store: %v", err)
+	}
+	defer s.Close()
+
+	if height, ok, err := s.LatestHeight(context.Background()); ok || height != 0 || err != nil {
+		t.Errorf("expected empty store, got %d (ok: %t, err: %v)", height, ok, err)
+	}
+}
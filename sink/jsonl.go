@@ -0,0 +1,184 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/figment-networks/indexing-engine/worker/store"
+)
+
+// maxOpenFiles is the number of rotated files kept open at once
+const maxOpenFiles = 4
+
+// Stream writes records as newline delimited JSON into writer (eg. stdout)
+type Stream struct {
+	w   io.Writer
+	enc *json.Encoder
+	l   sync.Mutex
+}
+
+// NewStream is Stream constructor
+func NewStream(w io.Writer) *Stream {
+	return &Stream{w: w, enc: json.NewEncoder(w)}
+}
+
+// GetSearchSession returns session writing into the stream
+func (s *Stream) GetSearchSession(ctx context.Context) (store.SearchStore, error) {
+	return NewSession(s), nil
+}
+
+// Write encodes records, one per line
+func (s *Stream) Write(ctx context.Context, records []Record) error {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	for _, r := range records {
+		if err := s.enc.Encode(r); err != nil {
+			return fmt.Errorf("error writing record: %w", err)
+		}
+	}
+	recordsWritten.WithLabels("stream").Add(float64(len(records)))
+	return nil
+}
+
+// JSONL writes records as newline delimited JSON files in directory, rotated by height.
+// Records of heights from N*rotate to (N+1)*rotate-1 are written into `<prefix>-<N*rotate>.jsonl` file.
+type JSONL struct {
+	dir    string
+	prefix string
+	rotate uint64
+
+	files map[uint64]*jsonlFile
+	// order of opening files, for closing the oldest ones
+	order []uint64
+	l     sync.Mutex
+}
+
+type jsonlFile struct {
+	f   *os.File
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+// NewJSONL is JSONL constructor
+func NewJSONL(dir, prefix string, rotate uint64) (*JSONL, error) {
+	if rotate == 0 {
+		return nil, fmt.Errorf("rotation has to be greater than zero")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating jsonl directory: %w", err)
+	}
+	return &JSONL{
+		dir:    dir,
+		prefix: prefix,
+		rotate: rotate,
+		files:  make(map[uint64]*jsonlFile),
+	}, nil
+}
+
+// GetSearchSession returns session writing into the files
+func (j *JSONL) GetSearchSession(ctx context.Context) (store.SearchStore, error) {
+	return NewSession(j), nil
+}
+
+// Write appends records into files of their heights.
+// When records contain confirmation, buffered records are flushed, so confirmed heights aren't lost on crash.
+func (j *JSONL) Write(ctx context.Context, records []Record) error {
+	j.l.Lock()
+	defer j.l.Unlock()
+
+	var confirm bool
+	for _, r := range records {
+		jf, err := j.file(r.Height / j.rotate * j.rotate)
+		if err != nil {
+			return err
+		}
+		if err := jf.enc.Encode(r); err != nil {
+			return fmt.Errorf("error writing record: %w", err)
+		}
+		confirm = confirm || r.Kind == KindConfirm
+	}
+	recordsWritten.WithLabels("jsonl").Add(float64(len(records)))
+
+	if confirm {
+		return j.flush()
+	}
+	return nil
+}
+
+// Flush writes buffered records into files
+func (j *JSONL) Flush(ctx context.Context) error {
+	j.l.Lock()
+	defer j.l.Unlock()
+
+	return j.flush()
+}
+
+func (j *JSONL) flush() error {
+	for _, jf := range j.files {
+		if err := jf.buf.Flush(); err != nil {
+			return fmt.Errorf("error flushing jsonl file: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close flushes and closes all open files
+func (j *JSONL) Close() error {
+	j.l.Lock()
+	defer j.l.Unlock()
+
+	for _, start := range j.order {
+		if err := j.closeFile(start); err != nil {
+			return err
+		}
+	}
+	j.order = nil
+	return nil
+}
+
+// file gets open file of rotation starting at given height, closing the oldest one when limit is reached
+func (j *JSONL) file(start uint64) (*jsonlFile, error) {
+	if jf, ok := j.files[start]; ok {
+		return jf, nil
+	}
+
+	if len(j.order) == maxOpenFiles {
+		if err := j.closeFile(j.order[0]); err != nil {
+			return nil, err
+		}
+		j.order = j.order[1:]
+	}
+
+	path := filepath.Join(j.dir, fmt.Sprintf("%s-%d.jsonl", j.prefix, start))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening jsonl file: %w", err)
+	}
+
+	buf := bufio.NewWriter(f)
+	jf := &jsonlFile{f: f, buf: buf, enc: json.NewEncoder(buf)}
+	j.files[start] = jf
+	j.order = append(j.order, start)
+	return jf, nil
+}
+
+func (j *JSONL) closeFile(start uint64) error {
+	jf, ok := j.files[start]
+	if !ok {
+		return nil
+	}
+	delete(j.files, start)
+
+	if err := jf.buf.Flush(); err != nil {
+		jf.f.Close()
+		return fmt.Errorf("error flushing jsonl file: %w", err)
+	}
+	return jf.f.Close()
+}
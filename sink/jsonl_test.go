@@ -0,0 +1,139 @@
+package sink
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func readRecords(t *testing.T, path string) (heights []uint64) {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("error opening %s: %v", path, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		r := Record{}
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatalf("error decoding %s: %v", path, err)
+		}
+		heights = append(heights, r.Height)
+	}
+	return heights
+}
+
+func TestStream(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := NewStream(buf).Write(context.Background(), []Record{{Kind: KindConfirm, Height: 1}, {Kind: KindConfirm, Height: 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "{\"kind\":\"confirm\",\"height\":1}\n{\"kind\":\"confirm\",\"height\":2}\n"; got != want {
+		t.Errorf("stream output = %q, want %q", got, want)
+	}
+}
+
+func TestJSONLRotation(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	j, err := NewJSONL(dir, "kava-4", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var records []Record
+	// six rotations, more than maxOpenFiles, the first one is written again after it was closed
+	for _, h := range []uint64{0, 9, 10, 25, 31, 47, 55, 5} {
+		records = append(records, Record{Kind: KindConfirm, Height: h})
+	}
+	if err := j.Write(ctx, records); err != nil {
+		t.Fatal(err)
+	}
+	if len(j.files) > maxOpenFiles {
+		t.Errorf("%d files open, limit is %d", len(j.files), maxOpenFiles)
+	}
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]uint64{
+		"kava-4-0.jsonl":  {0, 9, 5},
+		"kava-4-10.jsonl": {10},
+		"kava-4-20.jsonl": {25},
+		"kava-4-30.jsonl": {31},
+		"kava-4-40.jsonl": {47},
+		"kava-4-50.jsonl": {55},
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(want) {
+		t.Errorf("written files %v", files)
+	}
+	for name, heights := range want {
+		if got := readRecords(t, filepath.Join(dir, name)); !reflect.DeepEqual(got, heights) {
+			t.Errorf("%s has heights %v, want %v", name, got, heights)
+		}
+	}
+}
+
+func TestJSONLFlush(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	j, err := NewJSONL(dir, "kava-4", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+
+	if err := j.Write(ctx, []Record{{Kind: KindBlock, Height: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := readRecords(t, filepath.Join(dir, "kava-4-0.jsonl")); len(got) != 0 {
+		t.Errorf("records written before flush: %v", got)
+	}
+	if err := j.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := readRecords(t, filepath.Join(dir, "kava-4-0.jsonl")); !reflect.DeepEqual(got, []uint64{1}) {
+		t.Errorf("records after flush: %v", got)
+	}
+}
+
+func TestJSONLFlushOnConfirm(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	j, err := NewJSONL(dir, "kava-4", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+
+	// block of the next rotation is buffered in another file, it's flushed as well
+	if err := j.Write(ctx, []Record{{Kind: KindBlock, Height: 12}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Write(ctx, []Record{{Kind: KindBlock, Height: 2}, {Kind: KindConfirm, Height: 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := readRecords(t, filepath.Join(dir, "kava-4-0.jsonl")); !reflect.DeepEqual(got, []uint64{2, 2}) {
+		t.Errorf("records after confirmation: %v", got)
+	}
+	if got := readRecords(t, filepath.Join(dir, "kava-4-10.jsonl")); !reflect.DeepEqual(got, []uint64{12}) {
+		t.Errorf("records of other file after confirmation: %v", got)
+	}
+}
+
+func TestNewJSONLRotation(t *testing.T) {
+	if _, err := NewJSONL(t.TempDir(), "kava-4", 0); err == nil {
+		t.Error("expected error for zero rotation")
+	}
+}
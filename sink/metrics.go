@@ -0,0 +1,21 @@
+package sink
+
+import "github.com/figment-networks/indexing-engine/metrics"
+
+var (
+	recordsWritten = metrics.MustNewCounterWithTags(metrics.Options{
+		Namespace: "indexers",
+		Subsystem: "worker_sink",
+		Name:      "records_written",
+		Desc:      "Records written by sink",
+		Tags:      []string{"sink"},
+	})
+
+	webhookRequests = metrics.MustNewCounterWithTags(metrics.Options{
+		Namespace: "indexers",
+		Subsystem: "worker_sink",
+		Name:      "webhook_requests",
+		Desc:      "Requests sent to webhook",
+		Tags:      []string{"status"},
+	})
+)
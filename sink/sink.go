@@ -0,0 +1,165 @@
+// Package sink contains outputs of indexed data, other than the search store.
+// Every sink implements store.SearchStoreCaller, so it may be used in place of (or along with) the store.
+package sink
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/figment-networks/indexing-engine/worker/store"
+)
+
+// Kinds of records
+const (
+	KindBlock       = "block"
+	KindTransaction = "transaction"
+	KindConfirm     = "confirm"
+)
+
+// Record is a single entry written to a sink
+type Record struct {
+	Kind        string                       `json:"kind"`
+	Height      uint64                       `json:"height"`
+	Block       *structs.BlockWithMeta       `json:"block,omitempty"`
+	Transaction *structs.TransactionWithMeta `json:"transaction,omitempty"`
+}
+
+// Writer writes records
+type Writer interface {
+	Write(ctx context.Context, records []Record) error
+}
+
+// Flusher is implemented by sinks buffering records
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// confirmedHashGetter is client.ConfirmedHashGetter, passed through by Multi
+type confirmedHashGetter interface {
+	GetConfirmedHash(ctx context.Context, height uint64) (hash string, ok bool, err error)
+	UnconfirmHeights(ctx context.Context, heights []uint64) error
+}
+
+// Session adapts Writer into store.SearchStore
+type Session struct {
+	w Writer
+}
+
+// NewSession is Session constructor
+func NewSession(w Writer) *Session {
+	return &Session{w: w}
+}
+
+// StoreTransactions writes transaction records
+func (s *Session) StoreTransactions(ctx context.Context, txs []structs.TransactionWithMeta) error {
+	records := make([]Record, len(txs))
+	for i := range txs {
+		records[i] = Record{Kind: KindTransaction, Height: txs[i].Transaction.Height, Transaction: &txs[i]}
+	}
+	return s.w.Write(ctx, records)
+}
+
+// StoreBlocks writes block records
+func (s *Session) StoreBlocks(ctx context.Context, blocks []structs.BlockWithMeta) error {
+	records := make([]Record, len(blocks))
+	for i := range blocks {
+		records[i] = Record{Kind: KindBlock, Height: blocks[i].Block.Height, Block: &blocks[i]}
+	}
+	return s.w.Write(ctx, records)
+}
+
+// ConfirmHeights writes confirmation records (without block data)
+func (s *Session) ConfirmHeights(ctx context.Context, heights []structs.BlockWithMeta) error {
+	records := make([]Record, len(heights))
+	for i := range heights {
+		records[i] = Record{Kind: KindConfirm, Height: heights[i].Block.Height}
+	}
+	return s.w.Write(ctx, records)
+}
+
+// Multi passes data to all of its callers, in order
+type Multi struct {
+	callers []store.SearchStoreCaller
+}
+
+// NewMulti is Multi constructor
+func NewMulti(callers ...store.SearchStoreCaller) *Multi {
+	return &Multi{callers: callers}
+}
+
+// GetSearchSession gets sessions of all callers
+func (m *Multi) GetSearchSession(ctx context.Context) (store.SearchStore, error) {
+	ms := make(multiSession, 0, len(m.callers))
+	for _, c := range m.callers {
+		s, err := c.GetSearchSession(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ms = append(ms, s)
+	}
+	return ms, nil
+}
+
+// Flush flushes all callers buffering data
+func (m *Multi) Flush(ctx context.Context) error {
+	for _, c := range m.callers {
+		if f, ok := c.(Flusher); ok {
+			if err := f.Flush(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type multiSession []store.SearchStore
+
+// GetConfirmedHash returns hash from the first session able to return hashes of confirmed heights
+func (ms multiSession) GetConfirmedHash(ctx context.Context, height uint64) (hash string, ok bool, err error) {
+	for _, s := range ms {
+		if chg, isGetter := s.(confirmedHashGetter); isGetter {
+			return chg.GetConfirmedHash(ctx, height)
+		}
+	}
+	return "", false, nil
+}
+
+// UnconfirmHeights removes confirmations from all sessions keeping hashes of confirmed heights
+func (ms multiSession) UnconfirmHeights(ctx context.Context, heights []uint64) error {
+	for i, s := range ms {
+		if chg, isGetter := s.(confirmedHashGetter); isGetter {
+			if err := chg.UnconfirmHeights(ctx, heights); err != nil {
+				return fmt.Errorf("error removing confirmations in sink %d: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (ms multiSession) StoreTransactions(ctx context.Context, txs []structs.TransactionWithMeta) error {
+	for i, s := range ms {
+		if err := s.StoreTransactions(ctx, txs); err != nil {
+			return fmt.Errorf("error storing transactions in sink %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (ms multiSession) StoreBlocks(ctx context.Context, blocks []structs.BlockWithMeta) error {
+	for i, s := range ms {
+		if err := s.StoreBlocks(ctx, blocks); err != nil {
+			return fmt.Errorf("error storing blocks in sink %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (ms multiSession) ConfirmHeights(ctx context.Context, heights []structs.BlockWithMeta) error {
+	for i, s := range ms {
+		if err := s.ConfirmHeights(ctx, heights); err != nil {
+			return fmt.Errorf("error confirming heights in sink %d: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/figment-networks/indexing-engine/structs"
+	"github.com/figment-networks/indexing-engine/worker/store"
+)
+
+// memWriter keeps written records
+type memWriter struct {
+	records []Record
+	err     error
+	flushed int
+}
+
+func (mw *memWriter) Write(ctx context.Context, records []Record) error {
+	if mw.err != nil {
+		return mw.err
+	}
+	mw.records = append(mw.records, records...)
+	return nil
+}
+
+func (mw *memWriter) Flush(ctx context.Context) error {
+	mw.flushed++
+	return mw.err
+}
+
+func (mw *memWriter) GetSearchSession(ctx context.Context) (store.SearchStore, error) {
+	return NewSession(mw), nil
+}
+
+// hashWriter is memWriter able to return hashes of confirmed heights
+type hashWriter struct {
+	memWriter
+	unconfirmed []uint64
+}
+
+func (hw *hashWriter) GetSearchSession(ctx context.Context) (store.SearchStore, error) {
+	return hashSession{Session: NewSession(hw), hw: hw}, nil
+}
+
+type hashSession struct {
+	*Session
+	hw *hashWriter
+}
+
+func (hashSession) GetConfirmedHash(ctx context.Context, height uint64) (string, bool, error) {
+	return "HASH", true, nil
+}
+
+func (hs hashSession) UnconfirmHeights(ctx context.Context, heights []uint64) error {
+	hs.hw.unconfirmed = append(hs.hw.unconfirmed, heights...)
+	return nil
+}
+
+func kinds(records []Record) (k []string) {
+	for _, r := range records {
+		k = append(k, r.Kind)
+	}
+	return k
+}
+
+func TestSession(t *testing.T) {
+	ctx := context.Background()
+	mw := &memWriter{}
+	s := NewSession(mw)
+
+	if err := s.StoreBlocks(ctx, []structs.BlockWithMeta{{Block: structs.Block{Height: 10, Hash: "B10"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.StoreTransactions(ctx, []structs.TransactionWithMeta{
+		{Transaction: structs.Transaction{Height: 10, Hash: "T1"}},
+		{Transaction: structs.Transaction{Height: 10, Hash: "T2"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ConfirmHeights(ctx, []structs.BlockWithMeta{{Block: structs.Block{Height: 10, Hash: "B10"}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := kinds(mw.records), []string{KindBlock, KindTransaction, KindTransaction, KindConfirm}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("record kinds = %v, want %v", got, want)
+	}
+	for _, r := range mw.records {
+		if r.Height != 10 {
+			t.Errorf("%s record has height %d", r.Kind, r.Height)
+		}
+	}
+	if mw.records[0].Block.Block.Hash != "B10" {
+		t.Errorf("block record carries %+v", mw.records[0].Block)
+	}
+	if mw.records[1].Transaction.Transaction.Hash != "T1" || mw.records[2].Transaction.Transaction.Hash != "T2" {
+		t.Errorf("transaction records point to wrong transactions")
+	}
+	if mw.records[3].Block != nil || mw.records[3].Transaction != nil {
+		t.Errorf("confirm record carries data")
+	}
+}
+
+func TestMulti(t *testing.T) {
+	ctx := context.Background()
+	first, second := &memWriter{}, &hashWriter{}
+	m := NewMulti(first, second)
+
+	s, err := m.GetSearchSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.StoreBlocks(ctx, []structs.BlockWithMeta{{Block: structs.Block{Height: 1}}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(first.records) != 1 || len(second.records) != 1 {
+		t.Errorf("records not passed to all sinks: %d, %d", len(first.records), len(second.records))
+	}
+
+	chg, ok := s.(confirmedHashGetter)
+	if !ok {
+		t.Fatal("multi session doesn't pass confirmed hashes")
+	}
+	if hash, ok, err := chg.GetConfirmedHash(ctx, 1); err != nil || !ok || hash != "HASH" {
+		t.Errorf("GetConfirmedHash() = %q, %v, %v", hash, ok, err)
+	}
+	if err := chg.UnconfirmHeights(ctx, []uint64{1, 2}); err != nil || !reflect.DeepEqual(second.unconfirmed, []uint64{1, 2}) {
+		t.Errorf("UnconfirmHeights() = %v, removed %v", err, second.unconfirmed)
+	}
+
+	if err := m.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if first.flushed != 1 || second.flushed != 1 {
+		t.Errorf("flushed %d, %d times", first.flushed, second.flushed)
+	}
+
+	// sinks after the failing one are not written
+	failing, last := &memWriter{err: errors.New("disk full")}, &memWriter{}
+	s, err = NewMulti(failing, last).GetSearchSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ConfirmHeights(ctx, []structs.BlockWithMeta{{Block: structs.Block{Height: 1}}}); err == nil {
+		t.Error("expected error of failing sink")
+	}
+	if len(last.records) != 0 {
+		t.Error("sink after failing one was written")
+	}
+
+	// without any getter no hash is known
+	s, err = NewMulti(&memWriter{}).GetSearchSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := s.(confirmedHashGetter).GetConfirmedHash(ctx, 1); ok || err != nil {
+		t.Errorf("GetConfirmedHash() without getters = %v, %v", ok, err)
+	}
+}
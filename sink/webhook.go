@@ -0,0 +1,207 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/figment-networks/indexing-engine/worker/store"
+	"go.uber.org/zap"
+)
+
+const (
+	// webhookInterval is the default interval of sending pending records
+	webhookInterval     = 5 * time.Second
+	webhookRetries      = 5
+	webhookRetryBackoff = time.Second
+	webhookTimeout      = 30 * time.Second
+	// webhookShutdownTimeout is the time given to send pending records after Run is stopped
+	webhookShutdownTimeout = time.Minute
+)
+
+// ErrWebhookBufferFull is returned when records cannot be buffered because webhook doesn't keep up
+var ErrWebhookBufferFull = errors.New("webhook buffer is full")
+
+// Webhook posts records as JSON array to the url.
+// Records are sent in batches, when batch size is reached or every interval.
+// Confirmations are sent right away, along with all records buffered before them.
+type Webhook struct {
+	url          string
+	client       *http.Client
+	logger       *zap.Logger
+	batch        int
+	interval     time.Duration
+	maxPending   int
+	retryBackoff time.Duration
+
+	pending []Record
+	l       sync.Mutex
+	// sendL keeps batches in order
+	sendL sync.Mutex
+}
+
+// NewWebhook is Webhook constructor
+func NewWebhook(url string, client *http.Client, logger *zap.Logger, batch int, interval time.Duration) *Webhook {
+	if batch <= 0 {
+		batch = 100
+	}
+	if interval <= 0 {
+		interval = webhookInterval
+	}
+	return &Webhook{
+		url:          url,
+		client:       client,
+		logger:       logger,
+		batch:        batch,
+		interval:     interval,
+		maxPending:   batch * 100,
+		retryBackoff: webhookRetryBackoff,
+	}
+}
+
+// Run sends pending records every interval, until context is done.
+// Records left pending are sent afterwards, within webhookShutdownTimeout.
+func (wh *Webhook) Run(ctx context.Context) {
+	defer wh.logger.Sync()
+
+	tckr := time.NewTicker(wh.interval)
+	defer tckr.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			fCtx, cancel := context.WithTimeout(context.Background(), webhookShutdownTimeout)
+			defer cancel()
+			if err := wh.Flush(fCtx); err != nil {
+				wh.logger.Error("[SINK] Error sending pending records to webhook on shutdown", zap.Error(err))
+			}
+			return
+		case <-tckr.C:
+			if err := wh.Flush(ctx); err != nil {
+				wh.logger.Error("[SINK] Error sending records to webhook", zap.Error(err))
+			}
+		}
+	}
+}
+
+// GetSearchSession returns session writing into the webhook
+func (wh *Webhook) GetSearchSession(ctx context.Context) (store.SearchStore, error) {
+	return NewSession(wh), nil
+}
+
+// Write buffers records, sending full batches. When records contain confirmation, everything buffered is sent,
+// so heights are confirmed only after webhook received them.
+// Sending error is returned, records are kept buffered and sent again later (so they may be delivered more than once).
+func (wh *Webhook) Write(ctx context.Context, records []Record) error {
+	wh.l.Lock()
+	if len(wh.pending)+len(records) > wh.maxPending {
+		wh.l.Unlock()
+		return ErrWebhookBufferFull
+	}
+	wh.pending = append(wh.pending, records...)
+	full := len(wh.pending) >= wh.batch
+	wh.l.Unlock()
+
+	for _, r := range records {
+		if r.Kind == KindConfirm {
+			return wh.send(ctx, true)
+		}
+	}
+	if full {
+		return wh.send(ctx, false)
+	}
+	return nil
+}
+
+// Flush sends all pending records
+func (wh *Webhook) Flush(ctx context.Context) error {
+	return wh.send(ctx, true)
+}
+
+// send posts pending records in batches, the last incomplete batch only when all is set.
+// Batches that couldn't be sent are put back in front of pending records.
+func (wh *Webhook) send(ctx context.Context, all bool) error {
+	wh.sendL.Lock()
+	defer wh.sendL.Unlock()
+
+	for {
+		wh.l.Lock()
+		if len(wh.pending) == 0 || (!all && len(wh.pending) < wh.batch) {
+			wh.l.Unlock()
+			return nil
+		}
+		n := wh.batch
+		if n > len(wh.pending) {
+			n = len(wh.pending)
+		}
+		batch := wh.pending[:n:n]
+		wh.pending = wh.pending[n:]
+		wh.l.Unlock()
+
+		if err := wh.post(ctx, batch); err != nil {
+			wh.l.Lock()
+			wh.pending = append(batch, wh.pending...)
+			wh.l.Unlock()
+			return err
+		}
+		recordsWritten.WithLabels("webhook").Add(float64(len(batch)))
+	}
+}
+
+// post sends batch retrying with linear backoff
+func (wh *Webhook) post(ctx context.Context, batch []Record) error {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("error encoding records: %w", err)
+	}
+
+	for i := 1; ; i++ {
+		err = wh.postOnce(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if i == webhookRetries {
+			return fmt.Errorf("error sending records after %d attempts: %w", i, err)
+		}
+		wh.logger.Debug("[SINK] Retrying webhook request", zap.Error(err), zap.Int("attempt", i))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(i) * wh.retryBackoff):
+		}
+	}
+}
+
+func (wh *Webhook) postOnce(ctx context.Context, body []byte) error {
+	pCtx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(pCtx, http.MethodPost, wh.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := wh.client.Do(req)
+	if err != nil {
+		webhookRequests.WithLabels("error").Inc()
+		return err
+	}
+	defer resp.Body.Close()
+	webhookRequests.WithLabels(strconv.Itoa(resp.StatusCode)).Inc()
+
+	if resp.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook responded with %d: %s", resp.StatusCode, string(msg))
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// webhookServer records received batches, failing the given number of requests first
+type webhookServer struct {
+	l        sync.Mutex
+	batches  [][]Record
+	requests int
+	failures int
+}
+
+func (ws *webhookServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ws.l.Lock()
+	defer ws.l.Unlock()
+
+	ws.requests++
+	if ws.failures > 0 {
+		ws.failures--
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	batch := []Record{}
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ws.batches = append(ws.batches, batch)
+}
+
+func (ws *webhookServer) sizes() (s []int) {
+	ws.l.Lock()
+	defer ws.l.Unlock()
+	for _, b := range ws.batches {
+		s = append(s, len(b))
+	}
+	return s
+}
+
+func newTestWebhook(t *testing.T, ws *webhookServer, batch int) *Webhook {
+	t.Helper()
+	srv := httptest.NewServer(ws)
+	t.Cleanup(srv.Close)
+
+	wh := NewWebhook(srv.URL, srv.Client(), zap.NewNop(), batch, 10*time.Millisecond)
+	wh.retryBackoff = time.Millisecond
+	return wh
+}
+
+func confirms(heights ...uint64) (records []Record) {
+	for _, h := range heights {
+		records = append(records, Record{Kind: KindConfirm, Height: h})
+	}
+	return records
+}
+
+func blocks(n int) (records []Record) {
+	for i := 0; i < n; i++ {
+		records = append(records, Record{Kind: KindBlock, Height: uint64(i)})
+	}
+	return records
+}
+
+func TestWebhookBatching(t *testing.T) {
+	ctx := context.Background()
+	ws := &webhookServer{}
+	wh := newTestWebhook(t, ws, 3)
+
+	if err := wh.Write(ctx, blocks(2)); err != nil {
+		t.Fatal(err)
+	}
+	if len(ws.sizes()) != 0 {
+		t.Fatalf("incomplete batch was sent: %v", ws.sizes())
+	}
+
+	// full batches are sent, the rest is kept
+	if err := wh.Write(ctx, blocks(5)); err != nil {
+		t.Fatal(err)
+	}
+	if got := ws.sizes(); len(got) != 2 || got[0] != 3 || got[1] != 3 {
+		t.Fatalf("sent batches %v, want [3 3]", got)
+	}
+
+	// confirmation sends everything buffered before it
+	if err := wh.Write(ctx, confirms(1)); err != nil {
+		t.Fatal(err)
+	}
+	got := ws.sizes()
+	if len(got) != 3 || got[2] != 2 {
+		t.Fatalf("sent batches %v, want [3 3 2]", got)
+	}
+	if last := ws.batches[2][1]; last.Kind != KindConfirm {
+		t.Errorf("confirmation is not the last record sent: %+v", last)
+	}
+
+	if err := wh.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(ws.sizes()) != 3 {
+		t.Errorf("empty buffer was sent")
+	}
+}
+
+func TestWebhookRetry(t *testing.T) {
+	ctx := context.Background()
+	ws := &webhookServer{failures: webhookRetries - 1}
+	wh := newTestWebhook(t, ws, 10)
+
+	if err := wh.Write(ctx, confirms(1, 2)); err != nil {
+		t.Fatalf("request should succeed on the last attempt: %v", err)
+	}
+	if ws.requests != webhookRetries {
+		t.Errorf("sent %d requests, want %d", ws.requests, webhookRetries)
+	}
+	if got := ws.sizes(); len(got) != 1 || got[0] != 2 {
+		t.Errorf("sent batches %v, want [2]", got)
+	}
+}
+
+func TestWebhookFailure(t *testing.T) {
+	ctx := context.Background()
+	ws := &webhookServer{failures: webhookRetries}
+	wh := newTestWebhook(t, ws, 10)
+
+	if err := wh.Write(ctx, blocks(1)); err != nil {
+		t.Fatal(err)
+	}
+	// confirmation isn't accepted when webhook didn't receive the height
+	if err := wh.Write(ctx, confirms(1)); err == nil {
+		t.Fatal("expected error when webhook is unavailable")
+	}
+	if len(wh.pending) != 2 {
+		t.Fatalf("%d records pending, want 2", len(wh.pending))
+	}
+
+	// records are sent again later, in order
+	if err := wh.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := ws.sizes(); len(got) != 1 || got[0] != 2 {
+		t.Fatalf("sent batches %v, want [2]", got)
+	}
+	if ws.batches[0][0].Kind != KindBlock || ws.batches[0][1].Kind != KindConfirm {
+		t.Errorf("records sent out of order: %+v", ws.batches[0])
+	}
+}
+
+func TestWebhookBufferFull(t *testing.T) {
+	ws := &webhookServer{}
+	wh := newTestWebhook(t, ws, 1)
+	wh.maxPending = 2
+
+	if err := wh.Write(context.Background(), blocks(3)); !errors.Is(err, ErrWebhookBufferFull) {
+		t.Errorf("Write() error = %v, want %v", err, ErrWebhookBufferFull)
+	}
+}
+
+func TestWebhookRunFlushesOnShutdown(t *testing.T) {
+	ws := &webhookServer{}
+	wh := newTestWebhook(t, ws, 10)
+
+	if err := wh.Write(context.Background(), blocks(2)); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		wh.Run(ctx)
+		close(done)
+	}()
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run didn't return after context was cancelled")
+	}
+	if got := ws.sizes(); len(got) != 1 || got[0] != 2 {
+		t.Errorf("sent batches %v, want [2]", got)
+	}
+}
+
+func TestWebhookRunInterval(t *testing.T) {
+	ws := &webhookServer{}
+	wh := newTestWebhook(t, ws, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go wh.Run(ctx)
+
+	if err := wh.Write(ctx, blocks(1)); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(ws.sizes()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("pending records were not sent on interval")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestNewWebhookDefaults(t *testing.T) {
+	wh := NewWebhook("http://127.0.0.1", http.DefaultClient, zap.NewNop(), 0, 0)
+	if wh.batch != 100 || wh.interval != webhookInterval {
+		t.Errorf("expected default batch and interval, got %d and %s", wh.batch, wh.interval)
+	}
+
+	// zero interval would panic the ticker
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	wh.Run(ctx)
+}